@@ -4,14 +4,30 @@ import (
 	"currency-converter-v2/internal/app"
 	"currency-converter-v2/internal/config"
 	"log"
+	"os"
 )
 
 func main() {
-	// Загружаем конфигурацию
-	cfg := config.Load()
+	// Загружаем конфигурацию: defaults -> файл -> env -> флаги
+	cfg, opts, err := config.Load(os.Args[1:])
+	if opts.PrintConfig && cfg != nil {
+		if printErr := config.Print(os.Stdout, cfg); printErr != nil {
+			log.Fatalf("Failed to print configuration: %v", printErr)
+		}
+		if err != nil {
+			log.Fatalf("Invalid configuration:\n%v", err)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
-	// Создаем приложение
-	app := app.New(cfg)
+	// Создаем приложение; по SIGHUP конфигурация перечитывается из тех же источников
+	app := app.New(cfg, func() (*config.Config, error) {
+		cfg, _, err := config.Load(os.Args[1:])
+		return cfg, err
+	})
 
 	// Просто запускаем
 	log.Println("Starting Currency Converter API...")
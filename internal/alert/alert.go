@@ -0,0 +1,149 @@
+// Package alert - правила оповещений о движении курса и их доставка вебхуками.
+package alert
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kind - тип правила
+type Kind string
+
+const (
+	// KindAbove - курс пересек порог снизу вверх
+	KindAbove Kind = "above"
+	// KindBelow - курс пересек порог сверху вниз
+	KindBelow Kind = "below"
+	// KindChangePct - курс сдвинулся больше чем на Threshold% за ChangeWindow
+	KindChangePct Kind = "change_pct"
+)
+
+// ChangeWindow - окно, за которое считается изменение для KindChangePct
+const ChangeWindow = 24 * time.Hour
+
+// ErrNotFound - правила нет или оно принадлежит другому клиенту
+var ErrNotFound = errors.New("alert not found")
+
+// ValidationError - некорректное правило, текст можно отдавать клиенту
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Rule - правило оповещения клиента
+type Rule struct {
+	ID         string    `json:"id"`
+	ClientID   string    `json:"client_id"`
+	Kind       Kind      `json:"kind"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Threshold  float64   `json:"threshold"`
+	WebhookURL string    `json:"webhook_url"`
+	Secret     string    `json:"-"` // ключ HMAC подписи, клиент видит его только при создании
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Состояние между проверками
+	LastRate        float64    `json:"last_rate,omitempty"`
+	BaselineRate    float64    `json:"baseline_rate,omitempty"` // для change_pct: курс начала окна
+	BaselineAt      *time.Time `json:"baseline_at,omitempty"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+}
+
+// Validate проверяет правило и приводит коды валют к верхнему регистру
+func (r *Rule) Validate() error {
+	r.From, r.To = strings.ToUpper(r.From), strings.ToUpper(r.To)
+	switch {
+	case r.Kind != KindAbove && r.Kind != KindBelow && r.Kind != KindChangePct:
+		return &ValidationError{fmt.Errorf("kind must be above, below or change_pct, got %q", r.Kind)}
+	case len(r.From) != 3 || len(r.To) != 3:
+		return &ValidationError{errors.New("currency codes must be 3 characters")}
+	case r.From == r.To:
+		return &ValidationError{errors.New("from and to must differ")}
+	case !(r.Threshold > 0):
+		return &ValidationError{errors.New("threshold must be positive")}
+	}
+	u, err := url.Parse(r.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{errors.New("webhook_url must be an absolute http(s) URL")}
+	}
+	return nil
+}
+
+// Event - срабатывание правила, тело вебхука
+type Event struct {
+	ID           string    `json:"id"`
+	AlertID      string    `json:"alert_id"`
+	Kind         Kind      `json:"kind"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Threshold    float64   `json:"threshold"`
+	Rate         float64   `json:"rate"`
+	PreviousRate float64   `json:"previous_rate"` // для change_pct - курс начала окна
+	ChangePct    float64   `json:"change_pct"`
+	TriggeredAt  time.Time `json:"triggered_at"`
+}
+
+// Evaluate применяет новый курс к правилу: возвращает обновленное состояние
+// и событие, если правило сработало.
+// above/below срабатывают на пересечении порога, а не на каждом курсе за ним.
+func Evaluate(rule Rule, rate float64, now time.Time) (Rule, *Event) {
+	prev := rule.LastRate
+	rule.LastRate = rate
+
+	var fired bool
+	switch rule.Kind {
+	case KindAbove:
+		fired = prev > 0 && prev <= rule.Threshold && rate > rule.Threshold
+	case KindBelow:
+		fired = prev > 0 && prev >= rule.Threshold && rate < rule.Threshold
+	case KindChangePct:
+		if rule.BaselineAt == nil || now.Sub(*rule.BaselineAt) >= ChangeWindow {
+			rule.BaselineRate, rule.BaselineAt = rate, &now
+			return rule, nil
+		}
+		prev = rule.BaselineRate
+		fired = math.Abs(rate-prev)/prev*100 >= rule.Threshold
+		if fired {
+			// Следующее окно отсчитывается от момента срабатывания
+			rule.BaselineRate, rule.BaselineAt = rate, &now
+		}
+	}
+	if !fired {
+		return rule, nil
+	}
+
+	rule.LastTriggeredAt = &now
+	event := &Event{
+		ID:           newID("evt_"),
+		AlertID:      rule.ID,
+		Kind:         rule.Kind,
+		From:         rule.From,
+		To:           rule.To,
+		Threshold:    rule.Threshold,
+		Rate:         rate,
+		PreviousRate: prev,
+		ChangePct:    (rate - prev) / prev * 100,
+		TriggeredAt:  now.UTC(),
+	}
+	return rule, event
+}
+
+func newID(prefix string) string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return prefix + hex.EncodeToString(buf)
+}
+
+func newSecret() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return "whsec_" + hex.EncodeToString(buf)
+}
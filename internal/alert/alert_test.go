@@ -0,0 +1,76 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate_Threshold(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rule := Rule{ID: "alert_1", Kind: KindAbove, From: "USD", To: "RUB", Threshold: 100}
+
+	// Первый курс только запоминается: пересечения еще не было
+	rule, event := Evaluate(rule, 101, now)
+	assert.Nil(t, event)
+
+	rule, event = Evaluate(rule, 99, now)
+	assert.Nil(t, event)
+
+	rule, event = Evaluate(rule, 100.5, now)
+	require.NotNil(t, event)
+	assert.Equal(t, 99.0, event.PreviousRate)
+	assert.Equal(t, 100.5, event.Rate)
+	assert.Equal(t, "alert_1", event.AlertID)
+	require.NotNil(t, rule.LastTriggeredAt)
+
+	// Курс остается выше порога - повторно не срабатывает
+	_, event = Evaluate(rule, 102, now)
+	assert.Nil(t, event)
+
+	below := Rule{Kind: KindBelow, Threshold: 90, LastRate: 91}
+	_, event = Evaluate(below, 89, now)
+	assert.NotNil(t, event)
+}
+
+func TestEvaluate_ChangePct(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rule := Rule{Kind: KindChangePct, Threshold: 5}
+
+	rule, event := Evaluate(rule, 100, start)
+	assert.Nil(t, event)
+	assert.Equal(t, 100.0, rule.BaselineRate)
+
+	rule, event = Evaluate(rule, 104, start.Add(time.Hour))
+	assert.Nil(t, event)
+
+	rule, event = Evaluate(rule, 94, start.Add(2*time.Hour))
+	require.NotNil(t, event)
+	assert.InDelta(t, -6.0, event.ChangePct, 1e-9)
+	assert.Equal(t, 94.0, rule.BaselineRate, "после срабатывания окно начинается заново")
+
+	// За пределами окна база сбрасывается без срабатывания
+	rule, event = Evaluate(rule, 120, start.Add(2*time.Hour+ChangeWindow))
+	assert.Nil(t, event)
+	assert.Equal(t, 120.0, rule.BaselineRate)
+}
+
+func TestRule_Validate(t *testing.T) {
+	valid := Rule{Kind: KindAbove, From: "usd", To: "rub", Threshold: 100, WebhookURL: "https://example.com/hook"}
+	require.NoError(t, valid.Validate())
+	assert.Equal(t, "USD", valid.From)
+
+	for name, mutate := range map[string]func(r *Rule){
+		"kind":      func(r *Rule) { r.Kind = "sideways" },
+		"same pair": func(r *Rule) { r.To = "USD" },
+		"threshold": func(r *Rule) { r.Threshold = 0 },
+		"scheme":    func(r *Rule) { r.WebhookURL = "ftp://example.com/hook" },
+	} {
+		r := valid
+		mutate(&r)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, r.Validate(), &validationErr, name)
+	}
+}
@@ -0,0 +1,152 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/refresher"
+
+	"go.uber.org/zap"
+)
+
+// Manager - правила клиентов и их проверка на каждом обновлении курсов
+type Manager struct {
+	store      Store
+	dispatcher *Dispatcher
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewManager(store Store, dispatcher *Dispatcher, logger *zap.Logger) *Manager {
+	return &Manager{
+		store:      store,
+		dispatcher: dispatcher,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// List возвращает правила клиента
+func (m *Manager) List(ctx context.Context, clientID string) ([]Rule, error) {
+	rules, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	own := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.ClientID == clientID {
+			own = append(own, r)
+		}
+	}
+	return own, nil
+}
+
+// Get возвращает правило клиента; чужое правило неотличимо от несуществующего
+func (m *Manager) Get(ctx context.Context, clientID, id string) (Rule, error) {
+	r, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Rule{}, err
+	}
+	if r.ClientID != clientID {
+		return Rule{}, ErrNotFound
+	}
+	return r, nil
+}
+
+// Create сохраняет новое правило и выдает ему секрет подписи вебхуков
+func (m *Manager) Create(ctx context.Context, clientID string, r Rule) (Rule, error) {
+	if err := r.Validate(); err != nil {
+		return Rule{}, err
+	}
+	r.ID = newID("alert_")
+	r.ClientID = clientID
+	r.Secret = newSecret()
+	r.CreatedAt = m.now().UTC()
+	r.LastRate, r.BaselineRate, r.BaselineAt, r.LastTriggeredAt = 0, 0, nil, nil
+	if err := m.store.Save(ctx, r); err != nil {
+		return Rule{}, err
+	}
+	return r, nil
+}
+
+// Update заменяет условия правила. Секрет остается прежним, состояние
+// сбрасывается: пересечения и окно change_pct считаются заново.
+func (m *Manager) Update(ctx context.Context, clientID, id string, r Rule) (Rule, error) {
+	existing, err := m.Get(ctx, clientID, id)
+	if err != nil {
+		return Rule{}, err
+	}
+	if err := r.Validate(); err != nil {
+		return Rule{}, err
+	}
+	existing.Kind, existing.From, existing.To = r.Kind, r.From, r.To
+	existing.Threshold, existing.WebhookURL = r.Threshold, r.WebhookURL
+	existing.LastRate, existing.BaselineRate, existing.BaselineAt = 0, 0, nil
+	if err := m.store.Save(ctx, existing); err != nil {
+		return Rule{}, err
+	}
+	return existing, nil
+}
+
+// Delete удаляет правило клиента
+func (m *Manager) Delete(ctx context.Context, clientID, id string) error {
+	if _, err := m.Get(ctx, clientID, id); err != nil {
+		return err
+	}
+	return m.store.Delete(ctx, id)
+}
+
+// DeadLetters - недоставленные вебхуки клиента, от новых к старым
+func (m *Manager) DeadLetters(ctx context.Context, clientID string, limit int) ([]DeadLetter, error) {
+	return m.store.DeadLetters(ctx, clientID, limit)
+}
+
+// Pairs - пары всех правил, их курсы должен обновлять refresher
+func (m *Manager) Pairs(ctx context.Context) []refresher.Pair {
+	rules, err := m.store.List(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to list alert rules", zap.Error(err))
+		return nil
+	}
+	pairs := make([]refresher.Pair, 0, len(rules))
+	for _, r := range rules {
+		pairs = append(pairs, refresher.Pair{From: r.From, To: r.To})
+	}
+	return pairs
+}
+
+// OnUpdate проверяет правила по свежим курсам и отправляет сработавшие события
+func (m *Manager) OnUpdate(ctx context.Context, u refresher.Update) {
+	rules, err := m.store.List(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to list alert rules", zap.Error(err))
+		return
+	}
+	now := m.now()
+	for _, rule := range rules {
+		if rule.From != u.Base {
+			continue
+		}
+		rate, ok := u.Rates[rule.To]
+		if !ok {
+			continue
+		}
+
+		next, event := Evaluate(rule, rate, now)
+		if err := m.store.SaveState(ctx, next); err != nil {
+			// Без сохраненного состояния то же пересечение сработает повторно, поэтому не отправляем
+			m.logger.Error("Failed to save alert state", zap.String("alert_id", rule.ID), zap.Error(err))
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		m.logger.Info("Alert triggered",
+			zap.String("alert_id", rule.ID),
+			zap.String("client_id", rule.ClientID),
+			zap.String("kind", string(rule.Kind)),
+			zap.Float64("rate", rate),
+		)
+		m.dispatcher.Dispatch(next, *event)
+	}
+}
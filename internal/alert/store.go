@@ -0,0 +1,280 @@
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeadLetter - вебхук, который не удалось доставить за все попытки
+type DeadLetter struct {
+	ID         int64     `json:"id"`
+	AlertID    string    `json:"alert_id"`
+	ClientID   string    `json:"client_id"`
+	EventID    string    `json:"event_id"`
+	URL        string    `json:"url"`
+	Payload    string    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	LastStatus int       `json:"last_status,omitempty"`
+	LastError  string    `json:"last_error"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Store - хранилище правил и журнала недоставленных вебхуков
+type Store interface {
+	List(ctx context.Context) ([]Rule, error)
+	Get(ctx context.Context, id string) (Rule, error)
+	// Save создает правило или обновляет существующее
+	Save(ctx context.Context, r Rule) error
+	Delete(ctx context.Context, id string) error
+	// SaveState обновляет только состояние проверок; удаленное правило не воскрешает
+	SaveState(ctx context.Context, r Rule) error
+
+	AppendDeadLetter(ctx context.Context, d *DeadLetter) error
+	// DeadLetters - последние записи клиента, от новых к старым; пустой clientID - все
+	DeadLetters(ctx context.Context, clientID string, limit int) ([]DeadLetter, error)
+}
+
+// PostgresStore хранит правила в alert_rules, недоставленные вебхуки - в alert_dead_letters
+type PostgresStore struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS alert_rules (
+	id                TEXT PRIMARY KEY,
+	client_id         TEXT NOT NULL,
+	kind              TEXT NOT NULL,
+	from_code         CHAR(3) NOT NULL,
+	to_code           CHAR(3) NOT NULL,
+	threshold         DOUBLE PRECISION NOT NULL,
+	webhook_url       TEXT NOT NULL,
+	secret            TEXT NOT NULL,
+	created_at        TIMESTAMPTZ NOT NULL,
+	last_rate         DOUBLE PRECISION NOT NULL DEFAULT 0,
+	baseline_rate     DOUBLE PRECISION NOT NULL DEFAULT 0,
+	baseline_at       TIMESTAMPTZ,
+	last_triggered_at TIMESTAMPTZ
+);
+CREATE TABLE IF NOT EXISTS alert_dead_letters (
+	id          BIGSERIAL PRIMARY KEY,
+	alert_id    TEXT NOT NULL,
+	client_id   TEXT NOT NULL,
+	event_id    TEXT NOT NULL,
+	url         TEXT NOT NULL,
+	payload     TEXT NOT NULL,
+	attempts    INTEGER NOT NULL,
+	last_status INTEGER NOT NULL DEFAULT 0,
+	last_error  TEXT NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS alert_dead_letters_client_idx ON alert_dead_letters (client_id, id);
+`
+
+// NewPostgresStore создает таблицы, если их еще нет
+func NewPostgresStore(ctx context.Context, db *sql.DB) (*PostgresStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create alert tables: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+const ruleColumns = `id, client_id, kind, from_code, to_code, threshold, webhook_url, secret, created_at,
+	last_rate, baseline_rate, baseline_at, last_triggered_at`
+
+func scanRule(scan func(dest ...any) error) (Rule, error) {
+	var (
+		r                         Rule
+		baselineAt, lastTriggered sql.NullTime
+	)
+	if err := scan(&r.ID, &r.ClientID, &r.Kind, &r.From, &r.To, &r.Threshold, &r.WebhookURL, &r.Secret,
+		&r.CreatedAt, &r.LastRate, &r.BaselineRate, &baselineAt, &lastTriggered); err != nil {
+		return Rule{}, err
+	}
+	if baselineAt.Valid {
+		r.BaselineAt = &baselineAt.Time
+	}
+	if lastTriggered.Valid {
+		r.LastTriggeredAt = &lastTriggered.Time
+	}
+	return r, nil
+}
+
+func (s *PostgresStore) List(ctx context.Context) ([]Rule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+ruleColumns+` FROM alert_rules ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []Rule
+	for rows.Next() {
+		r, err := scanRule(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+func (s *PostgresStore) Get(ctx context.Context, id string) (Rule, error) {
+	r, err := scanRule(s.db.QueryRowContext(ctx, `SELECT `+ruleColumns+` FROM alert_rules WHERE id = $1`, id).Scan)
+	if err == sql.ErrNoRows {
+		return Rule{}, ErrNotFound
+	}
+	return r, err
+}
+
+func (s *PostgresStore) Save(ctx context.Context, r Rule) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO alert_rules (`+ruleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			kind = EXCLUDED.kind, from_code = EXCLUDED.from_code, to_code = EXCLUDED.to_code,
+			threshold = EXCLUDED.threshold, webhook_url = EXCLUDED.webhook_url,
+			last_rate = EXCLUDED.last_rate, baseline_rate = EXCLUDED.baseline_rate,
+			baseline_at = EXCLUDED.baseline_at, last_triggered_at = EXCLUDED.last_triggered_at`,
+		r.ID, r.ClientID, r.Kind, r.From, r.To, r.Threshold, r.WebhookURL, r.Secret, r.CreatedAt,
+		r.LastRate, r.BaselineRate, r.BaselineAt, r.LastTriggeredAt)
+	return err
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) SaveState(ctx context.Context, r Rule) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE alert_rules
+		SET last_rate = $2, baseline_rate = $3, baseline_at = $4, last_triggered_at = $5
+		WHERE id = $1`,
+		r.ID, r.LastRate, r.BaselineRate, r.BaselineAt, r.LastTriggeredAt)
+	return err
+}
+
+func (s *PostgresStore) AppendDeadLetter(ctx context.Context, d *DeadLetter) error {
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO alert_dead_letters (alert_id, client_id, event_id, url, payload, attempts, last_status, last_error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`,
+		d.AlertID, d.ClientID, d.EventID, d.URL, d.Payload, d.Attempts, d.LastStatus, d.LastError, d.CreatedAt,
+	).Scan(&d.ID)
+}
+
+func (s *PostgresStore) DeadLetters(ctx context.Context, clientID string, limit int) ([]DeadLetter, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, alert_id, client_id, event_id, url, payload, attempts, last_status, last_error, created_at
+		FROM alert_dead_letters
+		WHERE $1 = '' OR client_id = $1
+		ORDER BY id DESC
+		LIMIT $2`, clientID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []DeadLetter
+	for rows.Next() {
+		var d DeadLetter
+		if err := rows.Scan(&d.ID, &d.AlertID, &d.ClientID, &d.EventID, &d.URL, &d.Payload,
+			&d.Attempts, &d.LastStatus, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		letters = append(letters, d)
+	}
+	return letters, rows.Err()
+}
+
+// MemoryStore - хранилище в памяти процесса, когда база не настроена.
+// Правила и журнал теряются при рестарте.
+type MemoryStore struct {
+	mu      sync.Mutex
+	rules   map[string]Rule
+	letters []DeadLetter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rules: make(map[string]Rule)}
+}
+
+func (s *MemoryStore) List(context.Context) ([]Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := make([]Rule, 0, len(s.rules))
+	for _, r := range s.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].CreatedAt.Before(rules[j].CreatedAt) })
+	return rules, nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Rule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.rules[id]
+	if !ok {
+		return Rule{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, r Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[r.ID] = r
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *MemoryStore) SaveState(_ context.Context, r Rule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.rules[r.ID]
+	if !ok {
+		return nil
+	}
+	existing.LastRate, existing.BaselineRate = r.LastRate, r.BaselineRate
+	existing.BaselineAt, existing.LastTriggeredAt = r.BaselineAt, r.LastTriggeredAt
+	s.rules[r.ID] = existing
+	return nil
+}
+
+func (s *MemoryStore) AppendDeadLetter(_ context.Context, d *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.ID = int64(len(s.letters) + 1)
+	s.letters = append(s.letters, *d)
+	return nil
+}
+
+func (s *MemoryStore) DeadLetters(_ context.Context, clientID string, limit int) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var letters []DeadLetter
+	for i := len(s.letters) - 1; i >= 0 && len(letters) < limit; i-- {
+		if clientID == "" || s.letters[i].ClientID == clientID {
+			letters = append(letters, s.letters[i])
+		}
+	}
+	return letters, nil
+}
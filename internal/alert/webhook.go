@@ -0,0 +1,252 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"currency-converter-v2/internal/metrics"
+	"currency-converter-v2/pkg/resilience"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// SignatureHeader - подпись тела: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	SignatureHeader = "X-Signature"
+	// TimestampHeader - unix-время подписи, входит в подписываемую строку
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+// Sign считает подпись тела вебхука
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись на стороне получателя
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
+
+// DispatcherConfig - параметры доставки вебхуков
+type DispatcherConfig struct {
+	Timeout     time.Duration // на одну попытку
+	MaxAttempts int           // всего попыток, включая первую
+	BaseDelay   time.Duration // задержка перед первым повтором, дальше экспоненциально
+	// AllowPrivate разрешает вебхуки на loopback, частные и link-local адреса
+	AllowPrivate bool
+}
+
+// Dispatcher асинхронно доставляет события; то, что не удалось доставить
+// за все попытки, пишется в журнал недоставленных.
+type Dispatcher struct {
+	client *http.Client
+	store  Store
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+type attemptsKey struct{}
+
+func NewDispatcher(cfg DispatcherConfig, store Store, logger *zap.Logger) *Dispatcher {
+	// Timeout клиента покрыл бы всю серию попыток, поэтому ограничиваем ожидание
+	// ответа на каждую попытку отдельно
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = cfg.Timeout
+	if !cfg.AllowPrivate {
+		// Адрес проверяется при соединении, после DNS: проверку имени обошел бы DNS rebinding.
+		// Прокси сам разрешает имя получателя, поэтому с проверкой он не используется.
+		base.Proxy = nil
+		base.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   checkWebhookAddress,
+		}).DialContext
+	}
+	transport := resilience.NewTransport(base, nil, resilience.RetryConfig{
+		MaxRetries: cfg.MaxAttempts - 1,
+		BaseDelay:  cfg.BaseDelay,
+		MaxDelay:   cfg.BaseDelay << 4,
+	}, resilience.Hooks{
+		OnAttempt: func(req *http.Request) {
+			if counter, ok := req.Context().Value(attemptsKey{}).(*atomic.Int32); ok {
+				counter.Add(1)
+			}
+		},
+	})
+	return &Dispatcher{
+		client: &http.Client{Transport: transport},
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Dispatch отправляет событие в фоне
+func (d *Dispatcher) Dispatch(rule Rule, event Event) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		d.Deliver(ctx, rule, event)
+	}()
+}
+
+// Wait дожидается завершения всех начатых доставок
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Deliver синхронно доставляет событие. Повторы - на сетевых ошибках, 429 и 5xx;
+// Idempotency-Key с ID события позволяет получателю отбросить дубликаты.
+func (d *Dispatcher) Deliver(ctx context.Context, rule Rule, event Event) bool {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Error("Failed to encode alert event", zap.String("alert_id", rule.ID), zap.Error(err))
+		return false
+	}
+
+	var attempts atomic.Int32
+	status, err := d.post(context.WithValue(ctx, attemptsKey{}, &attempts), rule, event.ID, body)
+	if err == nil && status >= 200 && status < 300 {
+		metrics.AlertWebhooks.WithLabelValues("delivered").Inc()
+		return true
+	}
+
+	metrics.AlertWebhooks.WithLabelValues("dead_letter").Inc()
+	letter := &DeadLetter{
+		AlertID:    rule.ID,
+		ClientID:   rule.ClientID,
+		EventID:    event.ID,
+		URL:        rule.WebhookURL,
+		Payload:    string(body),
+		Attempts:   int(attempts.Load()),
+		LastStatus: status,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err != nil {
+		letter.LastError = err.Error()
+	} else {
+		letter.LastError = fmt.Sprintf("unexpected status %d", status)
+	}
+	d.logger.Warn("Alert webhook delivery failed",
+		zap.String("alert_id", rule.ID),
+		zap.String("event_id", event.ID),
+		zap.Int("attempts", letter.Attempts),
+		zap.String("error", letter.LastError),
+	)
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	if err := d.store.AppendDeadLetter(storeCtx, letter); err != nil {
+		d.logger.Error("Failed to record dead letter",
+			zap.String("alert_id", rule.ID),
+			zap.String("event_id", event.ID),
+			zap.Error(err),
+		)
+	}
+	return false
+}
+
+// errForbiddenAddress - вебхук ведет во внутреннюю сеть
+var errForbiddenAddress = errors.New("webhook address is not allowed")
+
+// checkWebhookAddress запрещает соединения с внутренними адресами (защита от SSRF)
+func checkWebhookAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ip) {
+		return fmt.Errorf("%w: %s", errForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// deniedPrefixes - адреса, куда вебхуки не отправляются: внутренние, служебные
+// и IPv6-префиксы, внутри которых может быть любой IPv4 (NAT64, 6to4)
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "эта" сеть, включая 0.0.0.0
+	netip.MustParsePrefix("10.0.0.0/8"),      // частная
+	netip.MustParsePrefix("100.64.0.0/10"),   // CGNAT, внутренние адреса облаков и VPN
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, метаданные облака 169.254.169.254
+	netip.MustParsePrefix("172.16.0.0/12"),   // частная
+	netip.MustParsePrefix("192.0.0.0/24"),    // служебные протоколы IETF
+	netip.MustParsePrefix("192.0.2.0/24"),    // документация
+	netip.MustParsePrefix("192.168.0.0/16"),  // частная
+	netip.MustParsePrefix("198.18.0.0/15"),   // тестирование сетей
+	netip.MustParsePrefix("198.51.100.0/24"), // документация
+	netip.MustParsePrefix("203.0.113.0/24"),  // документация
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // зарезервировано, включая broadcast
+
+	netip.MustParsePrefix("::/128"),         // unspecified
+	netip.MustParsePrefix("::1/128"),        // loopback
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // локальный NAT64
+	netip.MustParsePrefix("100::/64"),       // discard
+	netip.MustParsePrefix("2001:db8::/32"),  // документация
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+	netip.MustParsePrefix("fc00::/7"),       // ULA
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+	netip.MustParsePrefix("ff00::/8"),       // multicast
+}
+
+// isPublicAddr - адрес не попадает ни в один из deniedPrefixes.
+// IPv4-mapped адреса (::ffff:a.b.c.d) проверяются как IPv4.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *Dispatcher) post(ctx context.Context, rule Rule, eventID string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rule.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", eventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(rule.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,212 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/refresher"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// receiver - локальный получатель вебхуков, проверяющий подпись
+type receiver struct {
+	server *httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	events   []Event
+	keys     []string
+	requests atomic.Int32
+	failures int32 // сколько первых запросов отклонить с 503
+}
+
+func newReceiver(t *testing.T, failures int32) *receiver {
+	rcv := &receiver{failures: failures}
+	rcv.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := rcv.requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(rcv.secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if n <= rcv.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		rcv.mu.Lock()
+		rcv.events = append(rcv.events, event)
+		rcv.keys = append(rcv.keys, r.Header.Get("Idempotency-Key"))
+		rcv.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(rcv.server.Close)
+	return rcv
+}
+
+func newTestManager(store Store, attempts int) *Manager {
+	dispatcher := NewDispatcher(DispatcherConfig{
+		Timeout:     time.Second,
+		MaxAttempts: attempts,
+		BaseDelay:   time.Millisecond,
+
+		AllowPrivate: true, // получатель слушает на 127.0.0.1
+	}, store, zap.NewNop())
+	return NewManager(store, dispatcher, zap.NewNop())
+}
+
+func TestManager_DeliversSignedWebhook(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	manager := newTestManager(store, 3)
+	rcv := newReceiver(t, 1)
+
+	rule, err := manager.Create(ctx, "client-a", Rule{
+		Kind: KindAbove, From: "usd", To: "rub", Threshold: 100, WebhookURL: rcv.server.URL,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rule.Secret, "whsec_")
+	rcv.secret = rule.Secret
+
+	assert.Equal(t, []refresher.Pair{{From: "USD", To: "RUB"}}, manager.Pairs(ctx))
+
+	manager.OnUpdate(ctx, refresher.Update{Base: "USD", Rates: map[string]float64{"RUB": 99}})
+	manager.OnUpdate(ctx, refresher.Update{Base: "USD", Rates: map[string]float64{"RUB": 101}})
+	manager.dispatcher.Wait()
+
+	require.Len(t, rcv.events, 1)
+	assert.Equal(t, rule.ID, rcv.events[0].AlertID)
+	assert.Equal(t, 101.0, rcv.events[0].Rate)
+	assert.Equal(t, rcv.events[0].ID, rcv.keys[0])
+	assert.Equal(t, int32(2), rcv.requests.Load(), "503 повторяется")
+
+	letters, err := manager.DeadLetters(ctx, "client-a", 10)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+func TestManager_DeadLetterAfterRetries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	manager := newTestManager(store, 3)
+	rcv := newReceiver(t, 100)
+
+	rule, err := manager.Create(ctx, "client-a", Rule{
+		Kind: KindBelow, From: "USD", To: "RUB", Threshold: 90, WebhookURL: rcv.server.URL,
+	})
+	require.NoError(t, err)
+	rcv.secret = rule.Secret
+
+	manager.OnUpdate(ctx, refresher.Update{Base: "USD", Rates: map[string]float64{"RUB": 91}})
+	manager.OnUpdate(ctx, refresher.Update{Base: "USD", Rates: map[string]float64{"RUB": 89}})
+	manager.dispatcher.Wait()
+
+	assert.Empty(t, rcv.events)
+	letters, err := manager.DeadLetters(ctx, "client-a", 10)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, rule.ID, letters[0].AlertID)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, letters[0].LastStatus)
+	assert.Contains(t, letters[0].Payload, `"rate":89`)
+
+	other, err := manager.DeadLetters(ctx, "client-b", 10)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestManager_ScopedToClient(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestManager(NewMemoryStore(), 1)
+
+	rule, err := manager.Create(ctx, "client-a", Rule{
+		Kind: KindAbove, From: "USD", To: "EUR", Threshold: 1, WebhookURL: "http://localhost/hook",
+	})
+	require.NoError(t, err)
+
+	_, err = manager.Get(ctx, "client-b", rule.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, manager.Delete(ctx, "client-b", rule.ID), ErrNotFound)
+
+	rules, err := manager.List(ctx, "client-b")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	updated, err := manager.Update(ctx, "client-a", rule.ID, Rule{
+		Kind: KindBelow, From: "USD", To: "EUR", Threshold: 0.8, WebhookURL: "http://localhost/hook",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rule.Secret, updated.Secret)
+	assert.Equal(t, KindBelow, updated.Kind)
+
+	require.NoError(t, manager.Delete(ctx, "client-a", rule.ID))
+}
+
+func TestDispatcher_RejectsPrivateAddresses(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	dispatcher := NewDispatcher(DispatcherConfig{Timeout: time.Second, MaxAttempts: 1}, store, zap.NewNop())
+	rcv := newReceiver(t, 0)
+
+	rule := Rule{ID: "alt_1", ClientID: "client-a", WebhookURL: rcv.server.URL}
+	assert.False(t, dispatcher.Deliver(ctx, rule, Event{ID: "evt_1"}))
+	assert.Zero(t, rcv.requests.Load(), "запрос не должен уйти на loopback")
+
+	letters, err := store.DeadLetters(ctx, "client-a", 10)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Contains(t, letters[0].LastError, "webhook address is not allowed")
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"100.64.0.1":       false,
+		"100.127.255.254":  false,
+		"0.1.2.3":          false,
+		"224.0.0.1":        false,
+		"239.255.255.250":  false,
+		"255.255.255.255":  false,
+		"ff02::1":          false,
+		"ff05::2":          false,
+		"64:ff9b::a00:1":   false, // NAT64 для 10.0.0.1
+		"64:ff9b:1::1":     false,
+		"2002:a00:1::1":    false, // 6to4 для 10.0.0.1
+		"fc00::1":          false,
+		"100.128.0.1":      true,
+		"8.8.8.8":          true,
+	} {
+		assert.Equal(t, public, isPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	signature := Sign("secret", 1700000000, body)
+	assert.True(t, Verify("secret", "1700000000", signature, body))
+	assert.False(t, Verify("other", "1700000000", signature, body))
+	assert.False(t, Verify("secret", "1700000001", signature, body))
+}
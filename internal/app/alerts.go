@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/alert"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/pkg/cache"
+	"currency-converter-v2/pkg/database"
+
+	"go.uber.org/zap"
+)
+
+// newRefresher готовит фоновое обновление курсов; пары из конфигурации уже проверены
+func newRefresher(cfg *config.Config, currencyService *service.CurrencyService, redis *cache.RedisClient, logger *zap.Logger) *refresher.Refresher {
+	pairs := make([]refresher.Pair, 0, len(cfg.Refresher.Pairs))
+	for _, raw := range cfg.Refresher.Pairs {
+		if pair, ok := refresher.ParsePair(raw); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	return refresher.New(currencyService, redis, cfg.Refresher.Interval, pairs, logger)
+}
+
+// newAlertManager готовит правила оповещений в PostgreSQL и подписывает их на обновления курсов.
+// Без базы правила живут только в памяти процесса.
+func newAlertManager(ctx context.Context, cfg *config.Config, db *database.Postgres, rates *refresher.Refresher, logger *zap.Logger) *alert.Manager {
+	var store alert.Store = alert.NewMemoryStore()
+	if db != nil {
+		initCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		pgStore, err := alert.NewPostgresStore(initCtx, db.DB())
+		if err != nil {
+			logger.Error("Failed to prepare alert tables, alerts are kept in memory only", zap.Error(err))
+		} else {
+			store = pgStore
+		}
+	} else {
+		logger.Warn("Database is not configured, alerts are kept in memory only")
+	}
+
+	dispatcher := alert.NewDispatcher(alert.DispatcherConfig{
+		Timeout:     cfg.Alerts.WebhookTimeout,
+		MaxAttempts: cfg.Alerts.MaxAttempts,
+		BaseDelay:   cfg.Alerts.RetryBaseDelay,
+
+		AllowPrivate: cfg.Alerts.AllowPrivateWebhooks,
+	}, store, logger)
+	manager := alert.NewManager(store, dispatcher, logger)
+	rates.Watch(manager.Pairs)
+	rates.Subscribe(manager.OnUpdate)
+	return manager
+}
@@ -3,37 +3,88 @@ package app
 import (
 	"context"
 	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/graphql"
 	"currency-converter-v2/internal/handler"
 	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/pricing"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/internal/quote"
 	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/stream"
+	"currency-converter-v2/internal/tracing"
+	"currency-converter-v2/internal/version"
 	"currency-converter-v2/pkg/cache"
+	"currency-converter-v2/pkg/database"
+	"currency-converter-v2/pkg/fixtures"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
+// LoadFunc заново читает конфигурацию из тех же источников, что и при старте
+type LoadFunc func() (*config.Config, error)
+
 type Application struct {
-	config *config.Config
-	router *gin.Engine
-	logger *zap.Logger
-	redis  *cache.RedisClient
-	server *http.Server
+	config         *config.Config
+	router         *gin.Engine
+	logger         *zap.Logger
+	logLevel       zap.AtomicLevel
+	redis          *cache.RedisClient
+	db             *database.Postgres
+	server         *http.Server
+	grpcServer     *grpc.Server
+	grpcHealth     *health.Server
+	grpcListener   net.Listener
+	tracerShutdown tracing.ShutdownFunc
+
+	// Перезагрузка по SIGHUP, см. reload.go
+	load     LoadFunc
+	current  atomic.Pointer[config.Config]
+	reloadMu sync.Mutex
+	service  *service.CurrencyService
+	quota    *quota.Tracker
+
+	// Фоновые задачи (обновление override и т.п.) останавливаются при shutdown
+	background     context.Context
+	stopBackground context.CancelFunc
 }
 
-func New(cfg *config.Config) *Application {
-	logger := initLogger(&cfg.Logging)
+// New собирает приложение. load используется для перезагрузки конфигурации
+// по SIGHUP; nil - перезагрузка выключена.
+func New(cfg *config.Config, load LoadFunc) *Application {
+	logger, logLevel := initLogger(&cfg.Logging, cfg.Secrets())
+	tracerShutdown, err := tracing.Init(cfg.Tracing, logger)
+	if err != nil {
+		logger.Error("Failed to initialize tracing", zap.Error(err))
+		// Продолжаем без трейсинга
+		tracerShutdown = func(context.Context) error { return nil }
+	}
 	reddisClient, err := cache.NewRedisClient(cfg.Redis, logger)
 	if err != nil {
+		// Сюда попадаем только при ошибке конфигурации: недоступный Redis
+		// не ошибка, клиент сам переподключится в фоне
 		logger.Error("Failed to create Redis client", zap.Error(err))
 		// Продолжаем без Redis
 	}
+	var db *database.Postgres
+	if cfg.Database.DSN.IsSet() {
+		db, err = database.NewPostgres(cfg.Database, logger)
+		if err != nil {
+			logger.Error("Failed to connect to database", zap.Error(err))
+			// Продолжаем без базы, readiness покажет down
+		}
+	}
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 		logger.Info("Running in RELEASE mode")
@@ -42,70 +93,181 @@ func New(cfg *config.Config) *Application {
 		logger.Info("Running in DEBUG mode")
 	}
 	router := gin.New()
-	currencyService := service.NewCurrencyService(cfg, reddisClient, logger)
+	quotaTracker := quota.NewTracker(cfg.Quota, reddisClient, logger)
+	currencyService := service.NewCurrencyService(cfg, reddisClient, quotaTracker, logger)
+	if cfg.Fixtures.Path != "" {
+		store, err := fixtures.Open(cfg.Fixtures.Path)
+		if err != nil {
+			logger.Fatal("Failed to load fixtures", zap.Error(err))
+		}
+		currencyService.UseFixtures(store, cfg.Fixtures.Record)
+		logger.Info("Fixtures enabled",
+			zap.String("path", cfg.Fixtures.Path),
+			zap.Int("pairs", store.Len()),
+			zap.Bool("record", cfg.Fixtures.Record),
+		)
+	}
+	if err := currencyService.SetProviders(cfg.API.Providers); err != nil {
+		logger.Fatal("Invalid provider list", zap.Error(err))
+	}
+	pricingRules, err := pricing.Load(cfg.Pricing)
+	if err != nil {
+		logger.Fatal("Invalid pricing rules", zap.Error(err))
+	}
+	currencyService.SetPricing(pricingRules)
+	currencyService.UseQuotes(quote.NewRedisStore(reddisClient), cfg.Quotes.TTL)
+	background, stopBackground := context.WithCancel(context.Background())
+	overrides := newOverrideManager(background, cfg, db, logger)
+	currencyService.UseOverrides(overrides)
+	auditStore := newAuditStore(background, db, logger)
+	currencyService.UseAudit(auditStore)
+	currencyService.UseHistory(newHistoryStore(background, db, logger))
+	rates := newRefresher(cfg, currencyService, reddisClient, logger)
+	alerts := newAlertManager(background, cfg, db, rates, logger)
+	hub := stream.NewHub(reddisClient, logger)
+	rates.Watch(hub.Pairs)
+	rates.Subscribe(hub.Publish)
+	go hub.Run(background)
+	go rates.Run(background)
 	currencyHandler := handler.NewCurrencyHandler(currencyService)
+	adminHandler := handler.NewAdminHandler(quotaTracker, []string{service.ProviderName})
+	overrideHandler := handler.NewOverrideHandler(overrides)
+	auditHandler := handler.NewAuditHandler(auditStore)
+	alertHandler := handler.NewAlertHandler(alerts)
+	streamHandler := handler.NewStreamHandler(hub, cfg.Stream.Keepalive)
+	wsHandler := handler.NewWSHandler(currencyService, hub, logger)
+	graphqlHandler := handler.NewGraphQLHandler(graphql.New(currencyService, logger))
 	app := &Application{
-		config: cfg,
-		router: router,
-		logger: logger,
-		redis:  reddisClient,
+		config:         cfg,
+		router:         router,
+		logger:         logger,
+		logLevel:       logLevel,
+		redis:          reddisClient,
+		db:             db,
+		tracerShutdown: tracerShutdown,
+		load:           load,
+		service:        currencyService,
+		quota:          quotaTracker,
+		background:     background,
+		stopBackground: stopBackground,
 	}
+	app.current.Store(cfg)
+	healthHandler := handler.NewHealthHandler(app.newHealthChecker(currencyService))
 	app.setupMiddleware()
-	app.setupRouter(currencyHandler)
+	app.setupGRPC(currencyService, hub)
+	app.setupRouter(currencyHandler, healthHandler, adminHandler, overrideHandler, auditHandler, alertHandler, streamHandler, wsHandler, graphqlHandler)
 	logger.Info("Application initialized",
+		zap.String("version", version.Version),
+		zap.String("commit", version.Commit),
 		zap.String("host", cfg.Server.Host),
 		zap.String("port", cfg.Server.Port),
-		zap.Bool("redis_connected", reddisClient != nil),
+		zap.Bool("redis_connected", reddisClient.Available()),
+		zap.Bool("database_connected", db != nil),
 	)
 	return app
 
 }
-func initLogger(cfg *config.LoggingConfig) *zap.Logger {
-	var logger *zap.Logger
-	var err error
-	if cfg.Format == "json" {
-		logger, err = zap.NewProduction()
-	} else {
-		logger, err = zap.NewDevelopment()
-	}
-	if err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
-	}
-	switch cfg.Level {
-	case "debug":
-		logger = logger.WithOptions(zap.IncreaseLevel(zap.DebugLevel))
-	case "info":
-		logger = logger.WithOptions(zap.IncreaseLevel(zap.InfoLevel))
-	case "warn":
-		logger = logger.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
-	case "error":
-		logger = logger.WithOptions(zap.IncreaseLevel(zap.ErrorLevel))
-	}
-	return logger
-}
 func (a *Application) setupMiddleware() {
 	a.router.Use(middleware.RecoveryMiddleware(a.logger))
+	a.router.Use(middleware.RequestIDMiddleware())
+	a.router.Use(middleware.TracingMiddleware())
 	a.router.Use(middleware.LoggingMiddleware(a.logger))
 	a.router.Use(middleware.CORSMiddleware())
 	a.logger.Debug("Middleware configured")
 }
-func (a *Application) setupRouter(currencyHandler *handler.CurrencyHandler) {
+func (a *Application) setupRouter(currencyHandler *handler.CurrencyHandler, healthHandler *handler.HealthHandler, adminHandler *handler.AdminHandler, overrideHandler *handler.OverrideHandler, auditHandler *handler.AuditHandler, alertHandler *handler.AlertHandler, streamHandler *handler.StreamHandler, wsHandler *handler.WSHandler, graphqlHandler *handler.GraphQLHandler) {
 	a.router.GET("/health", handler.HealthCheck)
-	apiV1 := a.router.Group("/api/v1")
+	a.router.GET("/livez", healthHandler.Livez)
+	a.router.GET("/readyz", healthHandler.Readyz)
+	a.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	a.router.GET("/openapi.json", handler.OpenAPISpec)
+	a.router.GET("/docs", handler.SwaggerUI)
+	// Ключи уже проверены в config.Validate
+	apiKeys, _ := a.config.Auth.ParseAPIKeys()
+	apiKey := middleware.APIKeyMiddleware(apiKeys, a.config.Auth.Required)
+	// Лимиты тарифов читаются из текущей конфигурации и меняются при перезагрузке
+	rateLimit := middleware.RateLimitMiddleware(func() config.RateLimitConfig { return a.Config().RateLimit }, a.redis, a.logger)
+	apiV1 := a.router.Group("/api/v1", apiKey, rateLimit)
 	apiV1.GET("/convert", currencyHandler.Convert)
+	apiV1.POST("/quotes", currencyHandler.CreateQuote)
+	apiV1.POST("/quotes/:id/execute", currencyHandler.ExecuteQuote)
+	apiV1.GET("/stream", streamHandler.Rates)
+	a.router.GET("/api/v1/ws", middleware.WebSocketAPIKey(), apiKey, rateLimit, wsHandler.Serve)
+	apiV1.GET("/alerts", alertHandler.List)
+	apiV1.POST("/alerts", alertHandler.Create)
+	apiV1.GET("/alerts/dead-letters", alertHandler.DeadLetters)
+	apiV1.GET("/alerts/:id", alertHandler.Get)
+	apiV1.PUT("/alerts/:id", alertHandler.Update)
+	apiV1.DELETE("/alerts/:id", alertHandler.Delete)
+	a.router.POST("/graphql", apiKey, rateLimit, graphqlHandler.Query)
+	admin := a.router.Group("/admin", middleware.AdminAuthMiddleware(a.config.Admin.Token.Value()))
+	admin.GET("/quota", adminHandler.Quota)
+	admin.GET("/overrides", overrideHandler.List)
+	admin.POST("/overrides", overrideHandler.Create)
+	admin.DELETE("/overrides/:id", overrideHandler.Delete)
+	admin.GET("/conversions", auditHandler.Conversions)
 	a.router.Static("/ui", "/app/frontend")
 	a.router.StaticFile("/", "/app/frontend/index.html")
 	a.logger.Debug("Routes configured",
 		zap.String("health", "GET /health"),
+		zap.String("livez", "GET /livez"),
+		zap.String("readyz", "GET /readyz"),
+		zap.String("metrics", "GET /metrics"),
+		zap.String("docs", "GET /openapi.json, GET /docs"),
 		zap.String("convert", "GET /api/v1/convert"),
+		zap.String("quotes", "POST /api/v1/quotes, POST /api/v1/quotes/:id/execute"),
+		zap.String("stream", "GET /api/v1/stream"),
+		zap.String("ws", "GET /api/v1/ws"),
+		zap.String("graphql", "POST /graphql"),
+		zap.String("alerts", "GET|POST /api/v1/alerts, GET|PUT|DELETE /api/v1/alerts/:id, GET /api/v1/alerts/dead-letters"),
+		zap.String("admin_quota", "GET /admin/quota"),
+		zap.String("admin_overrides", "GET|POST /admin/overrides, DELETE /admin/overrides/:id"),
+		zap.String("admin_conversions", "GET /admin/conversions"),
 		zap.String("frontend", "GET /ui"),
 	)
 }
 func (a *Application) Run() error {
-	a.server = &http.Server{
-		Addr:    a.config.Server.Addr(),
-		Handler: a.router,
+	listener, err := net.Listen("tcp", a.config.Server.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.config.Server.Addr(), err)
+	}
+	if a.grpcServer != nil {
+		a.grpcListener, err = net.Listen("tcp", a.config.GRPCAddr())
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to listen on %s: %w", a.config.GRPCAddr(), err)
+		}
+	}
+
+	// Настраиваем graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	// SIGHUP - перечитать конфигурацию без рестарта
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	return a.serve(listener, quit, hup)
+}
+
+// newServer собирает http.Server со всеми таймаутами из конфигурации
+func (a *Application) newServer() *http.Server {
+	return &http.Server{
+		Addr:              a.config.Server.Addr(),
+		Handler:           a.router,
+		ReadTimeout:       a.config.Server.ReadTimeout,
+		ReadHeaderTimeout: a.config.Server.ReadHeaderTimeout,
+		WriteTimeout:      a.config.Server.WriteTimeout,
+		IdleTimeout:       a.config.Server.IdleTimeout,
+		ErrorLog:          zap.NewStdLog(a.logger.Named("http")),
 	}
+}
+
+// serve обслуживает listener до сигнала из quit; сигналы из hup перезагружают конфигурацию
+func (a *Application) serve(listener net.Listener, quit, hup <-chan os.Signal) error {
+	a.server = a.newServer()
 
 	// Канал для ошибки сервера
 	serverErr := make(chan error, 1)
@@ -113,59 +275,93 @@ func (a *Application) Run() error {
 	// Запускаем сервер в горутине
 	go func() {
 		a.logger.Info("🚀 Server starting",
-			zap.String("address", a.server.Addr),
+			zap.String("address", listener.Addr().String()),
 			zap.String("mode", a.config.Server.Mode),
 		)
 
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := a.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 		close(serverErr)
 	}()
-
-	// Настраиваем graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	grpcErr := make(chan error, 1)
+	if a.grpcListener != nil {
+		a.serveGRPC(a.grpcListener, grpcErr)
+	}
 
 	// Ждем либо ошибку сервера, либо сигнал shutdown
-	select {
-	case err := <-serverErr:
-		return fmt.Errorf("server error: %w", err)
+	for {
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("server error: %w", err)
 
-	case sig := <-quit:
-		a.logger.Info("🛑 Received shutdown signal", zap.String("signal", sig.String()))
+		case err := <-grpcErr:
+			return fmt.Errorf("gRPC server error: %w", err)
 
-		// Graceful shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		case <-hup:
+			if err := a.Reload(); err != nil {
+				a.logger.Error("Configuration reload failed, keeping current config", zap.Error(err))
+			}
 
-		if err := a.server.Shutdown(ctx); err != nil {
-			return fmt.Errorf("graceful shutdown failed: %w", err)
-		}
+		case sig := <-quit:
+			a.logger.Info("🛑 Received shutdown signal", zap.String("signal", sig.String()))
+			a.stopBackground()
+
+			// Graceful shutdown: текущим запросам дается ShutdownTimeout
+			ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
+			defer cancel()
+
+			// gRPC останавливается параллельно с HTTP в пределах того же ShutdownTimeout
+			grpcStopped := make(chan struct{})
+			go func() {
+				a.stopGRPC(ctx)
+				close(grpcStopped)
+			}()
+			err := a.server.Shutdown(ctx)
+			<-grpcStopped
+			if err != nil {
+				return fmt.Errorf("graceful shutdown failed: %w", err)
+			}
 
-		a.logger.Info("✅ Server stopped gracefully")
-		return nil
+			// Отправляем оставшиеся спаны
+			if err := a.tracerShutdown(ctx); err != nil {
+				a.logger.Warn("Failed to flush traces", zap.Error(err))
+			}
+
+			a.logger.Info("✅ Server stopped gracefully")
+			return nil
+		}
 	}
 }
 
 // Shutdown корректно останавливает сервер
 func (a *Application) Shutdown() {
 	a.logger.Info("Starting graceful shutdown...")
+	a.stopBackground()
 
-	// Даем серверу 5 секунд на завершение текущих запросов
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Даем серверу ShutdownTimeout на завершение текущих запросов
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
 	defer cancel()
 
 	// Останавливаем прием новых соединений
 	if err := a.server.Shutdown(ctx); err != nil {
 		a.logger.Error("Failed to shutdown HTTP server", zap.Error(err))
 	}
+	a.stopGRPC(ctx)
 
 	// Закрываем соединение с Redis
 	if a.redis != nil {
 		a.redis.Close()
 	}
 
+	// Закрываем пул соединений с базой
+	a.db.Close()
+
+	// Отправляем оставшиеся спаны
+	if err := a.tracerShutdown(ctx); err != nil {
+		a.logger.Warn("Failed to flush traces", zap.Error(err))
+	}
+
 	// Сбрасываем буферы логгера
 	a.logger.Sync()
 
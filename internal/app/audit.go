@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/audit"
+	"currency-converter-v2/pkg/database"
+
+	"go.uber.org/zap"
+)
+
+// newAuditStore готовит журнал конвертаций в PostgreSQL.
+// Без базы журнал ведется только в памяти процесса.
+func newAuditStore(ctx context.Context, db *database.Postgres, logger *zap.Logger) audit.Store {
+	if db == nil {
+		logger.Warn("Database is not configured, conversion audit log is kept in memory only")
+		return audit.NewMemoryStore()
+	}
+	initCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	store, err := audit.NewPostgresStore(initCtx, db.DB())
+	if err != nil {
+		logger.Error("Failed to prepare conversion audit table, audit log is kept in memory only", zap.Error(err))
+		return audit.NewMemoryStore()
+	}
+	return store
+}
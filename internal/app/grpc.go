@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"net"
+
+	"currency-converter-v2/internal/grpcserver"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/stream"
+
+	"go.uber.org/zap"
+)
+
+// setupGRPC собирает gRPC сервер, если он включен в конфигурации
+func (a *Application) setupGRPC(currencyService service.CurrencyServiceInterface, hub *stream.Hub) {
+	if !a.config.GRPC.Enabled {
+		return
+	}
+	// Ключи уже проверены в config.Validate
+	apiKeys, _ := a.config.Auth.ParseAPIKeys()
+	a.grpcServer, a.grpcHealth = grpcserver.New(currencyService, hub, apiKeys, a.config.Auth.Required, a.logger)
+}
+
+// serveGRPC обслуживает listener; ошибка сервера уходит в errs
+func (a *Application) serveGRPC(listener net.Listener, errs chan<- error) {
+	a.logger.Info("🚀 gRPC server starting", zap.String("address", listener.Addr().String()))
+	go func() {
+		if err := a.grpcServer.Serve(listener); err != nil {
+			errs <- err
+		}
+	}()
+}
+
+// stopGRPC дожидается текущих вызовов до отмены ctx, затем обрывает оставшиеся
+func (a *Application) stopGRPC(ctx context.Context) {
+	if a.grpcServer == nil {
+		return
+	}
+	a.grpcHealth.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		a.logger.Warn("gRPC graceful stop timed out, closing remaining streams")
+		a.grpcServer.Stop()
+	}
+}
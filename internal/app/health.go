@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"currency-converter-v2/internal/health"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/version"
+	"currency-converter-v2/pkg/resilience"
+)
+
+// newHealthChecker собирает проверки зависимостей для /readyz
+func (a *Application) newHealthChecker(currencyService *service.CurrencyService) *health.Checker {
+	return health.NewChecker(a.config.Health.CheckTimeout, version.Version, version.Commit,
+		health.Check{
+			Name: "redis",
+			// Без Redis сервис продолжает работать напрямую с API,
+			// поэтому его падение переводит readiness только в degraded
+			Critical: false,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				if a.redis == nil {
+					return nil, errors.New("redis client is not configured")
+				}
+				err := a.redis.HealthCheck(ctx)
+				return map[string]any{"state": a.redis.State()}, err
+			},
+		},
+		health.Check{
+			Name:     "database",
+			Critical: true,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				if !a.config.Database.DSN.IsSet() {
+					return nil, health.ErrDisabled
+				}
+				if a.db == nil {
+					return nil, errors.New("database is not connected")
+				}
+				return nil, a.db.HealthCheck(ctx)
+			},
+		},
+		health.Check{
+			Name:     "upstream",
+			Critical: false,
+			Probe: func(ctx context.Context) (map[string]any, error) {
+				circuit := currencyService.CircuitState()
+				details := map[string]any{"circuit": circuit.String()}
+				if circuit == resilience.StateOpen {
+					return details, errors.New("circuit breaker is open")
+				}
+
+				last := currencyService.LastUpstreamSuccess()
+				if last.IsZero() {
+					details["last_success"] = nil
+					return details, errors.New("no successful upstream fetch yet")
+				}
+				age := time.Since(last)
+				details["last_success"] = last.UTC().Format(time.RFC3339)
+				details["age_seconds"] = int64(age.Seconds())
+				if age > a.config.Health.UpstreamMaxAge {
+					return details, fmt.Errorf("last successful upstream fetch is older than %v", a.config.Health.UpstreamMaxAge)
+				}
+				return details, nil
+			},
+		},
+	)
+}
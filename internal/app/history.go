@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/pkg/database"
+
+	"go.uber.org/zap"
+)
+
+// newHistoryStore готовит историю курсов в PostgreSQL.
+// Без базы история хранится только в памяти процесса.
+func newHistoryStore(ctx context.Context, db *database.Postgres, logger *zap.Logger) history.Store {
+	if db == nil {
+		logger.Warn("Database is not configured, rate history is kept in memory only")
+		return history.NewMemoryStore()
+	}
+	initCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	store, err := history.NewPostgresStore(initCtx, db.DB())
+	if err != nil {
+		logger.Error("Failed to prepare rate history table, history is kept in memory only", zap.Error(err))
+		return history.NewMemoryStore()
+	}
+	return store
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/logging"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func initLogger(cfg *config.LoggingConfig, secrets []string) (*zap.Logger, zap.AtomicLevel) {
+	// Уровень можно поменять на лету при перезагрузке конфигурации
+	level, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	}
+
+	var encoder zapcore.Encoder
+	options := []zap.Option{zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))}
+	if cfg.Format == "json" {
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		options = append(options, zap.AddStacktrace(zap.ErrorLevel))
+	} else {
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+		options = append(options, zap.Development(), zap.AddStacktrace(zap.WarnLevel))
+	}
+
+	output, err := logOutput(cfg)
+	if err != nil {
+		// Логгер нужен всем остальным компонентам, без него продолжать нельзя
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger := zap.New(zapcore.NewCore(encoder, output, level), options...)
+	// Значения секретов не должны попасть в лог ни в каком поле
+	return logger.WithOptions(logging.RedactOption(secrets)), level
+}
+
+// logOutput возвращает приемник логов: stdout, stderr или файл с ротацией по размеру и возрасту
+func logOutput(cfg *config.LoggingConfig) (zapcore.WriteSyncer, error) {
+	switch cfg.Output {
+	case "", "stdout":
+		return zapcore.Lock(os.Stdout), nil
+	case "stderr":
+		return zapcore.Lock(os.Stderr), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Output), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.Output,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxAge:     int(math.Ceil(cfg.MaxAge.Hours() / 24)), // lumberjack считает в днях
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}), nil
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ginParam - параметр пути в нотации gin (:id), в OpenAPI это {id}
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+// frontendRoute - статика фронтенда, в описание API не входит
+func frontendRoute(path string) bool {
+	return path == "/" || strings.HasPrefix(path, "/ui/")
+}
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	app := New(testConfig(), nil)
+	defer app.redis.Close()
+
+	var registered []string
+	for _, route := range app.router.Routes() {
+		if route.Method == http.MethodHead || frontendRoute(route.Path) {
+			continue
+		}
+		registered = append(registered, strings.ToLower(route.Method)+" "+ginParam.ReplaceAllString(route.Path, "{$1}"))
+	}
+	var documented []string
+	for path, ops := range openapi.Spec().Paths {
+		for method := range ops {
+			documented = append(documented, method+" "+path)
+		}
+	}
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, documented, registered)
+}
+
+// apiCase - запрос и ожидаемый статус; ответ должен быть описан в спецификации для этой операции
+type apiCase struct {
+	method  string
+	path    string // путь операции в спецификации
+	url     string
+	headers map[string]string
+	body    string
+	status  int // 0 - любой описанный в спецификации
+}
+
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	cfg := testConfig()
+	cfg.Auth.APIKeys = "shop-key:basic:shop"
+	cfg.Auth.Required = true
+	cfg.Admin.Token = "admin-token"
+	cfg.API.CurrencyAPIURL = "http://127.0.0.1:1"
+	app := New(cfg, nil)
+	defer app.redis.Close()
+
+	key := map[string]string{"X-API-Key": "shop-key"}
+	admin := map[string]string{"Authorization": "Bearer admin-token"}
+	validAlert := `{"kind":"above","from":"USD","to":"EUR","threshold":1,"webhook_url":"https://example.com/hook"}`
+	validOverride := `{"kind":"fixed","from":"EUR","to":"USD","rate":1.08}`
+
+	cases := []apiCase{
+		{method: "GET", path: "/health", url: "/health", status: 200},
+		{method: "GET", path: "/livez", url: "/livez", status: 200},
+		{method: "GET", path: "/readyz", url: "/readyz"},
+		{method: "GET", path: "/metrics", url: "/metrics", status: 200},
+		{method: "GET", path: "/openapi.json", url: "/openapi.json", status: 200},
+		{method: "GET", path: "/docs", url: "/docs", status: 200},
+
+		{method: "GET", path: "/api/v1/convert", url: "/api/v1/convert?from=USD&to=EUR&amount=1", status: 401},
+		{method: "GET", path: "/api/v1/convert", url: "/api/v1/convert?from=US&to=EUR&amount=1", headers: key, status: 400},
+		// Провайдер в тестах недоступен: ошибки приходят по каждой валюте
+		{method: "GET", path: "/api/v1/convert", url: "/api/v1/convert?from=USD&to=USD,EUR&targets=X&amount=1", headers: key, status: 200},
+		{method: "GET", path: "/api/v1/convert", url: "/api/v1/convert?from=USD&to=USD&amount=10&amount_in=target", headers: key, status: 200},
+		// Котировки лежат в Redis, а он в тестах недоступен
+		{method: "GET", path: "/api/v1/convert", url: "/api/v1/convert?quote_id=missing", headers: key, status: 503},
+		{method: "POST", path: "/api/v1/quotes", url: "/api/v1/quotes", headers: key, body: `{"from":"USD"}`, status: 400},
+		{method: "POST", path: "/api/v1/quotes/{id}/execute", url: "/api/v1/quotes/missing/execute", headers: key, status: 503},
+		{method: "POST", path: "/graphql", url: "/graphql", headers: key, body: `{"query":"{ currencies { code minorUnits } }"}`, status: 200},
+		{method: "POST", path: "/graphql", url: "/graphql", headers: key, body: `{"query":"{ unknown }"}`, status: 200},
+		{method: "POST", path: "/graphql", url: "/graphql", headers: key, body: `{}`, status: 400},
+		{method: "GET", path: "/api/v1/stream", url: "/api/v1/stream", headers: key, status: 400},
+		{method: "GET", path: "/api/v1/ws", url: "/api/v1/ws?api_key=wrong", status: 401},
+
+		{method: "GET", path: "/api/v1/alerts", url: "/api/v1/alerts", headers: key, status: 200},
+		{method: "POST", path: "/api/v1/alerts", url: "/api/v1/alerts", headers: key, body: validAlert, status: 201},
+		{method: "POST", path: "/api/v1/alerts", url: "/api/v1/alerts", headers: key, body: `{"kind":"sideways"}`, status: 400},
+		{method: "GET", path: "/api/v1/alerts/dead-letters", url: "/api/v1/alerts/dead-letters", headers: key, status: 200},
+		{method: "GET", path: "/api/v1/alerts/dead-letters", url: "/api/v1/alerts/dead-letters?limit=0", headers: key, status: 200},
+		{method: "GET", path: "/api/v1/alerts/dead-letters", url: "/api/v1/alerts/dead-letters?limit=100000", headers: key, status: 400},
+		{method: "GET", path: "/api/v1/alerts/{id}", url: "/api/v1/alerts/missing", headers: key, status: 404},
+		{method: "PUT", path: "/api/v1/alerts/{id}", url: "/api/v1/alerts/missing", headers: key, body: validAlert, status: 404},
+		{method: "DELETE", path: "/api/v1/alerts/{id}", url: "/api/v1/alerts/missing", headers: key, status: 404},
+
+		{method: "GET", path: "/admin/quota", url: "/admin/quota", headers: admin, status: 200},
+		{method: "GET", path: "/admin/quota", url: "/admin/quota", status: 401},
+		{method: "GET", path: "/admin/overrides", url: "/admin/overrides", headers: admin, status: 200},
+		{method: "POST", path: "/admin/overrides", url: "/admin/overrides", headers: admin, body: validOverride, status: 201},
+		{method: "POST", path: "/admin/overrides", url: "/admin/overrides", headers: admin, body: `{"kind":"fixed"}`, status: 400},
+		{method: "DELETE", path: "/admin/overrides/{id}", url: "/admin/overrides/missing", headers: admin, status: 404},
+		{method: "GET", path: "/admin/conversions", url: "/admin/conversions", headers: admin, status: 200},
+		{method: "GET", path: "/admin/conversions", url: "/admin/conversions?pair=USDEUR", headers: admin, status: 400},
+	}
+
+	spec := openapi.Spec()
+	covered := make(map[string]bool)
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			op := spec.Paths[tc.path][strings.ToLower(tc.method)]
+			require.NotNil(t, op, "operation is not documented")
+			covered[strings.ToLower(tc.method)+" "+tc.path] = true
+
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			app.router.ServeHTTP(w, req)
+			if tc.status != 0 {
+				require.Equal(t, tc.status, w.Code, w.Body.String())
+			}
+
+			resp := op.Responses[strconv.Itoa(w.Code)]
+			require.NotNil(t, resp, "status %d is not documented", w.Code)
+			media := resp.Content["application/json"]
+			if media == nil {
+				return
+			}
+			var body any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+			assert.Empty(t, validateSchema(spec, media.Schema, body, "$"), w.Body.String())
+		})
+	}
+
+	// Каждая описанная операция проверена хотя бы одним запросом
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			assert.True(t, covered[method+" "+path], "no response check for %s %s", method, path)
+		}
+	}
+}
+
+// validateSchema проверяет значение по схеме. Объекты со списком свойств считаются закрытыми:
+// лишнее поле в ответе - это расхождение с документацией.
+func validateSchema(doc *openapi.Document, s *openapi.Schema, v any, at string) []string {
+	if s.Ref != "" {
+		return validateSchema(doc, doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")], v, at)
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, option := range s.OneOf {
+			if len(validateSchema(doc, option, v, at)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return []string{fmt.Sprintf("%s: value matches %d of %d oneOf schemas", at, matched, len(s.OneOf))}
+		}
+		return nil
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+	fail := func(format string, args ...any) []string {
+		return []string{at + ": " + fmt.Sprintf(format, args...)}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("expected object, got %T", v)
+		}
+		var errs []string
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, at+"."+name+": required property is missing")
+			}
+		}
+		for name, value := range obj {
+			switch prop, ok := s.Properties[name]; {
+			case ok:
+				errs = append(errs, validateSchema(doc, prop, value, at+"."+name)...)
+			case s.AdditionalProperties != nil:
+				errs = append(errs, validateSchema(doc, s.AdditionalProperties, value, at+"."+name)...)
+			case s.Properties != nil:
+				errs = append(errs, at+"."+name+": property is not documented")
+			}
+		}
+		return errs
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fail("expected array, got %T", v)
+		}
+		var errs []string
+		for i, item := range items {
+			errs = append(errs, validateSchema(doc, s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return errs
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fail("expected string, got %T", v)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fail("invalid date-time %q", str)
+			}
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return fail("%q is not one of %v", str, s.Enum)
+		}
+	case "number", "integer":
+		n, ok := v.(float64)
+		if !ok {
+			return fail("expected %s, got %T", s.Type, v)
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fail("expected integer, got %v", n)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("expected boolean, got %T", v)
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
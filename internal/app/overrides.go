@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/override"
+	"currency-converter-v2/pkg/database"
+
+	"go.uber.org/zap"
+)
+
+// newOverrideManager загружает ручные курсы из PostgreSQL и подписывается на их обновление.
+// Без базы override живут только в памяти процесса.
+func newOverrideManager(ctx context.Context, cfg *config.Config, db *database.Postgres, logger *zap.Logger) *override.Manager {
+	var store override.Store = override.NewMemoryStore()
+	if db != nil {
+		initCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		pgStore, err := override.NewPostgresStore(initCtx, db.DB())
+		if err != nil {
+			logger.Error("Failed to prepare overrides table, overrides will not be persisted", zap.Error(err))
+		} else {
+			store = pgStore
+		}
+	} else {
+		logger.Warn("Database is not configured, rate overrides are kept in memory only")
+	}
+
+	manager := override.NewManager(store, logger)
+	loadCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := manager.Load(loadCtx); err != nil {
+		logger.Error("Failed to load rate overrides", zap.Error(err))
+	}
+	if _, persistent := store.(*override.PostgresStore); persistent {
+		go manager.Refresh(ctx, cfg.Overrides.RefreshInterval)
+	}
+	return manager
+}
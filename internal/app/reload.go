@@ -0,0 +1,102 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/pricing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config возвращает текущую действующую конфигурацию (с учетом перезагрузок)
+func (a *Application) Config() *config.Config {
+	return a.current.Load()
+}
+
+// Reload перечитывает конфигурацию и применяет ее перезагружаемую часть:
+// уровень логирования, TTL кеша в Redis, порядок провайдеров, правила наценки
+// и лимиты тарифов (rate_limit). Секция cache сейчас никто не читает и требует рестарта.
+// Все проверяется до применения: при любой ошибке действует прежняя конфигурация.
+func (a *Application) Reload() error {
+	if a.load == nil {
+		return errors.New("reload is not configured")
+	}
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	next, err := a.load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	level, err := zapcore.ParseLevel(next.Logging.Level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	rules, err := pricing.Load(next.Pricing)
+	if err != nil {
+		return fmt.Errorf("invalid pricing rules: %w", err)
+	}
+
+	prev := a.current.Load()
+	if changed := restartRequired(prev, next); len(changed) > 0 {
+		a.logger.Warn("Some configuration changes require a restart and were not applied",
+			zap.Strings("sections", changed))
+	}
+
+	// SetProviders - единственный шаг, который может отказать, поэтому он первый
+	if err := a.service.SetProviders(next.API.Providers); err != nil {
+		return err
+	}
+	a.logLevel.SetLevel(level)
+	a.redis.SetTTL(next.Redis.TTL, next.Redis.StaleTTL)
+	a.quota.SetExtendedTTL(next.Quota.ExtendedTTL)
+	a.service.SetPricing(rules)
+
+	// Остальные секции остаются от старта, подменяется только перезагружаемая часть
+	applied := *prev
+	applied.Logging.Level = next.Logging.Level
+	applied.Redis.TTL = next.Redis.TTL
+	applied.Redis.StaleTTL = next.Redis.StaleTTL
+	applied.Quota.ExtendedTTL = next.Quota.ExtendedTTL
+	applied.API.Providers = next.API.Providers
+	applied.Pricing = next.Pricing
+	applied.RateLimit = next.RateLimit
+	a.current.Store(&applied)
+
+	a.logger.Info("Configuration reloaded",
+		zap.String("log_level", level.String()),
+		zap.Duration("redis_ttl", next.Redis.TTL),
+		zap.Duration("redis_stale_ttl", next.Redis.StaleTTL),
+		zap.Duration("quota_extended_ttl", next.Quota.ExtendedTTL),
+		zap.Strings("providers", next.API.Providers),
+		zap.Any("pricing", next.Pricing),
+		zap.Any("rate_limit", next.RateLimit),
+	)
+	return nil
+}
+
+// restartRequired возвращает секции, изменения в которых на лету не применяются
+func restartRequired(prev, next *config.Config) []string {
+	a, b := *prev, *next
+	for _, c := range []*config.Config{&a, &b} {
+		c.Logging.Level = ""
+		c.Redis.TTL, c.Redis.StaleTTL = 0, 0
+		c.Quota.ExtendedTTL = 0
+		c.API.Providers = nil
+		c.Pricing = config.PricingConfig{}
+		c.RateLimit = config.RateLimitConfig{}
+	}
+
+	var changed []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
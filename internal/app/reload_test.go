@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Server.Mode = "test"
+	cfg.Redis.Addr = "127.0.0.1:1" // Redis недоступен, кеш в деградированном режиме
+	return cfg
+}
+
+func TestReload_AppliesReloadableSettings(t *testing.T) {
+	next := testConfig()
+	next.Logging.Level = "debug"
+	next.Redis.TTL = 5 * time.Minute
+	next.RateLimit.Free.Requests = 7
+	next.Server.Port = "9999" // требует рестарта, не применяется
+
+	app := New(testConfig(), func() (*config.Config, error) { return next, nil })
+	defer app.redis.Close()
+
+	require.NoError(t, app.Reload())
+
+	assert.Equal(t, zapcore.DebugLevel, app.logLevel.Level())
+	assert.Equal(t, 5*time.Minute, app.redis.TTL())
+	assert.Equal(t, 7, app.Config().RateLimit.Free.Requests)
+	assert.Equal(t, "8080", app.Config().Server.Port)
+}
+
+func TestReload_AppliesRateLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.Auth.APIKeys = "shop-key:free:shop"
+	cfg.RateLimit.Free.Requests = 2
+	next := testConfig()
+	next.Auth.APIKeys = cfg.Auth.APIKeys
+	next.RateLimit.Free.Requests = 4
+	app := New(cfg, func() (*config.Config, error) { return next, nil })
+	defer app.redis.Close()
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/alerts", nil)
+		req.Header.Set("X-API-Key", "shop-key")
+		app.router.ServeHTTP(w, req)
+		return w
+	}
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, get().Code)
+	}
+	w := get()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Новый лимит действует сразу, счетчик текущего окна сохраняется
+	require.NoError(t, app.Reload())
+	w = get()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, get().Code)
+}
+
+func TestReload_KeepsConfigOnFailure(t *testing.T) {
+	var next *config.Config
+	var loadErr error
+	app := New(testConfig(), func() (*config.Config, error) { return next, loadErr })
+	defer app.redis.Close()
+	before := app.Config()
+
+	loadErr = errors.New("logging.level: unknown value")
+	assert.Error(t, app.Reload())
+
+	next, loadErr = testConfig(), nil
+	next.Logging.Level = "debug"
+	next.API.Providers = []string{"exchangerate-api", "no-such-provider"}
+	assert.ErrorContains(t, app.Reload(), "no-such-provider")
+
+	assert.Same(t, before, app.Config())
+	assert.Equal(t, zapcore.InfoLevel, app.logLevel.Level(), "уровень не должен меняться частично")
+	assert.Equal(t, []string{"exchangerate-api"}, app.service.Providers())
+}
@@ -0,0 +1,314 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testServer struct {
+	app  *Application
+	addr string
+	quit chan os.Signal
+	done chan error
+
+	stopped bool
+}
+
+// startServer поднимает приложение на случайном порту
+func startServer(t *testing.T, mutate func(cfg *config.Config), routes func(r *gin.Engine)) *testServer {
+	t.Helper()
+	cfg := testConfig()
+	if mutate != nil {
+		mutate(cfg)
+	}
+	app := New(cfg, nil)
+	if routes != nil {
+		routes(app.router)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ts := &testServer{
+		app:  app,
+		addr: listener.Addr().String(),
+		quit: make(chan os.Signal, 1),
+		done: make(chan error, 1),
+	}
+	go func() { ts.done <- app.serve(listener, ts.quit, nil) }()
+	t.Cleanup(func() {
+		if !ts.stopped {
+			_, _ = ts.stop(t)
+		}
+		app.redis.Close()
+	})
+	return ts
+}
+
+func (ts *testServer) stop(t *testing.T) (time.Duration, error) {
+	t.Helper()
+	ts.stopped = true
+	started := time.Now()
+	ts.quit <- syscall.SIGTERM
+	select {
+	case err := <-ts.done:
+		return time.Since(started), err
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+		return 0, nil
+	}
+}
+
+// waitClosed читает соединение до EOF и возвращает, сколько это заняло
+func waitClosed(t *testing.T, conn net.Conn) time.Duration {
+	t.Helper()
+	started := time.Now()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, err := io.Copy(io.Discard, conn)
+	require.NoError(t, err, "server should close the connection before the client deadline")
+	return time.Since(started)
+}
+
+func TestServer_TimeoutsFromConfig(t *testing.T) {
+	ts := startServer(t, func(cfg *config.Config) {
+		cfg.Server.ReadTimeout = 11 * time.Second
+		cfg.Server.ReadHeaderTimeout = 3 * time.Second
+		cfg.Server.WriteTimeout = 12 * time.Second
+		cfg.Server.IdleTimeout = 13 * time.Second
+	}, nil)
+
+	resp, err := http.Get("http://" + ts.addr + "/livez")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, 11*time.Second, ts.app.server.ReadTimeout)
+	assert.Equal(t, 3*time.Second, ts.app.server.ReadHeaderTimeout)
+	assert.Equal(t, 12*time.Second, ts.app.server.WriteTimeout)
+	assert.Equal(t, 13*time.Second, ts.app.server.IdleTimeout)
+}
+
+func TestServer_ReadHeaderTimeout(t *testing.T) {
+	ts := startServer(t, func(cfg *config.Config) {
+		cfg.Server.ReadHeaderTimeout = 200 * time.Millisecond
+	}, nil)
+
+	conn, err := net.Dial("tcp", ts.addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Заголовки так и не дописываются до конца (slowloris)
+	_, err = conn.Write([]byte("GET /livez HTTP/1.1\r\nHost: test\r\n"))
+	require.NoError(t, err)
+
+	assert.Less(t, waitClosed(t, conn), 2*time.Second)
+}
+
+func TestServer_ReadTimeout(t *testing.T) {
+	ts := startServer(t, func(cfg *config.Config) {
+		cfg.Server.ReadTimeout = 200 * time.Millisecond
+	}, func(r *gin.Engine) {
+		r.POST("/echo", func(c *gin.Context) {
+			if _, err := io.ReadAll(c.Request.Body); err != nil {
+				c.Status(http.StatusRequestTimeout)
+				return
+			}
+			c.Status(http.StatusOK)
+		})
+	})
+
+	conn, err := net.Dial("tcp", ts.addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Тело обещано, но не отправляется
+	_, err = conn.Write([]byte("POST /echo HTTP/1.1\r\nHost: test\r\nContent-Length: 10\r\n\r\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode)
+}
+
+func TestServer_WriteTimeout(t *testing.T) {
+	ts := startServer(t, func(cfg *config.Config) {
+		cfg.Server.WriteTimeout = 200 * time.Millisecond
+	}, func(r *gin.Engine) {
+		r.GET("/slow", func(c *gin.Context) {
+			time.Sleep(500 * time.Millisecond)
+			c.String(http.StatusOK, "late")
+		})
+	})
+
+	_, err := http.Get("http://" + ts.addr + "/slow")
+	assert.Error(t, err, "ответ после WriteTimeout не должен дойти до клиента")
+}
+
+func TestServer_IdleTimeout(t *testing.T) {
+	ts := startServer(t, func(cfg *config.Config) {
+		cfg.Server.IdleTimeout = 200 * time.Millisecond
+	}, nil)
+
+	conn, err := net.Dial("tcp", ts.addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /livez HTTP/1.1\r\nHost: test\r\n\r\n"))
+	require.NoError(t, err)
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// keep-alive соединение без запросов закрывается сервером
+	started := time.Now()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(3*time.Second)))
+	_, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(started), 2*time.Second)
+}
+
+func TestServer_ShutdownGrace(t *testing.T) {
+	t.Run("in-flight request completes", func(t *testing.T) {
+		entered := make(chan struct{})
+		ts := startServer(t, func(cfg *config.Config) {
+			cfg.Server.ShutdownTimeout = 2 * time.Second
+		}, func(r *gin.Engine) {
+			r.GET("/slow", func(c *gin.Context) {
+				close(entered)
+				time.Sleep(200 * time.Millisecond)
+				c.String(http.StatusOK, "done")
+			})
+		})
+
+		result := make(chan error, 1)
+		go func() {
+			resp, err := http.Get("http://" + ts.addr + "/slow")
+			if err == nil {
+				resp.Body.Close()
+			}
+			result <- err
+		}()
+		<-entered
+
+		_, err := ts.stop(t)
+		assert.NoError(t, err)
+		assert.NoError(t, <-result)
+	})
+
+	t.Run("grace period expires", func(t *testing.T) {
+		entered := make(chan struct{})
+		ts := startServer(t, func(cfg *config.Config) {
+			cfg.Server.ShutdownTimeout = 200 * time.Millisecond
+		}, func(r *gin.Engine) {
+			r.GET("/slow", func(c *gin.Context) {
+				close(entered)
+				time.Sleep(2 * time.Second)
+			})
+		})
+
+		go func() {
+			if resp, err := http.Get("http://" + ts.addr + "/slow"); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-entered
+
+		elapsed, err := ts.stop(t)
+		assert.ErrorContains(t, err, "graceful shutdown failed")
+		assert.Less(t, elapsed, time.Second)
+	})
+}
+
+func TestServer_GRPCStopsWithHTTP(t *testing.T) {
+	app := New(testConfig(), nil)
+	defer app.redis.Close()
+	require.NotNil(t, app.grpcServer)
+
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	app.grpcListener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	quit := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() { done <- app.serve(httpListener, quit, nil) }()
+
+	conn, err := grpc.NewClient(app.grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	health := healthpb.NewHealthClient(conn)
+
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	quit <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = health.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Error(t, err, "gRPC сервер должен остановиться вместе с HTTP")
+}
+
+func TestInitLogger_FileOutputWithRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	logger, _ := initLogger(&config.LoggingConfig{
+		Level:      "info",
+		Format:     "json",
+		Output:     path,
+		MaxSizeMB:  1,
+		MaxBackups: 2,
+	}, nil)
+
+	logger.Info("to file", zap.String("marker", "first"))
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		logger.Info("filler", zap.String("payload", payload))
+	}
+	require.NoError(t, logger.Sync())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "app-*.log"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, files, "файл больше MaxSizeMB должен быть ротирован")
+}
+
+func TestLogOutput_StdStreams(t *testing.T) {
+	for _, output := range []string{"stdout", "stderr"} {
+		ws, err := logOutput(&config.LoggingConfig{Output: output})
+		require.NoError(t, err)
+		assert.NotNil(t, ws)
+	}
+}
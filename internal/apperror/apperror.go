@@ -0,0 +1,145 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind - стабильный машинный код ошибки, который видит клиент
+type Kind string
+
+const (
+	KindValidation          Kind = "validation"
+	KindUnsupportedCurrency Kind = "unsupported_currency"
+	KindUpstreamUnavailable Kind = "upstream_unavailable"
+	KindRateLimited         Kind = "rate_limited"
+	KindUnauthorized        Kind = "unauthorized"
+	KindForbidden           Kind = "forbidden"
+	KindNotFound            Kind = "not_found"
+	KindConflict            Kind = "conflict"
+	KindInternal            Kind = "internal"
+)
+
+// Error - типизированная ошибка приложения.
+// Message безопасно отдавать клиенту, Err хранит внутреннюю причину только для логов.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus возвращает HTTP статус, соответствующий типу ошибки
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnsupportedCurrency:
+		return http.StatusUnprocessableEntity
+	case KindUpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Title возвращает короткое человекочитаемое описание типа ошибки
+func (k Kind) Title() string {
+	switch k {
+	case KindValidation:
+		return "Invalid request"
+	case KindUnsupportedCurrency:
+		return "Unsupported currency"
+	case KindUpstreamUnavailable:
+		return "Exchange rate provider unavailable"
+	case KindRateLimited:
+		return "Too many requests"
+	case KindUnauthorized:
+		return "Unauthorized"
+	case KindForbidden:
+		return "Forbidden"
+	case KindNotFound:
+		return "Not found"
+	case KindConflict:
+		return "Conflict"
+	default:
+		return "Internal server error"
+	}
+}
+
+func New(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+func Validation(message string) *Error {
+	return New(KindValidation, message, nil)
+}
+
+func UnsupportedCurrency(code string) *Error {
+	return New(KindUnsupportedCurrency, fmt.Sprintf("currency %s is not supported", code), nil)
+}
+
+func UpstreamUnavailable(err error) *Error {
+	return New(KindUpstreamUnavailable, "exchange rate provider is temporarily unavailable", err)
+}
+
+func RateLimited(message string) *Error {
+	return New(KindRateLimited, message, nil)
+}
+
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message, nil)
+}
+
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message, nil)
+}
+
+func NotFound(message string) *Error {
+	return New(KindNotFound, message, nil)
+}
+
+func Conflict(message string) *Error {
+	return New(KindConflict, message, nil)
+}
+
+func Internal(err error) *Error {
+	return New(KindInternal, "something went wrong", err)
+}
+
+// From приводит произвольную ошибку к *Error.
+// Неизвестные ошибки считаются внутренними, их текст клиенту не раскрывается.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// IsKind проверяет, относится ли ошибка к указанному типу
+func IsKind(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
@@ -0,0 +1,64 @@
+// Package audit - неизменяемый журнал конвертаций: какой курс и как применен к каждой сумме.
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Entry - запись журнала. После записи не меняется и не удаляется.
+type Entry struct {
+	ID            int64     `json:"id"`
+	RequestID     string    `json:"request_id"`
+	ClientID      string    `json:"client_id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Amount        float64   `json:"amount"`
+	MidRate       float64   `json:"mid_rate"`
+	Rate          float64   `json:"rate"` // после наценки
+	MarkupPct     float64   `json:"markup_pct"`
+	Fee           float64   `json:"fee"`
+	RateSource    string    `json:"rate_source"`
+	RateProvider  string    `json:"rate_provider,omitempty"`
+	RateTimestamp time.Time `json:"rate_timestamp"`
+	Stale         bool      `json:"stale"`
+	RawResult     float64   `json:"raw_result"` // до округления
+	Result        float64   `json:"result"`
+	Rounding      string    `json:"rounding"`
+	OverrideID    string    `json:"override_id,omitempty"`
+	QuoteID       string    `json:"quote_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Filter - условия выборки. Пустые поля не фильтруют.
+// Записи отдаются от новых к старым; Before - ID, с которого продолжить (курсор).
+type Filter struct {
+	ClientID string
+	From     string
+	To       string
+	Since    time.Time
+	Until    time.Time
+	Before   int64
+	Limit    int
+}
+
+// Match проверяет запись на соответствие фильтру (без учета Limit)
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case f.ClientID != "" && e.ClientID != f.ClientID,
+		f.From != "" && e.From != f.From,
+		f.To != "" && e.To != f.To,
+		!f.Since.IsZero() && e.CreatedAt.Before(f.Since),
+		!f.Until.IsZero() && !e.CreatedAt.Before(f.Until),
+		f.Before > 0 && e.ID >= f.Before:
+		return false
+	}
+	return true
+}
+
+// Store - хранилище журнала: только добавление и чтение
+type Store interface {
+	// Append сохраняет запись и проставляет ей ID
+	Append(ctx context.Context, e *Entry) error
+	Query(ctx context.Context, f Filter) ([]Entry, error)
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PostgresStore хранит журнал в таблице conversion_audit.
+// UPDATE и DELETE запрещены триггером, записи можно только добавлять.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS conversion_audit (
+	id             BIGSERIAL PRIMARY KEY,
+	request_id     TEXT NOT NULL DEFAULT '',
+	client_id      TEXT NOT NULL,
+	from_code      CHAR(3) NOT NULL,
+	to_code        CHAR(3) NOT NULL,
+	amount         DOUBLE PRECISION NOT NULL,
+	mid_rate       DOUBLE PRECISION NOT NULL,
+	rate           DOUBLE PRECISION NOT NULL,
+	markup_pct     DOUBLE PRECISION NOT NULL,
+	fee            DOUBLE PRECISION NOT NULL,
+	rate_source    TEXT NOT NULL,
+	rate_timestamp TIMESTAMPTZ NOT NULL,
+	raw_result     DOUBLE PRECISION NOT NULL,
+	result         DOUBLE PRECISION NOT NULL,
+	rounding       TEXT NOT NULL,
+	override_id    TEXT NOT NULL DEFAULT '',
+	quote_id       TEXT NOT NULL DEFAULT '',
+	created_at     TIMESTAMPTZ NOT NULL
+);
+-- Происхождение курса добавлено позже, колонки докатываются на существующую таблицу
+ALTER TABLE conversion_audit ADD COLUMN IF NOT EXISTS rate_provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE conversion_audit ADD COLUMN IF NOT EXISTS stale BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS conversion_audit_client_idx ON conversion_audit (client_id, id);
+CREATE INDEX IF NOT EXISTS conversion_audit_created_idx ON conversion_audit (created_at);
+
+CREATE OR REPLACE FUNCTION conversion_audit_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'conversion_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS conversion_audit_immutable ON conversion_audit;
+CREATE TRIGGER conversion_audit_immutable
+	BEFORE UPDATE OR DELETE ON conversion_audit
+	FOR EACH ROW EXECUTE FUNCTION conversion_audit_immutable();
+`
+
+// NewPostgresStore создает таблицу и триггер, если их еще нет
+func NewPostgresStore(ctx context.Context, db *sql.DB) (*PostgresStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create conversion_audit table: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) Append(ctx context.Context, e *Entry) error {
+	return s.db.QueryRowContext(ctx, `
+		INSERT INTO conversion_audit (request_id, client_id, from_code, to_code, amount, mid_rate, rate,
+			markup_pct, fee, rate_source, rate_timestamp, raw_result, result, rounding,
+			override_id, quote_id, created_at, rate_provider, stale)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		RETURNING id`,
+		e.RequestID, e.ClientID, e.From, e.To, e.Amount, e.MidRate, e.Rate,
+		e.MarkupPct, e.Fee, e.RateSource, e.RateTimestamp, e.RawResult, e.Result, e.Rounding,
+		e.OverrideID, e.QuoteID, e.CreatedAt, e.RateProvider, e.Stale,
+	).Scan(&e.ID)
+}
+
+func (s *PostgresStore) Query(ctx context.Context, f Filter) ([]Entry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ClientID != "" {
+		add("client_id = $%d", f.ClientID)
+	}
+	if f.From != "" {
+		add("from_code = $%d", f.From)
+	}
+	if f.To != "" {
+		add("to_code = $%d", f.To)
+	}
+	if !f.Since.IsZero() {
+		add("created_at >= $%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("created_at < $%d", f.Until)
+	}
+	if f.Before > 0 {
+		add("id < $%d", f.Before)
+	}
+
+	query := `
+		SELECT id, request_id, client_id, from_code, to_code, amount, mid_rate, rate,
+			markup_pct, fee, rate_source, rate_timestamp, raw_result, result, rounding,
+			override_id, quote_id, created_at, rate_provider, stale
+		FROM conversion_audit`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.ClientID, &e.From, &e.To, &e.Amount, &e.MidRate, &e.Rate,
+			&e.MarkupPct, &e.Fee, &e.RateSource, &e.RateTimestamp, &e.RawResult, &e.Result, &e.Rounding,
+			&e.OverrideID, &e.QuoteID, &e.CreatedAt, &e.RateProvider, &e.Stale); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// MemoryStore - журнал в памяти процесса, когда база не настроена.
+// Записи теряются при рестарте.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Append(_ context.Context, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.ID = int64(len(s.entries) + 1)
+	s.entries = append(s.entries, *e)
+	return nil
+}
+
+func (s *MemoryStore) Query(_ context.Context, f Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []Entry
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < f.Limit; i-- {
+		if f.Match(s.entries[i]) {
+			entries = append(entries, s.entries[i])
+		}
+	}
+	return entries, nil
+}
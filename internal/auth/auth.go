@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"currency-converter-v2/internal/config"
+)
+
+// Client - клиент API, определенный по ключу
+type Client struct {
+	ID   string
+	Plan string
+}
+
+// Anonymous - клиент без ключа, получает тариф free
+var Anonymous = Client{ID: "anonymous", Plan: config.PlanFree}
+
+type clientKey struct{}
+
+// WithClient сохраняет клиента в контексте запроса
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom возвращает клиента из контекста или Anonymous
+func ClientFrom(ctx context.Context) Client {
+	if client, ok := ctx.Value(clientKey{}).(Client); ok {
+		return client
+	}
+	return Anonymous
+}
+
+// Lookup находит клиента по ключу, сравнивая его со всеми известными за постоянное время
+func Lookup(keys []config.APIKey, provided string) (Client, bool) {
+	var (
+		client Client
+		found  bool
+	)
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key.Key)) == 1 {
+			client = Client{ID: key.ClientID, Plan: key.Plan}
+			found = true
+		}
+	}
+	return client, found
+}
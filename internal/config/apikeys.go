@@ -0,0 +1,57 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Тарифы клиентов, совпадают с секциями RateLimitConfig
+const (
+	PlanFree    = "free"
+	PlanBasic   = "basic"
+	PlanPremium = "premium"
+)
+
+// APIKey - ключ клиента из AuthConfig.APIKeys
+type APIKey struct {
+	Key      string
+	Plan     string
+	ClientID string // по умолчанию - отпечаток ключа, сам ключ в логи не попадает
+}
+
+// ParseAPIKeys разбирает список "ключ:тариф[:id клиента]" через запятую
+func (a AuthConfig) ParseAPIKeys() ([]APIKey, error) {
+	var keys []APIKey
+	seen := make(map[string]bool)
+	for i, entry := range strings.Split(a.APIKeys.Value(), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			// Сам ключ в ошибку не попадает
+			return nil, fmt.Errorf("entry %d: expected key:plan[:client_id]", i+1)
+		}
+		key := APIKey{Key: parts[0], Plan: parts[1]}
+		switch key.Plan {
+		case PlanFree, PlanBasic, PlanPremium:
+		default:
+			return nil, fmt.Errorf("entry %d: unknown plan %q", i+1, key.Plan)
+		}
+		if len(parts) == 3 && parts[2] != "" {
+			key.ClientID = parts[2]
+		} else {
+			sum := sha256.Sum256([]byte(key.Key))
+			key.ClientID = "key-" + hex.EncodeToString(sum[:4])
+		}
+		if seen[key.Key] {
+			return nil, fmt.Errorf("entry %d: duplicate key", i+1)
+		}
+		seen[key.Key] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
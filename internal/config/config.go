@@ -1,13 +1,7 @@
 package config
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 type Config struct {
@@ -19,30 +13,86 @@ type Config struct {
 	RateLimit RateLimitConfig
 	Cache     CacheConfig
 	Logging   LoggingConfig
+	Tracing   TracingConfig
+	Health    HealthConfig
+	Quota     QuotaConfig
+	Admin     AdminConfig
+	Fixtures  FixturesConfig
+	Overrides OverridesConfig
+	Auth      AuthConfig
+	Pricing   PricingConfig
+	Quotes    QuotesConfig
+	Refresher RefresherConfig
+	Alerts    AlertsConfig
+	Stream    StreamConfig
+	GRPC      GRPCConfig
 }
 type ServerConfig struct {
-	Port         string
-	Host         string
-	Mode         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port              string
+	Host              string
+	Mode              string
+	ReadTimeout       time.Duration // чтение всего запроса вместе с телом
+	ReadHeaderTimeout time.Duration // чтение заголовков, защита от slowloris
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration // сколько держать keep-alive соединение без запросов
+	ShutdownTimeout   time.Duration // сколько ждать текущие запросы при остановке
 }
 type RedisConfig struct {
-	Addr     string
-	Password string
-	DB       int
+	Mode       string   // "standalone", "sentinel" или "cluster"
+	Addr       string   // адрес для standalone
+	Addrs      []string // адреса sentinel-узлов или seed-узлов кластера
+	MasterName string   // имя мастера в sentinel
+	Username   string   // ACL пользователь
+	Password   Secret
+	DB         int // не поддерживается в cluster
+
+	SentinelUsername string
+	SentinelPassword Secret
+
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Задержки фонового переподключения, пока Redis недоступен
+	ReconnectMinBackoff time.Duration
+	ReconnectMaxBackoff time.Duration
+
+	TLS      RedisTLSConfig
 	TTL      time.Duration
+	StaleTTL time.Duration // сколько хранить последний известный курс после истечения TTL
+}
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string // клиентский сертификат для mTLS
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
 }
 type DatabaseConfig struct {
-	DSN string // Connection string для PostgreSQL
+	DSN Secret // Connection string для PostgreSQL, содержит пароль
 }
 type APIConfig struct {
-	CurrencyKeyAPI string
+	CurrencyKeyAPI Secret
 	CurrencyAPIURL string
-	Timeout        time.Duration
+	Timeout        time.Duration // общий бюджет на запрос вместе с повторами
+
+	// Провайдеры курсов в порядке опроса: следующий используется, если предыдущий недоступен
+	Providers []string
+
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	MaxRetryAfter  time.Duration // Retry-After больше этого значения не ждем
+
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenRequests int
 }
 type JWTConfig struct {
-	JWTSecret  string
+	JWTSecret  Secret
 	Expiration time.Duration
 }
 type RateLimitConfig struct {
@@ -61,132 +111,216 @@ type CacheConfig struct {
 type LoggingConfig struct {
 	Level  string // "debug", "info", "warn", "error"
 	Format string // "json" или "text"
-	Output string // "stdout" или файл
+	Output string // "stdout", "stderr" или путь к файлу
+
+	// Ротация файла логов (для stdout/stderr не используется)
+	MaxSizeMB  int           // размер файла, после которого он ротируется
+	MaxAge     time.Duration // сколько хранить ротированные файлы, 0 - без ограничения
+	MaxBackups int           // сколько ротированных файлов хранить, 0 - все
+	Compress   bool          // сжимать ротированные файлы gzip
+}
+type TracingConfig struct {
+	Enabled     bool
+	Exporter    string // "otlp" или "stdout"
+	Endpoint    string // адрес OTLP коллектора (host:port)
+	Insecure    bool   // OTLP без TLS
+	ServiceName string
+	SampleRatio float64 // доля запросов, попадающих в трейс (0..1)
+}
+type HealthConfig struct {
+	CheckTimeout   time.Duration // таймаут одной проверки readiness
+	UpstreamMaxAge time.Duration // после этого срока без успешного запроса к API upstream считается down
+}
+
+// QuotaConfig - бюджет запросов к upstream на расчетный период (месяц)
+type QuotaConfig struct {
+	Budget             int           // запросов за период, 0 - без ограничений
+	BillingDay         int           // день месяца, с которого начинается период (1-28)
+	ConserveThreshold  float64       // доля бюджета, после которой курсы кешируются на ExtendedTTL
+	StaleOnlyThreshold float64       // доля бюджета, после которой upstream не вызывается
+	ExtendedTTL        time.Duration // TTL кеша в режиме экономии
+}
+
+// FixturesConfig - офлайн-курсы из локального файла.
+// Провайдер "fixture" в API.Providers отдает курсы из файла (replay),
+// Record=true дописывает в файл ответы настоящего API (record).
+type FixturesConfig struct {
+	Path   string // .json или .csv
+	Record bool
+}
+
+// OverridesConfig - ручные курсы (fixed/peg), хранятся в PostgreSQL
+type OverridesConfig struct {
+	RefreshInterval time.Duration // как часто подхватывать изменения, сделанные другими инстансами
+}
+
+// AuthConfig - API ключи клиентов. Ключ определяет клиента и его тариф (free, basic, premium).
+type AuthConfig struct {
+	// Список "ключ:тариф[:id клиента]" через запятую
+	APIKeys Secret
+	// Без ключа запросы к /api/* отклоняются; иначе анонимные клиенты получают тариф free
+	Required bool
+}
+
+// PricingConfig - наценка (спред) и комиссии при конвертации.
+// Правило по умолчанию задается здесь, правила по парам, валютам и тарифам - в файле.
+type PricingConfig struct {
+	File      string  // YAML или JSON с правилами, см. internal/pricing
+	SpreadPct float64 // наценка к среднему курсу, %
+	FeePct    float64 // комиссия от суммы, %
+	FeeFixed  float64 // фиксированная комиссия в валюте списания
+	Rounding  string  // none, half_up, half_even, down - до минимальной единицы валюты
+}
+
+// QuotesConfig - котировки с фиксированным курсом (/api/v1/quotes), хранятся в Redis
+type QuotesConfig struct {
+	TTL time.Duration // сколько котировку можно исполнить
+}
+
+// RefresherConfig - фоновое обновление курсов для алертов и стримов
+type RefresherConfig struct {
+	Interval time.Duration // как часто обновлять курсы
+	Pairs    []string      // пары "USD/EUR", которые обновляются всегда
+}
+
+// AlertsConfig - доставка вебхуков оповещений о курсе
+type AlertsConfig struct {
+	WebhookTimeout time.Duration // ожидание ответа на одну попытку
+	MaxAttempts    int           // всего попыток, после них - в журнал недоставленных
+	RetryBaseDelay time.Duration // задержка перед первым повтором, дальше экспоненциально
+	// AllowPrivateWebhooks разрешает вебхуки на loopback, частные и link-local адреса
+	AllowPrivateWebhooks bool
+}
+
+// StreamConfig - поток обновлений курсов (/api/v1/stream)
+type StreamConfig struct {
+	Keepalive time.Duration // интервал комментариев-пингов, чтобы прокси не закрывали соединение
+}
+
+// GRPCConfig - gRPC API на отдельном порту, хост тот же, что у HTTP
+type GRPCConfig struct {
+	Enabled bool
+	Port    string
+}
+
+type AdminConfig struct {
+	Token Secret // токен для /admin/*, пустой - админка выключена
 }
 
 // Метод для получения адреса сервера
 func (s *ServerConfig) Addr() string {
 	return s.Host + ":" + s.Port
 }
-func getEnv(key string, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
-	}
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
-	}
-	return value
-}
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
-	}
-	value, err := time.ParseDuration(valueStr)
-	if err != nil {
-		return defaultValue
-	}
-	return value
+
+// GRPCAddr - адрес gRPC сервера
+func (c *Config) GRPCAddr() string {
+	return c.Server.Host + ":" + c.GRPC.Port
 }
-func getEnvAsBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
-		}
-	}
-	return defaultValue
-}
-func Load() *Config {
-	// 🔥 ПРАВИЛЬНАЯ ЗАГРУЗКА .env файла
-	fmt.Println("=== LOADING CONFIGURATION ===")
-
-	// Вариант 1: Загрузить .env без указания пути (godotenv найдет сам)
-	if err := godotenv.Load(); err != nil {
-		fmt.Printf("⚠️ godotenv.Load() failed: %v\n", err)
-
-		// Вариант 2: Попробуем с абсолютным путем
-		cwd, _ := os.Getwd()
-		envPath := filepath.Join(cwd, ".env")
-		fmt.Printf("📁 Trying absolute path: %s\n", envPath)
-
-		if err := godotenv.Load(envPath); err != nil {
-			fmt.Printf("❌ ERROR: Cannot load .env file from %s: %v\n", envPath, err)
-			fmt.Println("📝 Using default values instead...")
-		} else {
-			fmt.Printf("✅ .env loaded from: %s\n", envPath)
-		}
-	} else {
-		fmt.Println("✅ .env loaded successfully")
-	}
 
-	// 🔥 ПРОВЕРКА: что переменные загрузились
-	fmt.Println("=== ENVIRONMENT VARIABLES ===")
-	fmt.Printf("CURRENCY_KEY_API: '%s' (length: %d)\n",
-		os.Getenv("CURRENCY_KEY_API"),
-		len(os.Getenv("CURRENCY_KEY_API")))
-	fmt.Printf("CURRENCY_API_URL: '%s'\n", os.Getenv("CURRENCY_API_URL"))
-	fmt.Printf("PORT: '%s'\n", os.Getenv("PORT"))
-	fmt.Println("=============================")
+// defaultJWTSecret - заглушка для локальной разработки, в release режиме запрещена
+const defaultJWTSecret = "your-super-secret-key-change-this-in-production"
 
-	// Используем значения из .env или дефолтные
+// Default возвращает конфигурацию со значениями по умолчанию - нижний слой перед файлом, env и флагами
+func Default() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			Host:         getEnv("HOST", "0.0.0.0"),
-			Mode:         getEnv("GIN_MODE", "debug"),
-			ReadTimeout:  getEnvAsDuration("READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getEnvAsDuration("WRITE_TIMEOUT", 10*time.Second),
+			Port:              "8080",
+			Host:              "0.0.0.0",
+			Mode:              "debug",
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
 		},
 		Redis: RedisConfig{
-			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
-			TTL:      getEnvAsDuration("REDIS_TTL", 30*time.Minute),
-		},
-		Database: DatabaseConfig{
-			DSN: getEnv("DATABASE_URL", ""),
+			Mode:                "standalone",
+			Addr:                "localhost:6379",
+			DialTimeout:         5 * time.Second,
+			ReadTimeout:         3 * time.Second,
+			WriteTimeout:        3 * time.Second,
+			ReconnectMinBackoff: 500 * time.Millisecond,
+			ReconnectMaxBackoff: 30 * time.Second,
+			TTL:                 30 * time.Minute,
+			StaleTTL:            7 * 24 * time.Hour,
 		},
 		API: APIConfig{
-			// 🔥 ВАЖНО: проверь правильное имя переменной
-			CurrencyKeyAPI: getEnv("CURRENCY_KEY_API", "4cd60470d61ac235ae2e1f77"),
-			CurrencyAPIURL: getEnv("CURRENCY_API_URL", "https://v6.exchangerate-api.com"),
-			Timeout:        getEnvAsDuration("API_TIMEOUT", 10*time.Second),
+			// Ключа по умолчанию нет: задай CURRENCY_KEY_API или CURRENCY_KEY_API_FILE
+			CurrencyAPIURL: "https://v6.exchangerate-api.com",
+			Timeout:        10 * time.Second,
+			Providers:      []string{"exchangerate-api"},
+
+			MaxRetries:     2,
+			RetryBaseDelay: 200 * time.Millisecond,
+			RetryMaxDelay:  2 * time.Second,
+			MaxRetryAfter:  5 * time.Second,
+
+			BreakerFailureThreshold: 5,
+			BreakerOpenTimeout:      30 * time.Second,
+			BreakerHalfOpenRequests: 1,
 		},
 		JWT: JWTConfig{
-			JWTSecret:  getEnv("JWT_SECRET", "your-super-secret-key-change-this-in-production"),
-			Expiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+			JWTSecret:  defaultJWTSecret,
+			Expiration: 24 * time.Hour,
 		},
 		RateLimit: RateLimitConfig{
-			Free: RateLimitPlan{
-				Requests: getEnvAsInt("RATE_LIMIT_FREE", 100),
-				Period:   getEnvAsDuration("RATE_LIMIT_FREE_PERIOD", 24*time.Hour),
-			},
-			Basic: RateLimitPlan{
-				Requests: getEnvAsInt("RATE_LIMIT_BASIC", 1000),
-				Period:   getEnvAsDuration("RATE_LIMIT_BASIC_PERIOD", 24*time.Hour),
-			},
-			Premium: RateLimitPlan{
-				Requests: getEnvAsInt("RATE_LIMIT_PREMIUM", 10000),
-				Period:   getEnvAsDuration("RATE_LIMIT_PREMIUM_PERIOD", 24*time.Hour),
-			},
+			Free:    RateLimitPlan{Requests: 100, Period: 24 * time.Hour},
+			Basic:   RateLimitPlan{Requests: 1000, Period: 24 * time.Hour},
+			Premium: RateLimitPlan{Requests: 10000, Period: 24 * time.Hour},
 		},
 		Cache: CacheConfig{
-			DefaultTTL:      getEnvAsDuration("CACHE_TTL", 30*time.Minute),
-			CleanupInterval: getEnvAsDuration("CACHE_CLEANUP", 1*time.Hour),
+			DefaultTTL:      30 * time.Minute,
+			CleanupInterval: 1 * time.Hour,
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
-			Output: getEnv("LOG_OUTPUT", "stdout"),
+			Level:  "info",
+			Format: "json",
+			Output: "stdout",
+
+			MaxSizeMB:  100,
+			MaxAge:     7 * 24 * time.Hour,
+			MaxBackups: 7,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "currency-converter-api",
+			SampleRatio: 1.0,
+		},
+		Quota: QuotaConfig{
+			BillingDay:         1,
+			ConserveThreshold:  0.8,
+			StaleOnlyThreshold: 0.95,
+			ExtendedTTL:        6 * time.Hour,
+		},
+		Pricing: PricingConfig{
+			Rounding: "none",
+		},
+		Quotes: QuotesConfig{
+			TTL: 30 * time.Second,
+		},
+		Refresher: RefresherConfig{
+			Interval: 5 * time.Minute,
+		},
+		GRPC: GRPCConfig{
+			Enabled: true,
+			Port:    "9090",
+		},
+		Stream: StreamConfig{
+			Keepalive: 15 * time.Second,
+		},
+		Alerts: AlertsConfig{
+			WebhookTimeout: 5 * time.Second,
+			MaxAttempts:    5,
+			RetryBaseDelay: time.Second,
+		},
+		Overrides: OverridesConfig{
+			RefreshInterval: 30 * time.Second,
+		},
+		Health: HealthConfig{
+			CheckTimeout:   2 * time.Second,
+			UpstreamMaxAge: 1 * time.Hour,
 		},
 	}
 }
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field - одна настройка конфигурации: ключ в файле/флаге, переменная окружения
+// и способ прочитать/записать значение в Config
+type field struct {
+	key    string // путь в YAML/TOML и имя флага, например "server.port"
+	env    string
+	secret bool
+	set    func(c *Config, raw string) error
+	get    func(c *Config) any
+}
+
+func bind[T any](key, env string, ptr func(c *Config) *T, parse func(raw string) (T, error)) field {
+	return field{
+		key: key,
+		env: env,
+		set: func(c *Config, raw string) error {
+			value, err := parse(raw)
+			if err != nil {
+				return err
+			}
+			*ptr(c) = value
+			return nil
+		},
+		get: func(c *Config) any { return *ptr(c) },
+	}
+}
+
+func stringField(key, env string, ptr func(c *Config) *string) field {
+	return bind(key, env, ptr, func(raw string) (string, error) { return raw, nil })
+}
+
+func secretField(key, env string, ptr func(c *Config) *Secret) field {
+	f := bind(key, env, ptr, func(raw string) (Secret, error) { return Secret(raw), nil })
+	f.secret = true
+	return f
+}
+
+func intField(key, env string, ptr func(c *Config) *int) field {
+	return bind(key, env, ptr, func(raw string) (int, error) {
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", raw)
+		}
+		return value, nil
+	})
+}
+
+func floatField(key, env string, ptr func(c *Config) *float64) field {
+	return bind(key, env, ptr, func(raw string) (float64, error) {
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", raw)
+		}
+		return value, nil
+	})
+}
+
+func boolField(key, env string, ptr func(c *Config) *bool) field {
+	return bind(key, env, ptr, func(raw string) (bool, error) {
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean %q", raw)
+		}
+		return value, nil
+	})
+}
+
+func durationField(key, env string, ptr func(c *Config) *time.Duration) field {
+	return bind(key, env, ptr, func(raw string) (time.Duration, error) {
+		value, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q (expected e.g. 500ms, 10s, 24h)", raw)
+		}
+		return value, nil
+	})
+}
+
+func listField(key, env string, ptr func(c *Config) *[]string) field {
+	return bind(key, env, ptr, func(raw string) ([]string, error) {
+		var values []string
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		return values, nil
+	})
+}
+
+// fields - полный список настроек. Порядок определяет порядок флагов в --help.
+var fields = []field{
+	stringField("server.port", "PORT", func(c *Config) *string { return &c.Server.Port }),
+	stringField("server.host", "HOST", func(c *Config) *string { return &c.Server.Host }),
+	stringField("server.mode", "GIN_MODE", func(c *Config) *string { return &c.Server.Mode }),
+	durationField("server.read_timeout", "READ_TIMEOUT", func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationField("server.read_header_timeout", "READ_HEADER_TIMEOUT", func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationField("server.write_timeout", "WRITE_TIMEOUT", func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationField("server.idle_timeout", "IDLE_TIMEOUT", func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	durationField("server.shutdown_timeout", "SHUTDOWN_TIMEOUT", func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+
+	stringField("redis.mode", "REDIS_MODE", func(c *Config) *string { return &c.Redis.Mode }),
+	stringField("redis.addr", "REDIS_ADDR", func(c *Config) *string { return &c.Redis.Addr }),
+	listField("redis.addrs", "REDIS_ADDRS", func(c *Config) *[]string { return &c.Redis.Addrs }),
+	stringField("redis.master_name", "REDIS_MASTER_NAME", func(c *Config) *string { return &c.Redis.MasterName }),
+	stringField("redis.username", "REDIS_USERNAME", func(c *Config) *string { return &c.Redis.Username }),
+	secretField("redis.password", "REDIS_PASSWORD", func(c *Config) *Secret { return &c.Redis.Password }),
+	intField("redis.db", "REDIS_DB", func(c *Config) *int { return &c.Redis.DB }),
+	stringField("redis.sentinel_username", "REDIS_SENTINEL_USERNAME", func(c *Config) *string { return &c.Redis.SentinelUsername }),
+	secretField("redis.sentinel_password", "REDIS_SENTINEL_PASSWORD", func(c *Config) *Secret { return &c.Redis.SentinelPassword }),
+	intField("redis.pool_size", "REDIS_POOL_SIZE", func(c *Config) *int { return &c.Redis.PoolSize }),
+	intField("redis.min_idle_conns", "REDIS_MIN_IDLE_CONNS", func(c *Config) *int { return &c.Redis.MinIdleConns }),
+	durationField("redis.dial_timeout", "REDIS_DIAL_TIMEOUT", func(c *Config) *time.Duration { return &c.Redis.DialTimeout }),
+	durationField("redis.read_timeout", "REDIS_READ_TIMEOUT", func(c *Config) *time.Duration { return &c.Redis.ReadTimeout }),
+	durationField("redis.write_timeout", "REDIS_WRITE_TIMEOUT", func(c *Config) *time.Duration { return &c.Redis.WriteTimeout }),
+	durationField("redis.reconnect_min_backoff", "REDIS_RECONNECT_MIN_BACKOFF", func(c *Config) *time.Duration { return &c.Redis.ReconnectMinBackoff }),
+	durationField("redis.reconnect_max_backoff", "REDIS_RECONNECT_MAX_BACKOFF", func(c *Config) *time.Duration { return &c.Redis.ReconnectMaxBackoff }),
+	boolField("redis.tls.enabled", "REDIS_TLS_ENABLED", func(c *Config) *bool { return &c.Redis.TLS.Enabled }),
+	stringField("redis.tls.ca_file", "REDIS_TLS_CA_FILE", func(c *Config) *string { return &c.Redis.TLS.CAFile }),
+	stringField("redis.tls.cert_file", "REDIS_TLS_CERT_FILE", func(c *Config) *string { return &c.Redis.TLS.CertFile }),
+	stringField("redis.tls.key_file", "REDIS_TLS_KEY_FILE", func(c *Config) *string { return &c.Redis.TLS.KeyFile }),
+	stringField("redis.tls.server_name", "REDIS_TLS_SERVER_NAME", func(c *Config) *string { return &c.Redis.TLS.ServerName }),
+	boolField("redis.tls.insecure_skip_verify", "REDIS_TLS_INSECURE_SKIP_VERIFY", func(c *Config) *bool { return &c.Redis.TLS.InsecureSkipVerify }),
+	durationField("redis.ttl", "REDIS_TTL", func(c *Config) *time.Duration { return &c.Redis.TTL }),
+	durationField("redis.stale_ttl", "REDIS_STALE_TTL", func(c *Config) *time.Duration { return &c.Redis.StaleTTL }),
+
+	secretField("database.dsn", "DATABASE_URL", func(c *Config) *Secret { return &c.Database.DSN }),
+
+	secretField("api.key", "CURRENCY_KEY_API", func(c *Config) *Secret { return &c.API.CurrencyKeyAPI }),
+	stringField("api.url", "CURRENCY_API_URL", func(c *Config) *string { return &c.API.CurrencyAPIURL }),
+	durationField("api.timeout", "API_TIMEOUT", func(c *Config) *time.Duration { return &c.API.Timeout }),
+	listField("api.providers", "API_PROVIDERS", func(c *Config) *[]string { return &c.API.Providers }),
+	intField("api.max_retries", "API_MAX_RETRIES", func(c *Config) *int { return &c.API.MaxRetries }),
+	durationField("api.retry_base_delay", "API_RETRY_BASE_DELAY", func(c *Config) *time.Duration { return &c.API.RetryBaseDelay }),
+	durationField("api.retry_max_delay", "API_RETRY_MAX_DELAY", func(c *Config) *time.Duration { return &c.API.RetryMaxDelay }),
+	durationField("api.max_retry_after", "API_MAX_RETRY_AFTER", func(c *Config) *time.Duration { return &c.API.MaxRetryAfter }),
+	intField("api.breaker_failure_threshold", "API_BREAKER_FAILURE_THRESHOLD", func(c *Config) *int { return &c.API.BreakerFailureThreshold }),
+	durationField("api.breaker_open_timeout", "API_BREAKER_OPEN_TIMEOUT", func(c *Config) *time.Duration { return &c.API.BreakerOpenTimeout }),
+	intField("api.breaker_half_open_requests", "API_BREAKER_HALF_OPEN_REQUESTS", func(c *Config) *int { return &c.API.BreakerHalfOpenRequests }),
+
+	secretField("jwt.secret", "JWT_SECRET", func(c *Config) *Secret { return &c.JWT.JWTSecret }),
+	durationField("jwt.expiration", "JWT_EXPIRATION", func(c *Config) *time.Duration { return &c.JWT.Expiration }),
+
+	intField("rate_limit.free.requests", "RATE_LIMIT_FREE", func(c *Config) *int { return &c.RateLimit.Free.Requests }),
+	durationField("rate_limit.free.period", "RATE_LIMIT_FREE_PERIOD", func(c *Config) *time.Duration { return &c.RateLimit.Free.Period }),
+	intField("rate_limit.basic.requests", "RATE_LIMIT_BASIC", func(c *Config) *int { return &c.RateLimit.Basic.Requests }),
+	durationField("rate_limit.basic.period", "RATE_LIMIT_BASIC_PERIOD", func(c *Config) *time.Duration { return &c.RateLimit.Basic.Period }),
+	intField("rate_limit.premium.requests", "RATE_LIMIT_PREMIUM", func(c *Config) *int { return &c.RateLimit.Premium.Requests }),
+	durationField("rate_limit.premium.period", "RATE_LIMIT_PREMIUM_PERIOD", func(c *Config) *time.Duration { return &c.RateLimit.Premium.Period }),
+
+	durationField("cache.default_ttl", "CACHE_TTL", func(c *Config) *time.Duration { return &c.Cache.DefaultTTL }),
+	durationField("cache.cleanup_interval", "CACHE_CLEANUP", func(c *Config) *time.Duration { return &c.Cache.CleanupInterval }),
+
+	stringField("logging.level", "LOG_LEVEL", func(c *Config) *string { return &c.Logging.Level }),
+	stringField("logging.format", "LOG_FORMAT", func(c *Config) *string { return &c.Logging.Format }),
+	stringField("logging.output", "LOG_OUTPUT", func(c *Config) *string { return &c.Logging.Output }),
+	intField("logging.max_size_mb", "LOG_MAX_SIZE_MB", func(c *Config) *int { return &c.Logging.MaxSizeMB }),
+	durationField("logging.max_age", "LOG_MAX_AGE", func(c *Config) *time.Duration { return &c.Logging.MaxAge }),
+	intField("logging.max_backups", "LOG_MAX_BACKUPS", func(c *Config) *int { return &c.Logging.MaxBackups }),
+	boolField("logging.compress", "LOG_COMPRESS", func(c *Config) *bool { return &c.Logging.Compress }),
+
+	boolField("tracing.enabled", "TRACING_ENABLED", func(c *Config) *bool { return &c.Tracing.Enabled }),
+	stringField("tracing.exporter", "TRACING_EXPORTER", func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringField("tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", func(c *Config) *string { return &c.Tracing.Endpoint }),
+	boolField("tracing.insecure", "OTEL_EXPORTER_OTLP_INSECURE", func(c *Config) *bool { return &c.Tracing.Insecure }),
+	stringField("tracing.service_name", "OTEL_SERVICE_NAME", func(c *Config) *string { return &c.Tracing.ServiceName }),
+	floatField("tracing.sample_ratio", "TRACING_SAMPLE_RATIO", func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+
+	intField("quota.budget", "UPSTREAM_QUOTA_BUDGET", func(c *Config) *int { return &c.Quota.Budget }),
+	intField("quota.billing_day", "UPSTREAM_QUOTA_BILLING_DAY", func(c *Config) *int { return &c.Quota.BillingDay }),
+	floatField("quota.conserve_threshold", "UPSTREAM_QUOTA_CONSERVE_THRESHOLD", func(c *Config) *float64 { return &c.Quota.ConserveThreshold }),
+	floatField("quota.stale_only_threshold", "UPSTREAM_QUOTA_STALE_ONLY_THRESHOLD", func(c *Config) *float64 { return &c.Quota.StaleOnlyThreshold }),
+	durationField("quota.extended_ttl", "UPSTREAM_QUOTA_EXTENDED_TTL", func(c *Config) *time.Duration { return &c.Quota.ExtendedTTL }),
+
+	stringField("fixtures.path", "FIXTURES_PATH", func(c *Config) *string { return &c.Fixtures.Path }),
+	boolField("fixtures.record", "FIXTURES_RECORD", func(c *Config) *bool { return &c.Fixtures.Record }),
+
+	durationField("overrides.refresh_interval", "OVERRIDES_REFRESH_INTERVAL", func(c *Config) *time.Duration { return &c.Overrides.RefreshInterval }),
+
+	durationField("quotes.ttl", "QUOTE_TTL", func(c *Config) *time.Duration { return &c.Quotes.TTL }),
+
+	durationField("refresher.interval", "RATES_REFRESH_INTERVAL", func(c *Config) *time.Duration { return &c.Refresher.Interval }),
+	listField("refresher.pairs", "RATES_REFRESH_PAIRS", func(c *Config) *[]string { return &c.Refresher.Pairs }),
+
+	boolField("grpc.enabled", "GRPC_ENABLED", func(c *Config) *bool { return &c.GRPC.Enabled }),
+	stringField("grpc.port", "GRPC_PORT", func(c *Config) *string { return &c.GRPC.Port }),
+
+	durationField("stream.keepalive", "STREAM_KEEPALIVE", func(c *Config) *time.Duration { return &c.Stream.Keepalive }),
+
+	durationField("alerts.webhook_timeout", "ALERT_WEBHOOK_TIMEOUT", func(c *Config) *time.Duration { return &c.Alerts.WebhookTimeout }),
+	intField("alerts.max_attempts", "ALERT_WEBHOOK_MAX_ATTEMPTS", func(c *Config) *int { return &c.Alerts.MaxAttempts }),
+	durationField("alerts.retry_base_delay", "ALERT_WEBHOOK_RETRY_BASE_DELAY", func(c *Config) *time.Duration { return &c.Alerts.RetryBaseDelay }),
+	boolField("alerts.allow_private_webhooks", "ALERT_ALLOW_PRIVATE_WEBHOOKS", func(c *Config) *bool { return &c.Alerts.AllowPrivateWebhooks }),
+
+	secretField("auth.api_keys", "API_KEYS", func(c *Config) *Secret { return &c.Auth.APIKeys }),
+	boolField("auth.required", "API_KEYS_REQUIRED", func(c *Config) *bool { return &c.Auth.Required }),
+
+	stringField("pricing.file", "PRICING_FILE", func(c *Config) *string { return &c.Pricing.File }),
+	floatField("pricing.spread_pct", "PRICING_SPREAD_PCT", func(c *Config) *float64 { return &c.Pricing.SpreadPct }),
+	floatField("pricing.fee_pct", "PRICING_FEE_PCT", func(c *Config) *float64 { return &c.Pricing.FeePct }),
+	floatField("pricing.fee_fixed", "PRICING_FEE_FIXED", func(c *Config) *float64 { return &c.Pricing.FeeFixed }),
+	stringField("pricing.rounding", "PRICING_ROUNDING", func(c *Config) *string { return &c.Pricing.Rounding }),
+
+	secretField("admin.token", "ADMIN_TOKEN", func(c *Config) *Secret { return &c.Admin.Token }),
+
+	durationField("health.check_timeout", "HEALTH_CHECK_TIMEOUT", func(c *Config) *time.Duration { return &c.Health.CheckTimeout }),
+	durationField("health.upstream_max_age", "HEALTH_UPSTREAM_MAX_AGE", func(c *Config) *time.Duration { return &c.Health.UpstreamMaxAge }),
+}
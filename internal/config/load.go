@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options - параметры запуска, которые не являются частью конфигурации сервиса
+type Options struct {
+	ConfigFile  string // --config или CONFIG_FILE
+	PrintConfig bool   // --print-config
+}
+
+// Load собирает конфигурацию слоями: значения по умолчанию, файл (YAML или TOML),
+// переменные окружения (и .env), флаги командной строки. Каждый следующий слой
+// переопределяет предыдущий.
+//
+// Ошибки разбора и валидации не прерывают загрузку, а собираются вместе.
+// При ошибке Config все равно возвращается, чтобы --print-config мог его показать.
+func Load(args []string) (*Config, Options, error) {
+	var opts Options
+
+	// .env - только источник переменных окружения; отсутствие файла не ошибка
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, opts, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	flags := flag.NewFlagSet("currency-converter", flag.ContinueOnError)
+	flags.StringVar(&opts.ConfigFile, "config", os.Getenv("CONFIG_FILE"), "path to YAML or TOML config file (env CONFIG_FILE)")
+	flags.BoolVar(&opts.PrintConfig, "print-config", false, "print effective config with secrets redacted and exit")
+	values := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		values[f.key] = flags.String(f.key, "", "env "+f.env)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, opts, err
+	}
+
+	cfg := Default()
+	var errs []error
+
+	if opts.ConfigFile != "" {
+		if err := applyFile(cfg, opts.ConfigFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, f := range fields {
+		if err := applyEnv(cfg, f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	flags.Visit(func(fl *flag.Flag) {
+		raw, ok := values[fl.Name]
+		if !ok {
+			return
+		}
+		if err := byKey[fl.Name].set(cfg, *raw); err != nil {
+			errs = append(errs, fmt.Errorf("flag --%s: %w", fl.Name, err))
+		}
+	})
+
+	// Валидацию имеет смысл запускать только по разобранным значениям
+	if len(errs) == 0 {
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return cfg, opts, errors.Join(errs...)
+}
+
+var byKey = func() map[string]field {
+	m := make(map[string]field, len(fields))
+	for _, f := range fields {
+		m[f.key] = f
+	}
+	return m
+}()
+
+func applyEnv(cfg *Config, f field) error {
+	var (
+		raw string
+		ok  bool
+		err error
+	)
+	if f.secret {
+		raw, ok, err = lookupSecret(f.env)
+		if err != nil {
+			return fmt.Errorf("env %s: %w", f.env, err)
+		}
+	} else {
+		raw, ok = os.LookupEnv(f.env)
+	}
+	if !ok {
+		return nil
+	}
+	if err := f.set(cfg, raw); err != nil {
+		return fmt.Errorf("env %s: %w", f.env, err)
+	}
+	return nil
+}
+
+// applyFile читает YAML (.yaml, .yml) или TOML (.toml) файл.
+// Неизвестные ключи - ошибка: опечатка в имени не должна молча игнорироваться.
+func applyFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	tree := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q (use .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	flat := map[string]string{}
+	var errs []error
+	flatten("", tree, flat, &errs)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %q", path, key))
+			continue
+		}
+		if err := f.set(cfg, flat[key]); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flatten превращает вложенные секции в плоские ключи вида "redis.tls.enabled"
+func flatten(prefix string, node map[string]any, out map[string]string, errs *[]error) {
+	for name, value := range node {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, out, errs)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			// пустое значение в YAML - оставляем предыдущий слой
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
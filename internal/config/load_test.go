@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, opts, err := Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), cfg)
+	assert.False(t, opts.PrintConfig)
+}
+
+func TestLoad_LayerPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: "9000"
+  read_timeout: 15s
+redis:
+  addrs: [a:26379, b:26379]
+  tls:
+    enabled: true
+logging:
+  level: debug
+`)
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("READ_TIMEOUT", "20s")
+
+	cfg, opts, err := Load([]string{"--config", path, "--server.read_timeout=25s"})
+	require.NoError(t, err)
+
+	assert.Equal(t, path, opts.ConfigFile)
+	assert.Equal(t, "9000", cfg.Server.Port, "файл переопределяет default")
+	assert.Equal(t, "warn", cfg.Logging.Level, "env переопределяет файл")
+	assert.Equal(t, 25*time.Second, cfg.Server.ReadTimeout, "флаг переопределяет env")
+	assert.Equal(t, []string{"a:26379", "b:26379"}, cfg.Redis.Addrs)
+	assert.True(t, cfg.Redis.TLS.Enabled)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[api]
+timeout = "3s"
+max_retries = 4
+
+[rate_limit.free]
+requests = 50
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, _, err := Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3*time.Second, cfg.API.Timeout)
+	assert.Equal(t, 4, cfg.API.MaxRetries)
+	assert.Equal(t, 50, cfg.RateLimit.Free.Requests)
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  read_timout: 5s
+`)
+	t.Setenv("API_TIMEOUT", "ten seconds")
+	t.Setenv("REDIS_DB", "zero")
+
+	_, _, err := Load([]string{"--config", path})
+	require.Error(t, err)
+
+	assert.ErrorContains(t, err, `unknown key "server.read_timout"`)
+	assert.ErrorContains(t, err, `env API_TIMEOUT: invalid duration "ten seconds"`)
+	assert.ErrorContains(t, err, `env REDIS_DB: invalid integer "zero"`)
+}
+
+func TestValidate_AggregatesErrors(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = ""
+	cfg.Logging.Level = "verbose"
+	cfg.Redis.TTL = 0
+	cfg.Quota.ConserveThreshold = 0.99
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	assert.ErrorContains(t, err, "server.port: must not be empty")
+	assert.ErrorContains(t, err, `logging.level: unknown value "verbose"`)
+	assert.ErrorContains(t, err, "redis.ttl: must be positive")
+	assert.ErrorContains(t, err, "quota.conserve_threshold")
+
+	assert.NoError(t, Default().Validate())
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	t.Setenv("CURRENCY_KEY_API", "4cd60470d61ac235ae2e1f77")
+
+	cfg, opts, err := Load([]string{"--print-config"})
+	require.NoError(t, err)
+	require.True(t, opts.PrintConfig)
+
+	var out bytes.Buffer
+	require.NoError(t, Print(&out, cfg))
+
+	assert.NotContains(t, out.String(), "4cd60470d61ac235ae2e1f77")
+	assert.NotContains(t, out.String(), defaultJWTSecret)
+	assert.Contains(t, out.String(), "key: '[REDACTED]'")
+	assert.Contains(t, out.String(), "read_timeout: 10s")
+
+	// Вывод --print-config можно подать обратно как --config
+	path := writeFile(t, "printed.yaml", out.String())
+	reloaded, _, err := Load([]string{"--config", path})
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Server, reloaded.Server)
+}
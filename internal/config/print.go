@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print выводит итоговую конфигурацию в YAML - в том же формате, что принимает --config.
+// Секреты печатаются как [REDACTED].
+func Print(w io.Writer, cfg *Config) error {
+	tree := map[string]any{}
+	for _, f := range fields {
+		path := strings.Split(f.key, ".")
+		node := tree
+		for _, part := range path[:len(path)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[part] = child
+			}
+			node = child
+		}
+
+		value := f.get(cfg)
+		switch v := value.(type) {
+		case Secret:
+			value = v.String()
+		case time.Duration:
+			value = v.String()
+		}
+		node[path[len(path)-1]] = value
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(tree); err != nil {
+		return fmt.Errorf("failed to render config: %w", err)
+	}
+	return enc.Close()
+}
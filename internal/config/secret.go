@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret - строка с секретом (ключ API, пароль, токен).
+// При печати через fmt, zap или JSON всегда выводится [REDACTED];
+// настоящее значение доступно только через Value().
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) IsSet() bool {
+	return s != ""
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString закрывает утечку через %#v
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// lookupSecret читает секрет из файла, указанного в KEY_FILE (Docker/Kubernetes secrets),
+// а если файла нет - из переменной KEY. ok=false, если не задано ни то, ни другое.
+func lookupSecret(key string) (value string, ok bool, err error) {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s_FILE: %w", key, err)
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	value, ok = os.LookupEnv(key)
+	return value, ok, nil
+}
+
+// Secrets возвращает все заданные значения секретов конфигурации,
+// чтобы логгер мог вычищать их из любых полей
+func (c *Config) Secrets() []string {
+	secrets := []Secret{
+		c.API.CurrencyKeyAPI,
+		c.JWT.JWTSecret,
+		c.Redis.Password,
+		c.Redis.SentinelPassword,
+		c.Admin.Token,
+		c.Auth.APIKeys,
+		c.Database.DSN,
+	}
+
+	// Каждый API ключ клиента по отдельности, а не только весь список целиком
+	if keys, err := c.Auth.ParseAPIKeys(); err == nil {
+		for _, key := range keys {
+			secrets = append(secrets, Secret(key.Key))
+		}
+	}
+
+	// Пароль из DSN тоже может всплыть в ошибках драйвера отдельно от строки подключения
+	if dsn, err := url.Parse(c.Database.DSN.Value()); err == nil && dsn.User != nil {
+		if password, ok := dsn.User.Password(); ok {
+			secrets = append(secrets, Secret(password))
+		}
+	}
+
+	values := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret.IsSet() {
+			values = append(values, secret.Value())
+		}
+	}
+	return values
+}
+
+// ValidateSecrets проверяет, что в release режиме заданы обязательные секреты
+// и не используются значения-заглушки
+func (c *Config) ValidateSecrets() error {
+	if c.Server.Mode != "release" {
+		return nil
+	}
+
+	var missing []string
+	if !c.API.CurrencyKeyAPI.IsSet() {
+		missing = append(missing, "CURRENCY_KEY_API")
+	}
+	if !c.JWT.JWTSecret.IsSet() || c.JWT.JWTSecret == defaultJWTSecret {
+		missing = append(missing, "JWT_SECRET")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required secrets are not set in release mode: %s (use env vars or *_FILE)",
+			strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	positive := func(key string, d time.Duration) {
+		if d <= 0 {
+			fail(key, "must be positive, got %s", d)
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		fail(key, "unknown value %q (allowed: %v)", value, allowed)
+	}
+	ratio := func(key string, v float64) {
+		if v < 0 || v > 1 {
+			fail(key, "must be between 0 and 1, got %v", v)
+		}
+	}
+
+	// Server
+	if c.Server.Port == "" {
+		fail("server.port", "must not be empty")
+	} else if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		fail("server.port", "invalid port %q", c.Server.Port)
+	}
+	oneOf("server.mode", c.Server.Mode, "debug", "release", "test")
+	positive("server.read_timeout", c.Server.ReadTimeout)
+	positive("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	positive("server.write_timeout", c.Server.WriteTimeout)
+	positive("server.idle_timeout", c.Server.IdleTimeout)
+	positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+
+	// Redis
+	oneOf("redis.mode", c.Redis.Mode, "standalone", "sentinel", "cluster")
+	positive("redis.ttl", c.Redis.TTL)
+	if c.Redis.StaleTTL < 0 {
+		fail("redis.stale_ttl", "must not be negative")
+	}
+	if c.Redis.ReconnectMinBackoff <= 0 || c.Redis.ReconnectMaxBackoff < c.Redis.ReconnectMinBackoff {
+		fail("redis.reconnect_min_backoff", "must be positive and not exceed redis.reconnect_max_backoff")
+	}
+
+	// API
+	if u, err := url.Parse(c.API.CurrencyAPIURL); err != nil || u.Scheme == "" || u.Host == "" {
+		fail("api.url", "invalid URL %q", c.API.CurrencyAPIURL)
+	}
+	positive("api.timeout", c.API.Timeout)
+	if len(c.API.Providers) == 0 {
+		fail("api.providers", "must list at least one provider")
+	}
+	seen := make(map[string]bool, len(c.API.Providers))
+	for _, name := range c.API.Providers {
+		if seen[name] {
+			fail("api.providers", "duplicate provider %q", name)
+		}
+		seen[name] = true
+	}
+	if (seen["fixture"] || c.Fixtures.Record) && c.Fixtures.Path == "" {
+		fail("fixtures.path", "required for the fixture provider and record mode")
+	}
+	if c.API.MaxRetries < 0 {
+		fail("api.max_retries", "must not be negative")
+	}
+	if c.API.BreakerFailureThreshold < 1 {
+		fail("api.breaker_failure_threshold", "must be at least 1")
+	}
+	if c.API.BreakerHalfOpenRequests < 1 {
+		fail("api.breaker_half_open_requests", "must be at least 1")
+	}
+
+	// Rate limit
+	for _, plan := range []struct {
+		name string
+		RateLimitPlan
+	}{
+		{"free", c.RateLimit.Free},
+		{"basic", c.RateLimit.Basic},
+		{"premium", c.RateLimit.Premium},
+	} {
+		if plan.Requests < 1 {
+			fail("rate_limit."+plan.name+".requests", "must be at least 1")
+		}
+		positive("rate_limit."+plan.name+".period", plan.Period)
+	}
+
+	// Logging
+	oneOf("logging.level", c.Logging.Level, "debug", "info", "warn", "error")
+	oneOf("logging.format", c.Logging.Format, "json", "console", "text")
+	if c.Logging.Output == "" {
+		fail("logging.output", "must not be empty")
+	}
+	if c.Logging.MaxSizeMB < 1 {
+		fail("logging.max_size_mb", "must be at least 1")
+	}
+	if c.Logging.MaxAge < 0 {
+		fail("logging.max_age", "must not be negative")
+	}
+	if c.Logging.MaxBackups < 0 {
+		fail("logging.max_backups", "must not be negative")
+	}
+
+	// Tracing
+	if c.Tracing.Enabled {
+		oneOf("tracing.exporter", c.Tracing.Exporter, "otlp", "stdout")
+	}
+	ratio("tracing.sample_ratio", c.Tracing.SampleRatio)
+
+	// Quota
+	if c.Quota.Budget < 0 {
+		fail("quota.budget", "must not be negative")
+	}
+	if c.Quota.BillingDay < 1 || c.Quota.BillingDay > 28 {
+		fail("quota.billing_day", "must be between 1 and 28, got %d", c.Quota.BillingDay)
+	}
+	ratio("quota.conserve_threshold", c.Quota.ConserveThreshold)
+	ratio("quota.stale_only_threshold", c.Quota.StaleOnlyThreshold)
+	if c.Quota.ConserveThreshold > c.Quota.StaleOnlyThreshold {
+		fail("quota.conserve_threshold", "must not exceed quota.stale_only_threshold")
+	}
+
+	positive("overrides.refresh_interval", c.Overrides.RefreshInterval)
+	positive("quotes.ttl", c.Quotes.TTL)
+
+	// Refresher и алерты
+	positive("refresher.interval", c.Refresher.Interval)
+	for _, pair := range c.Refresher.Pairs {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || len(from) != 3 || len(to) != 3 {
+			fail("refresher.pairs", "invalid pair %q, expected FROM/TO", pair)
+		}
+	}
+	if c.GRPC.Enabled {
+		if port, err := strconv.Atoi(c.GRPC.Port); err != nil || port < 1 || port > 65535 {
+			fail("grpc.port", "invalid port %q", c.GRPC.Port)
+		} else if c.GRPC.Port == c.Server.Port {
+			fail("grpc.port", "must differ from server.port")
+		}
+	}
+	positive("stream.keepalive", c.Stream.Keepalive)
+	positive("alerts.webhook_timeout", c.Alerts.WebhookTimeout)
+	if c.Alerts.MaxAttempts < 1 || c.Alerts.MaxAttempts > 10 {
+		fail("alerts.max_attempts", "must be between 1 and 10, got %d", c.Alerts.MaxAttempts)
+	}
+	positive("alerts.retry_base_delay", c.Alerts.RetryBaseDelay)
+
+	// Auth
+	keys, err := c.Auth.ParseAPIKeys()
+	if err != nil {
+		fail("auth.api_keys", "%v", err)
+	}
+	if c.Auth.Required && err == nil && len(keys) == 0 {
+		fail("auth.api_keys", "must not be empty when auth.required is set")
+	}
+
+	// Pricing
+	if c.Pricing.SpreadPct < 0 || c.Pricing.SpreadPct >= 100 {
+		fail("pricing.spread_pct", "must be in [0, 100), got %v", c.Pricing.SpreadPct)
+	}
+	if c.Pricing.FeePct < 0 || c.Pricing.FeePct >= 100 {
+		fail("pricing.fee_pct", "must be in [0, 100), got %v", c.Pricing.FeePct)
+	}
+	if c.Pricing.FeeFixed < 0 {
+		fail("pricing.fee_fixed", "must not be negative")
+	}
+	switch c.Pricing.Rounding {
+	case "none", "half_up", "half_even", "down":
+	default:
+		fail("pricing.rounding", "must be one of none, half_up, half_even, down, got %q", c.Pricing.Rounding)
+	}
+
+	// Health
+	positive("health.check_timeout", c.Health.CheckTimeout)
+
+	if err := c.ValidateSecrets(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
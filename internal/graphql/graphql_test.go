@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// stubService отдает курсы из таблиц по базам и считает обращения к таблицам и парам
+type stubService struct {
+	mu     sync.Mutex
+	tables map[string]int
+	calls  map[string]int
+	rates  map[string]map[string]float64
+}
+
+func newStubService() *stubService {
+	return &stubService{
+		tables: make(map[string]int),
+		calls:  make(map[string]int),
+		rates:  map[string]map[string]float64{"USD": {"EUR": 0.92, "GBP": 0.79}},
+	}
+}
+
+func (s *stubService) GetRateTable(_ context.Context, base string) (service.RateTable, error) {
+	s.mu.Lock()
+	s.tables[base]++
+	s.mu.Unlock()
+	return service.RateTable{Base: base, Rates: s.rates[base], Provenance: service.Provenance{
+		Source: service.RateSourceCache, Provider: "fixture", Timestamp: time.Now(), CacheAge: 30 * time.Second,
+	}}, nil
+}
+
+func (s *stubService) TableRate(_ context.Context, from, to string, loadTable service.TableLoader) (service.Rate, error) {
+	s.mu.Lock()
+	s.calls[from+"/"+to]++
+	s.mu.Unlock()
+	table, err := loadTable()
+	if err != nil {
+		return service.Rate{}, err
+	}
+	value, ok := table.Rates[to]
+	if !ok {
+		return service.Rate{}, apperror.UnsupportedCurrency(to)
+	}
+	return service.Rate{Value: value, Provenance: table.Provenance}, nil
+}
+
+func (s *stubService) RateAt(_ context.Context, from, to string, t time.Time) (service.Rate, error) {
+	if t.Before(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		return service.Rate{}, apperror.NotFound("no rate")
+	}
+	return service.Rate{Value: 0.9, Provenance: service.Provenance{Source: service.RateSourceHistory, Timestamp: t}}, nil
+}
+
+func (s *stubService) ConvertRate(_ context.Context, from, to string, amount float64, rate service.Rate) (*service.ConversionResult, error) {
+	return &service.ConversionResult{
+		From: from, To: to, Amount: amount, Rate: rate.Value, MidRate: rate.Value,
+		Result: amount * rate.Value, RawResult: amount * rate.Value, Rounding: "none", Provenance: rate.Provenance,
+	}, nil
+}
+
+func (s *stubService) History(_ context.Context, from, to string, _, _ time.Time, _ int) ([]history.Point, error) {
+	return []history.Point{{From: from, To: to, Rate: 0.91, Provider: "fixture", At: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}}, nil
+}
+
+func exec(t *testing.T, svc Service, query string) (map[string]any, []map[string]any) {
+	t.Helper()
+	resp := New(svc, zap.NewNop()).Exec(context.Background(), query, "", nil)
+	raw, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var out struct {
+		Data   map[string]any   `json:"data"`
+		Errors []map[string]any `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &out))
+	return out.Data, out.Errors
+}
+
+func TestRatesAndConvert_ShareLookups(t *testing.T) {
+	svc := newStubService()
+	data, errs := exec(t, svc, `{
+		rates(base: "usd", symbols: ["EUR", "GBP", "EUR"]) { symbol value source cacheAgeSeconds }
+		convert(from: "USD", to: "EUR", amount: 100) { result rate source }
+		currencies { code minorUnits }
+	}`)
+	require.Empty(t, errs)
+
+	rates := data["rates"].([]any)
+	require.Len(t, rates, 3)
+	assert.Equal(t, "EUR", rates[0].(map[string]any)["symbol"])
+	assert.Equal(t, 0.79, rates[1].(map[string]any)["value"])
+	assert.Equal(t, 30.0, rates[0].(map[string]any)["cacheAgeSeconds"])
+	assert.InDelta(t, 92.0, data["convert"].(map[string]any)["result"], 1e-9)
+	assert.NotEmpty(t, data["currencies"])
+
+	// USD/EUR нужен трижды, но у сервиса запрошен один раз
+	assert.Equal(t, 1, svc.calls["USD/EUR"])
+	assert.Equal(t, 1, svc.calls["USD/GBP"])
+	assert.Equal(t, 1, svc.tables["USD"], "одна таблица на базу")
+}
+
+func TestRates_OneUpstreamCallPerBase(t *testing.T) {
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		base := strings.TrimPrefix(r.URL.Path[strings.LastIndex(r.URL.Path, "/"):], "/")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"result":"success","base_code":%q,"conversion_rates":{"EUR":0.9,"GBP":0.8,"JPY":150,"CHF":0.88,"USD":1.1}}`, base)
+	}))
+	defer upstream.Close()
+
+	cfg := config.Default()
+	cfg.API.CurrencyAPIURL = upstream.URL
+	logger := zap.NewNop()
+	svc := service.NewCurrencyService(cfg, nil, quota.NewTracker(cfg.Quota, nil, logger), logger)
+
+	data, errs := exec(t, svc, `{
+		usd: rates(base: "USD", symbols: ["EUR", "GBP", "JPY", "CHF", "XXX"]) { symbol value error { code } }
+		eur: rates(base: "EUR", symbols: ["USD", "GBP"]) { value }
+		convert(from: "USD", to: "GBP", amount: 10) { result }
+	}`)
+	require.Empty(t, errs)
+	assert.Len(t, data["usd"].([]any), 5)
+	assert.InDelta(t, 8.0, data["convert"].(map[string]any)["result"], 1e-9)
+	assert.Equal(t, int32(2), requests.Load(), "по одному запросу к провайдеру на базу")
+}
+
+func TestRates_PerSymbolErrors(t *testing.T) {
+	data, errs := exec(t, newStubService(), `{
+		rates(base: "USD", symbols: ["EUR", "XXX"]) { symbol value error { code message } }
+	}`)
+	require.Empty(t, errs)
+
+	rates := data["rates"].([]any)
+	assert.Nil(t, rates[0].(map[string]any)["error"])
+	failed := rates[1].(map[string]any)
+	assert.Nil(t, failed["value"])
+	assert.Equal(t, "unsupported_currency", failed["error"].(map[string]any)["code"])
+}
+
+func TestRates_Date(t *testing.T) {
+	data, errs := exec(t, newStubService(), `{
+		rates(base: "USD", symbols: ["EUR"], date: "2026-03-01") { value source timestamp }
+	}`)
+	require.Empty(t, errs)
+	rate := data["rates"].([]any)[0].(map[string]any)
+	assert.Equal(t, service.RateSourceHistory, rate["source"])
+	assert.Equal(t, "2026-03-01T23:59:59.999999999Z", rate["timestamp"])
+
+	_, errs = exec(t, newStubService(), `{ rates(base: "USD", symbols: ["EUR"], date: "01.03.2026") { value } }`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "validation", errs[0]["extensions"].(map[string]any)["code"])
+}
+
+func TestConvert_ErrorCode(t *testing.T) {
+	data, errs := exec(t, newStubService(), `{ convert(from: "USD", to: "XXX", amount: 1) { result } }`)
+	assert.Nil(t, data)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "currency XXX is not supported", errs[0]["message"])
+	assert.Equal(t, "unsupported_currency", errs[0]["extensions"].(map[string]any)["code"])
+}
+
+func TestHistory(t *testing.T) {
+	data, errs := exec(t, newStubService(), `{
+		history(from: "USD", to: "EUR", since: "2026-01-01T00:00:00Z") { rate provider at }
+	}`)
+	require.Empty(t, errs)
+	points := data["history"].([]any)
+	require.Len(t, points, 1)
+	assert.Equal(t, "2026-03-01T00:00:00Z", points[0].(map[string]any)["at"])
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"currency-converter-v2/internal/service"
+)
+
+// rateKey - пара и дата курса; пустая дата - текущий курс
+type rateKey struct {
+	from string
+	to   string
+	date string
+}
+
+// rateCall - результат загрузки, общий для всех резолверов, запросивших ту же пару
+type rateCall struct {
+	done chan struct{}
+	rate service.Rate
+	err  error
+}
+
+// tableCall - таблица курсов базы, одна на запрос
+type tableCall struct {
+	once  sync.Once
+	table service.RateTable
+	err   error
+}
+
+// loader загружает курсы в рамках одного запроса: одинаковые пары
+// запрашиваются у сервиса один раз, текущие курсы одной базы - одной таблицей
+type loader struct {
+	svc Service
+
+	mu     sync.Mutex
+	calls  map[rateKey]*rateCall
+	tables map[string]*tableCall
+}
+
+func newLoader(svc Service) *loader {
+	return &loader{svc: svc, calls: make(map[rateKey]*rateCall), tables: make(map[string]*tableCall)}
+}
+
+type loaderKey struct{}
+
+func withLoader(ctx context.Context, l *loader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *loader {
+	return ctx.Value(loaderKey{}).(*loader)
+}
+
+// load возвращает курс пары на дату day (конец дня UTC) или текущий, если day нулевой
+func (l *loader) load(ctx context.Context, from, to string, day time.Time) (service.Rate, error) {
+	key := rateKey{from: strings.ToUpper(from), to: strings.ToUpper(to)}
+	if !day.IsZero() {
+		key.date = day.Format(time.DateOnly)
+	}
+
+	l.mu.Lock()
+	call, ok := l.calls[key]
+	if !ok {
+		call = &rateCall{done: make(chan struct{})}
+		l.calls[key] = call
+	}
+	l.mu.Unlock()
+
+	if !ok {
+		if day.IsZero() {
+			call.rate, call.err = l.svc.TableRate(ctx, key.from, key.to, l.table(ctx, key.from))
+		} else {
+			call.rate, call.err = l.svc.RateAt(ctx, key.from, key.to, day.AddDate(0, 0, 1).Add(-time.Nanosecond))
+		}
+		close(call.done)
+	}
+
+	select {
+	case <-call.done:
+		return call.rate, call.err
+	case <-ctx.Done():
+		return service.Rate{}, ctx.Err()
+	}
+}
+
+// table возвращает загрузчик таблицы base: первый вызов идет в сервис,
+// остальные резолверы запроса, в том числе параллельные, ждут его результат
+func (l *loader) table(ctx context.Context, base string) service.TableLoader {
+	l.mu.Lock()
+	call, ok := l.tables[base]
+	if !ok {
+		call = &tableCall{}
+		l.tables[base] = call
+	}
+	l.mu.Unlock()
+
+	return func() (service.RateTable, error) {
+		call.once.Do(func() {
+			call.table, call.err = l.svc.GetRateTable(ctx, base)
+		})
+		return call.table, call.err
+	}
+}
+
+// rateResult - курс или ошибка его загрузки
+type rateResult struct {
+	symbol string
+	rate   service.Rate
+	err    error
+}
+
+// loadMany загружает курсы base ко всем symbols параллельно, сохраняя порядок.
+// Текущие курсы берутся из одной таблицы base, так что параллельность не множит запросы.
+func (l *loader) loadMany(ctx context.Context, base string, symbols []string, day time.Time) []rateResult {
+	results := make([]rateResult, len(symbols))
+	var wg sync.WaitGroup
+	for i, symbol := range symbols {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rate, err := l.load(ctx, base, symbol, day)
+			results[i] = rateResult{symbol: strings.ToUpper(symbol), rate: rate, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,271 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/service"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+)
+
+// resolver - корневой резолвер Query
+type resolver struct {
+	svc    Service
+	logger *zap.Logger
+}
+
+func (r *resolver) Currencies() []*currencyResolver {
+	list := service.Currencies()
+	out := make([]*currencyResolver, len(list))
+	for i := range list {
+		out[i] = &currencyResolver{c: list[i]}
+	}
+	return out
+}
+
+type ratesArgs struct {
+	Base    string
+	Symbols []string
+	Date    *string
+}
+
+func (r *resolver) Rates(ctx context.Context, args ratesArgs) ([]*rateResolver, error) {
+	if len(args.Symbols) == 0 {
+		return nil, r.error(ctx, apperror.Validation("symbols must not be empty"))
+	}
+	if len(args.Symbols) > maxSymbols {
+		return nil, r.error(ctx, apperror.Validation(fmt.Sprintf("at most %d symbols per request", maxSymbols)))
+	}
+	var day time.Time
+	if args.Date != nil {
+		var err error
+		day, err = time.Parse(time.DateOnly, *args.Date)
+		if err != nil {
+			return nil, r.error(ctx, apperror.Validation("date must be in YYYY-MM-DD format"))
+		}
+	}
+
+	base := strings.ToUpper(args.Base)
+	results := loaderFrom(ctx).loadMany(ctx, base, args.Symbols, day)
+	out := make([]*rateResolver, len(results))
+	for i, res := range results {
+		out[i] = &rateResolver{base: base, symbol: res.symbol, rate: res.rate}
+		if res.err != nil {
+			out[i].err = r.logError(ctx, res.err)
+		}
+	}
+	return out, nil
+}
+
+type convertArgs struct {
+	From   string
+	To     string
+	Amount float64
+}
+
+func (r *resolver) Convert(ctx context.Context, args convertArgs) (*conversionResolver, error) {
+	from, to := strings.ToUpper(args.From), strings.ToUpper(args.To)
+	if args.Amount <= 0 {
+		return nil, r.error(ctx, apperror.Validation(fmt.Sprintf("amount must be positive, got: %.2f", args.Amount)))
+	}
+	rate, err := loaderFrom(ctx).load(ctx, from, to, time.Time{})
+	if err != nil {
+		return nil, r.error(ctx, err)
+	}
+	result, err := r.svc.ConvertRate(ctx, from, to, args.Amount, rate)
+	if err != nil {
+		return nil, r.error(ctx, err)
+	}
+	return &conversionResolver{r: result}, nil
+}
+
+type historyArgs struct {
+	From  string
+	To    string
+	Since *graphqlgo.Time
+	Until *graphqlgo.Time
+	Limit *int32
+}
+
+func (r *resolver) History(ctx context.Context, args historyArgs) ([]*historyPointResolver, error) {
+	var since, until time.Time
+	if args.Since != nil {
+		since = args.Since.Time
+	}
+	if args.Until != nil {
+		until = args.Until.Time
+	}
+	var limit int
+	if args.Limit != nil {
+		limit = int(*args.Limit)
+	}
+	points, err := r.svc.History(ctx, args.From, args.To, since, until, limit)
+	if err != nil {
+		return nil, r.error(ctx, err)
+	}
+	out := make([]*historyPointResolver, len(points))
+	for i, p := range points {
+		out[i] = &historyPointResolver{rate: p.Rate, provider: p.Provider, at: p.At}
+	}
+	return out, nil
+}
+
+// error приводит ошибку сервиса к ошибке GraphQL с кодом в extensions
+func (r *resolver) error(ctx context.Context, err error) error {
+	return &queryError{err: r.logError(ctx, err)}
+}
+
+// logError логирует внутренние ошибки: клиенту уходит только безопасное сообщение
+func (r *resolver) logError(ctx context.Context, err error) *apperror.Error {
+	appErr := apperror.From(err)
+	if appErr.Kind == apperror.KindInternal {
+		logging.FromContext(ctx, r.logger).Error("GraphQL resolver failed", zap.Error(err))
+	}
+	return appErr
+}
+
+// queryError - ошибка резолвера; graphql-go кладет Extensions() в ответ
+type queryError struct {
+	err *apperror.Error
+}
+
+func (e *queryError) Error() string {
+	return e.err.Message
+}
+
+func (e *queryError) Extensions() map[string]any {
+	return map[string]any{"code": string(e.err.Kind)}
+}
+
+type currencyResolver struct {
+	c service.Currency
+}
+
+func (r *currencyResolver) Code() string      { return r.c.Code }
+func (r *currencyResolver) Name() string      { return r.c.Name }
+func (r *currencyResolver) MinorUnits() int32 { return int32(r.c.MinorUnits) }
+
+type rateResolver struct {
+	base   string
+	symbol string
+	rate   service.Rate
+	err    *apperror.Error
+}
+
+func (r *rateResolver) Base() string   { return r.base }
+func (r *rateResolver) Symbol() string { return r.symbol }
+
+func (r *rateResolver) Value() *float64 {
+	if r.err != nil {
+		return nil
+	}
+	return &r.rate.Value
+}
+
+func (r *rateResolver) Source() *string {
+	if r.err != nil {
+		return nil
+	}
+	return &r.rate.Source
+}
+
+func (r *rateResolver) Provider() *string {
+	if r.err != nil || r.rate.Provider == "" {
+		return nil
+	}
+	return &r.rate.Provider
+}
+
+func (r *rateResolver) Timestamp() *graphqlgo.Time {
+	if r.err != nil || r.rate.Timestamp.IsZero() {
+		return nil
+	}
+	return &graphqlgo.Time{Time: r.rate.Timestamp}
+}
+
+func (r *rateResolver) CacheAgeSeconds() *float64 {
+	if r.err != nil {
+		return nil
+	}
+	age := r.rate.CacheAge.Seconds()
+	return &age
+}
+
+func (r *rateResolver) Stale() *bool {
+	if r.err != nil {
+		return nil
+	}
+	return &r.rate.Stale
+}
+
+func (r *rateResolver) OverrideID() *string {
+	if r.err != nil || r.rate.Override == nil {
+		return nil
+	}
+	return &r.rate.Override.ID
+}
+
+func (r *rateResolver) Error() *errorResolver {
+	if r.err == nil {
+		return nil
+	}
+	return &errorResolver{err: r.err}
+}
+
+type conversionResolver struct {
+	r *service.ConversionResult
+}
+
+func (c *conversionResolver) From() string       { return c.r.From }
+func (c *conversionResolver) To() string         { return c.r.To }
+func (c *conversionResolver) Amount() float64    { return c.r.Amount }
+func (c *conversionResolver) Result() float64    { return c.r.Result }
+func (c *conversionResolver) Rate() float64      { return c.r.Rate }
+func (c *conversionResolver) MidRate() float64   { return c.r.MidRate }
+func (c *conversionResolver) MarkupPct() float64 { return c.r.MarkupPct }
+func (c *conversionResolver) Fee() float64       { return c.r.Fee }
+func (c *conversionResolver) RawResult() float64 { return c.r.RawResult }
+func (c *conversionResolver) Rounding() string   { return c.r.Rounding }
+func (c *conversionResolver) Source() string     { return c.r.Source }
+func (c *conversionResolver) Stale() bool        { return c.r.Stale }
+
+func (c *conversionResolver) Provider() *string {
+	if c.r.Provider == "" {
+		return nil
+	}
+	return &c.r.Provider
+}
+
+func (c *conversionResolver) Timestamp() graphqlgo.Time {
+	return graphqlgo.Time{Time: c.r.Timestamp}
+}
+
+func (c *conversionResolver) OverrideID() *string {
+	if c.r.Override == nil {
+		return nil
+	}
+	return &c.r.Override.ID
+}
+
+type historyPointResolver struct {
+	rate     float64
+	provider string
+	at       time.Time
+}
+
+func (p *historyPointResolver) Rate() float64      { return p.rate }
+func (p *historyPointResolver) Provider() string   { return p.provider }
+func (p *historyPointResolver) At() graphqlgo.Time { return graphqlgo.Time{Time: p.at} }
+
+type errorResolver struct {
+	err *apperror.Error
+}
+
+func (e *errorResolver) Code() string    { return string(e.err.Kind) }
+func (e *errorResolver) Message() string { return e.err.Message }
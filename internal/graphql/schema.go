@@ -0,0 +1,131 @@
+// Package graphql - GraphQL API поверх сервиса конвертации: валюты, курсы,
+// конвертация и история за один запрос.
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/internal/service"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+)
+
+const schemaSDL = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	"Справочник поддерживаемых валют"
+	currencies: [Currency!]!
+	"Курсы base к каждой из symbols; date (YYYY-MM-DD) - курс на конец дня из истории"
+	rates(base: String!, symbols: [String!]!, date: String): [Rate!]!
+	"Конвертация по текущему курсу с наценкой и комиссией тарифа клиента"
+	convert(from: String!, to: String!, amount: Float!): Conversion!
+	"История курса пары от старых точек к новым"
+	history(from: String!, to: String!, since: Time, until: Time, limit: Int): [HistoryPoint!]!
+}
+
+type Currency {
+	code: String!
+	name: String!
+	minorUnits: Int!
+}
+
+"Курс одной пары. Если курс получить не удалось, заполнено только error."
+type Rate {
+	base: String!
+	symbol: String!
+	value: Float
+	source: String
+	provider: String
+	timestamp: Time
+	cacheAgeSeconds: Float
+	stale: Boolean
+	overrideId: String
+	error: Error
+}
+
+type Conversion {
+	from: String!
+	to: String!
+	amount: Float!
+	result: Float!
+	rate: Float!
+	midRate: Float!
+	markupPct: Float!
+	fee: Float!
+	rawResult: Float!
+	rounding: String!
+	source: String!
+	provider: String
+	timestamp: Time!
+	stale: Boolean!
+	overrideId: String
+}
+
+type HistoryPoint {
+	rate: Float!
+	provider: String!
+	at: Time!
+}
+
+type Error {
+	code: String!
+	message: String!
+}
+`
+
+// Ограничения на сложность запроса
+const (
+	maxDepth       = 8
+	maxQueryLength = 16 << 10
+	maxSymbols     = 50
+)
+
+// Service - методы сервиса конвертации, которые нужны резолверам
+type Service interface {
+	GetRateTable(ctx context.Context, base string) (service.RateTable, error)
+	TableRate(ctx context.Context, from, to string, table service.TableLoader) (service.Rate, error)
+	RateAt(ctx context.Context, from, to string, t time.Time) (service.Rate, error)
+	ConvertRate(ctx context.Context, from, to string, amount float64, rate service.Rate) (*service.ConversionResult, error)
+	History(ctx context.Context, from, to string, since, until time.Time, limit int) ([]history.Point, error)
+}
+
+// Schema - исполняемая схема. Для каждого запроса создается свой загрузчик курсов,
+// так что одна и та же пара запрашивается у сервиса не больше одного раза.
+type Schema struct {
+	schema *graphqlgo.Schema
+	svc    Service
+}
+
+func New(svc Service, logger *zap.Logger) *Schema {
+	schema := graphqlgo.MustParseSchema(schemaSDL, &resolver{svc: svc, logger: logger},
+		graphqlgo.UseStringDescriptions(),
+		graphqlgo.MaxDepth(maxDepth),
+		graphqlgo.MaxQueryLength(maxQueryLength),
+		graphqlgo.Logger(panicLogger{logger: logger}),
+	)
+	return &Schema{schema: schema, svc: svc}
+}
+
+// Exec выполняет запрос. Ошибки резолверов попадают в Response.Errors
+// с машинным кодом в extensions.code.
+func (s *Schema) Exec(ctx context.Context, query, operationName string, variables map[string]any) *graphqlgo.Response {
+	ctx = withLoader(ctx, newLoader(s.svc))
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
+
+// panicLogger пишет паники резолверов в zap
+type panicLogger struct {
+	logger *zap.Logger
+}
+
+func (l panicLogger) LogPanic(_ context.Context, value any) {
+	l.logger.Error("GraphQL resolver panicked", zap.Any("panic", value), zap.Stack("stack"))
+}
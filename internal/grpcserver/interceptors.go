@@ -0,0 +1,155 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/logging"
+	currencyv1 "currency-converter-v2/pkg/pb/currency/v1"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// apiKeyMetadata - ключ клиента, как заголовок X-API-Key в REST
+	apiKeyMetadata = "x-api-key"
+	// requestIDMetadata - идентификатор запроса, как X-Request-ID в REST
+	requestIDMetadata = "x-request-id"
+)
+
+// interceptors определяют клиента по ключу и логируют вызовы. Health и reflection
+// ключа не требуют: ими пользуются балансировщики и grpcurl.
+type interceptors struct {
+	keys     []config.APIKey
+	required bool
+	logger   *zap.Logger
+}
+
+func (i interceptors) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	started := time.Now()
+	ctx, err := i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		i.log(ctx, info.FullMethod, started, err)
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	i.log(ctx, info.FullMethod, started, err)
+	return resp, err
+}
+
+func (i interceptors) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	started := time.Now()
+	ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	i.log(ctx, info.FullMethod, started, err)
+	return err
+}
+
+func (i interceptors) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := first(md, requestIDMetadata)
+	if requestID == "" || len(requestID) > 128 {
+		requestID = newRequestID()
+	}
+	ctx = logging.WithRequestID(ctx, requestID)
+
+	if !isCurrencyMethod(method) {
+		return ctx, nil
+	}
+	provided := first(md, apiKeyMetadata)
+	if provided == "" {
+		if i.required {
+			return ctx, statusError(apperror.Unauthorized("API key is required"))
+		}
+		return auth.WithClient(ctx, auth.Anonymous), nil
+	}
+	client, ok := auth.Lookup(i.keys, provided)
+	if !ok {
+		return ctx, statusError(apperror.Unauthorized("invalid API key"))
+	}
+	return auth.WithClient(ctx, client), nil
+}
+
+func (i interceptors) log(ctx context.Context, method string, started time.Time, err error) {
+	logging.FromContext(ctx, i.logger).Info("gRPC Request",
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.String("client_id", auth.ClientFrom(ctx).ID),
+		zap.Duration("latency", time.Since(started)),
+	)
+}
+
+// statusError переводит apperror в gRPC-статус; сообщение - то же, что в REST
+func statusError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	appErr := apperror.From(err)
+	return status.Error(grpcCode(appErr.Kind), appErr.Message)
+}
+
+func grpcCode(kind apperror.Kind) codes.Code {
+	switch kind {
+	case apperror.KindValidation:
+		return codes.InvalidArgument
+	case apperror.KindUnsupportedCurrency:
+		return codes.FailedPrecondition
+	case apperror.KindUpstreamUnavailable:
+		return codes.Unavailable
+	case apperror.KindRateLimited:
+		return codes.ResourceExhausted
+	case apperror.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperror.KindForbidden:
+		return codes.PermissionDenied
+	case apperror.KindNotFound:
+		return codes.NotFound
+	case apperror.KindConflict:
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
+
+// contextStream подменяет контекст потока контекстом с клиентом
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func isCurrencyMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+currencyv1.CurrencyService_ServiceDesc.ServiceName+"/")
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,177 @@
+// Package grpcserver - gRPC API поверх того же CurrencyServiceInterface, что и REST.
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/stream"
+	currencyv1 "currency-converter-v2/pkg/pb/currency/v1"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatch - сколько конвертаций принимает один BatchConvert
+const maxBatch = 100
+
+// Server реализует currency.v1.CurrencyService
+type Server struct {
+	currencyv1.UnimplementedCurrencyServiceServer
+
+	currencyService service.CurrencyServiceInterface
+	hub             *stream.Hub
+}
+
+// New собирает grpc.Server с API конвертера, health-check и reflection.
+// Health возвращается отдельно, чтобы перевести его в NOT_SERVING при остановке.
+func New(currencyService service.CurrencyServiceInterface, hub *stream.Hub, keys []config.APIKey, required bool, logger *zap.Logger) (*grpc.Server, *health.Server) {
+	interceptors := interceptors{keys: keys, required: required, logger: logger}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors.unary),
+		grpc.ChainStreamInterceptor(interceptors.stream),
+	)
+	currencyv1.RegisterCurrencyServiceServer(server, &Server{currencyService: currencyService, hub: hub})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(currencyv1.CurrencyService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server, healthServer
+}
+
+func (s *Server) Convert(ctx context.Context, req *currencyv1.ConvertRequest) (*currencyv1.ConvertResponse, error) {
+	response, err := s.convert(ctx, req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return response, nil
+}
+
+func (s *Server) GetRate(ctx context.Context, req *currencyv1.GetRateRequest) (*currencyv1.GetRateResponse, error) {
+	from, to := strings.ToUpper(req.GetFrom()), strings.ToUpper(req.GetTo())
+	if err := validatePair(from, to); err != nil {
+		return nil, statusError(err)
+	}
+	rate, err := s.currencyService.GetExchangeRate(ctx, from, to)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &currencyv1.GetRateResponse{From: from, To: to, Rate: rate}, nil
+}
+
+func (s *Server) BatchConvert(ctx context.Context, req *currencyv1.BatchConvertRequest) (*currencyv1.BatchConvertResponse, error) {
+	if len(req.GetConversions()) == 0 {
+		return nil, statusError(apperror.Validation("conversions must not be empty"))
+	}
+	if len(req.GetConversions()) > maxBatch {
+		return nil, statusError(apperror.Validation(fmt.Sprintf("at most %d conversions per batch", maxBatch)))
+	}
+
+	results := make([]*currencyv1.BatchConvertResult, 0, len(req.GetConversions()))
+	for _, conversion := range req.GetConversions() {
+		response, err := s.convert(ctx, conversion)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, statusError(ctx.Err())
+			}
+			appErr := apperror.From(err)
+			results = append(results, &currencyv1.BatchConvertResult{
+				Outcome: &currencyv1.BatchConvertResult_Error{Error: &currencyv1.Error{
+					Code:    string(appErr.Kind),
+					Message: appErr.Message,
+				}},
+			})
+			continue
+		}
+		results = append(results, &currencyv1.BatchConvertResult{
+			Outcome: &currencyv1.BatchConvertResult_Conversion{Conversion: response},
+		})
+	}
+	return &currencyv1.BatchConvertResponse{Results: results}, nil
+}
+
+func (s *Server) WatchRates(req *currencyv1.WatchRatesRequest, srv grpc.ServerStreamingServer[currencyv1.RateUpdate]) error {
+	base := strings.ToUpper(req.GetBase())
+	if len(base) != 3 {
+		return statusError(apperror.Validation("base must be a 3-letter currency code"))
+	}
+	symbols := make([]string, 0, len(req.GetSymbols()))
+	for _, symbol := range req.GetSymbols() {
+		symbol = strings.ToUpper(symbol)
+		if len(symbol) != 3 {
+			return statusError(apperror.Validation("symbols must be 3-letter currency codes"))
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	sub, _ := s.hub.Subscribe(base, symbols, 0)
+	defer sub.Close()
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Клиент отстал или сервер останавливается: клиент переподпишется
+				return statusError(apperror.New(apperror.KindUpstreamUnavailable, "rate stream closed, resubscribe", nil))
+			}
+			if err := srv.Send(&currencyv1.RateUpdate{
+				EventId:   event.ID,
+				Base:      event.Update.Base,
+				Rates:     event.Update.Rates,
+				Timestamp: timestamppb.New(event.Update.Timestamp),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) convert(ctx context.Context, req *currencyv1.ConvertRequest) (*currencyv1.ConvertResponse, error) {
+	from, to := strings.ToUpper(req.GetFrom()), strings.ToUpper(req.GetTo())
+	if err := validatePair(from, to); err != nil {
+		return nil, err
+	}
+	if req.GetAmount() < 0.01 {
+		return nil, apperror.Validation("amount must be at least 0.01")
+	}
+
+	result, err := s.currencyService.Convert(ctx, from, to, req.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+	response := &currencyv1.ConvertResponse{
+		From:            result.From,
+		To:              result.To,
+		Amount:          result.Amount,
+		Rate:            result.Rate,
+		Result:          result.Result,
+		MidRate:         result.MidRate,
+		MarkupPct:       result.MarkupPct,
+		Fee:             result.Fee,
+		Source:          result.Source,
+		Provider:        result.Provider,
+		CacheAgeSeconds: result.CacheAge.Seconds(),
+		Stale:           result.Stale,
+	}
+	if !result.Timestamp.IsZero() {
+		response.RateTimestamp = timestamppb.New(result.Timestamp)
+	}
+	return response, nil
+}
+
+func validatePair(from, to string) error {
+	if len(from) != 3 || len(to) != 3 {
+		return apperror.Validation("from and to must be 3-letter currency codes")
+	}
+	return nil
+}
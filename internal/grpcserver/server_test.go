@@ -0,0 +1,166 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/quote"
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/stream"
+	currencyv1 "currency-converter-v2/pkg/pb/currency/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stubService конвертирует по курсу 0.9 и запоминает клиента последнего вызова
+type stubService struct {
+	lastClient auth.Client
+}
+
+func (s *stubService) Convert(ctx context.Context, from, to string, amount float64) (*service.ConversionResult, error) {
+	s.lastClient = auth.ClientFrom(ctx)
+	if to == "XXX" {
+		return nil, apperror.New(apperror.KindUnsupportedCurrency, "unsupported currency XXX", nil)
+	}
+	return &service.ConversionResult{
+		From: from, To: to, Amount: amount, Rate: 0.9, MidRate: 0.9, Result: amount * 0.9,
+		Provenance: service.Provenance{Source: service.RateSourceCache, Provider: "fixture", Timestamp: time.Now()},
+	}, nil
+}
+
+func (s *stubService) GetExchangeRate(context.Context, string, string) (float64, error) {
+	return 0.9, nil
+}
+
+func (s *stubService) CreateQuote(context.Context, string, string, float64) (*quote.Quote, error) {
+	return nil, apperror.NotFound("quotes are disabled")
+}
+
+func (s *stubService) ExecuteQuote(context.Context, string, service.QuoteTerms) (*service.ConversionResult, error) {
+	return nil, apperror.NotFound("quotes are disabled")
+}
+
+func (s *stubService) ConvertMany(context.Context, string, []string, float64) (map[string]service.TargetResult, error) {
+	return nil, apperror.NotFound("not used over gRPC")
+}
+
+func (s *stubService) ConvertTarget(context.Context, string, string, float64) (*service.ConversionResult, error) {
+	return nil, apperror.NotFound("not used over gRPC")
+}
+
+func startServer(t *testing.T, svc service.CurrencyServiceInterface, hub *stream.Hub) *grpc.ClientConn {
+	t.Helper()
+	keys := []config.APIKey{{Key: "svc-key", Plan: config.PlanPremium, ClientID: "billing"}}
+	server, _ := New(svc, hub, keys, true, zap.NewNop())
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, key)
+}
+
+func TestConvert(t *testing.T) {
+	svc := &stubService{}
+	client := currencyv1.NewCurrencyServiceClient(startServer(t, svc, stream.NewHub(nil, zap.NewNop())))
+
+	resp, err := client.Convert(withKey("svc-key"), &currencyv1.ConvertRequest{From: "usd", To: "eur", Amount: 100})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", resp.GetTo())
+	assert.InDelta(t, 90.0, resp.GetResult(), 1e-9)
+	assert.Equal(t, "cache", resp.GetSource())
+	assert.NotNil(t, resp.GetRateTimestamp())
+	assert.Equal(t, "billing", svc.lastClient.ID)
+
+	_, err = client.Convert(context.Background(), &currencyv1.ConvertRequest{From: "USD", To: "EUR", Amount: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Convert(withKey("svc-key"), &currencyv1.ConvertRequest{From: "USD", To: "EUR"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Convert(withKey("svc-key"), &currencyv1.ConvertRequest{From: "USD", To: "XXX", Amount: 1})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestGetRateAndBatchConvert(t *testing.T) {
+	client := currencyv1.NewCurrencyServiceClient(startServer(t, &stubService{}, stream.NewHub(nil, zap.NewNop())))
+
+	rate, err := client.GetRate(withKey("svc-key"), &currencyv1.GetRateRequest{From: "USD", To: "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate.GetRate())
+
+	batch, err := client.BatchConvert(withKey("svc-key"), &currencyv1.BatchConvertRequest{
+		Conversions: []*currencyv1.ConvertRequest{
+			{From: "USD", To: "EUR", Amount: 10},
+			{From: "USD", To: "XXX", Amount: 10},
+			{From: "USD", To: "GBP", Amount: 0},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.GetResults(), 3)
+	assert.InDelta(t, 9.0, batch.GetResults()[0].GetConversion().GetResult(), 1e-9)
+	assert.Equal(t, "unsupported_currency", batch.GetResults()[1].GetError().GetCode())
+	assert.Equal(t, "validation", batch.GetResults()[2].GetError().GetCode())
+
+	_, err = client.BatchConvert(withKey("svc-key"), &currencyv1.BatchConvertRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchRates(t *testing.T) {
+	hub := stream.NewHub(nil, zap.NewNop())
+	client := currencyv1.NewCurrencyServiceClient(startServer(t, &stubService{}, hub))
+
+	ctx, cancel := context.WithCancel(withKey("svc-key"))
+	defer cancel()
+	watch, err := client.WatchRates(ctx, &currencyv1.WatchRatesRequest{Base: "usd", Symbols: []string{"EUR"}})
+	require.NoError(t, err)
+
+	// Подписка появляется, когда вызов дошел до сервера
+	require.Eventually(t, func() bool { return len(hub.Pairs(context.Background())) == 1 },
+		time.Second, 10*time.Millisecond)
+	hub.Publish(context.Background(), refresher.Update{
+		Base:      "USD",
+		Rates:     map[string]float64{"EUR": 0.92, "GBP": 0.79},
+		Timestamp: time.Now(),
+	})
+
+	update, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "USD", update.GetBase())
+	assert.Equal(t, map[string]float64{"EUR": 0.92}, update.GetRates())
+}
+
+func TestHealthWithoutAPIKey(t *testing.T) {
+	conn := startServer(t, &stubService{}, stream.NewHub(nil, zap.NewNop()))
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: currencyv1.CurrencyService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"currency-converter-v2/internal/quota"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	quota     *quota.Tracker
+	providers []string
+}
+
+func NewAdminHandler(quotaTracker *quota.Tracker, providers []string) *AdminHandler {
+	return &AdminHandler{
+		quota:     quotaTracker,
+		providers: providers,
+	}
+}
+
+// Quota возвращает расход и остаток бюджета запросов по каждому провайдеру
+func (h *AdminHandler) Quota(c *gin.Context) {
+	usages := make([]quota.Usage, 0, len(h.providers))
+	for _, provider := range h.providers {
+		usages = append(usages, h.quota.Usage(c.Request.Context(), provider))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   h.quota.Enabled(),
+		"providers": usages,
+	})
+}
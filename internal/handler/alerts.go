@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"currency-converter-v2/internal/alert"
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultDeadLettersLimit = 100
+
+// AlertHandler - правила оповещений клиента. Правила привязаны к API-ключу,
+// поэтому анонимным клиентам раздел недоступен.
+type AlertHandler struct {
+	alerts *alert.Manager
+}
+
+func NewAlertHandler(manager *alert.Manager) *AlertHandler {
+	return &AlertHandler{alerts: manager}
+}
+
+// client возвращает клиента запроса или отвечает 401 для анонимного
+func (h *AlertHandler) client(c *gin.Context) (auth.Client, bool) {
+	client := auth.ClientFrom(c.Request.Context())
+	if client.ID == auth.Anonymous.ID {
+		respondError(c, apperror.Unauthorized("alerts require an API key"))
+		return auth.Client{}, false
+	}
+	return client, true
+}
+
+// List возвращает правила клиента
+func (h *AlertHandler) List(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	rules, err := h.alerts.List(c.Request.Context(), client.ID)
+	if err != nil {
+		respondError(c, apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": rules})
+}
+
+// Get возвращает правило клиента
+func (h *AlertHandler) Get(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	rule, err := h.alerts.Get(c.Request.Context(), client.ID, c.Param("id"))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// Create добавляет правило. Секрет подписи вебхуков отдается только здесь.
+func (h *AlertHandler) Create(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	var req model.AlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	rule, err := h.alerts.Create(c.Request.Context(), client.ID, alertRule(req))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"alert": rule, "secret": rule.Secret})
+}
+
+// Update заменяет условия правила
+func (h *AlertHandler) Update(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	var req model.AlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	rule, err := h.alerts.Update(c.Request.Context(), client.ID, c.Param("id"), alertRule(req))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// Delete удаляет правило
+func (h *AlertHandler) Delete(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	if err := h.alerts.Delete(c.Request.Context(), client.ID, c.Param("id")); err != nil {
+		respondAlertError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeadLetters возвращает вебхуки клиента, которые не удалось доставить
+func (h *AlertHandler) DeadLetters(c *gin.Context) {
+	client, ok := h.client(c)
+	if !ok {
+		return
+	}
+	var query model.DeadLettersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultDeadLettersLimit
+	}
+	letters, err := h.alerts.DeadLetters(c.Request.Context(), client.ID, query.Limit)
+	if err != nil {
+		respondError(c, apperror.Internal(err))
+		return
+	}
+	if letters == nil {
+		letters = []alert.DeadLetter{}
+	}
+	c.JSON(http.StatusOK, gin.H{"dead_letters": letters})
+}
+
+func alertRule(req model.AlertRequest) alert.Rule {
+	return alert.Rule{
+		Kind:       alert.Kind(req.Kind),
+		From:       req.From,
+		To:         req.To,
+		Threshold:  req.Threshold,
+		WebhookURL: req.WebhookURL,
+	}
+}
+
+func respondAlertError(c *gin.Context, err error) {
+	var validationErr *alert.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondError(c, apperror.Validation(validationErr.Error()))
+	case errors.Is(err, alert.ErrNotFound):
+		respondError(c, apperror.NotFound("alert not found"))
+	default:
+		respondError(c, apperror.Internal(err))
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/audit"
+	"currency-converter-v2/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditPageSize = 100
+	// Размер страницы при выгрузке CSV: выгружается вся выборка, порциями
+	csvExportPageSize = 1000
+)
+
+type AuditHandler struct {
+	store audit.Store
+}
+
+func NewAuditHandler(store audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// Conversions отдает журнал конвертаций от новых к старым, постранично (JSON)
+// или целиком (format=csv)
+func (h *AuditHandler) Conversions(c *gin.Context) {
+	var req model.ConversionsQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	filter, err := auditFilter(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if req.Format == "csv" {
+		h.exportCSV(c, filter)
+		return
+	}
+
+	entries, err := h.store.Query(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, apperror.Internal(err))
+		return
+	}
+	response := gin.H{"conversions": entries}
+	if len(entries) == 0 {
+		response["conversions"] = []audit.Entry{}
+	}
+	if len(entries) == filter.Limit {
+		response["next_cursor"] = strconv.FormatInt(entries[len(entries)-1].ID, 10)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// auditFilter собирает фильтр хранилища из параметров запроса
+func auditFilter(req model.ConversionsQuery) (audit.Filter, error) {
+	filter := audit.Filter{
+		ClientID: req.ClientID,
+		From:     strings.ToUpper(req.From),
+		To:       strings.ToUpper(req.To),
+		Since:    req.Since,
+		Until:    req.Until,
+		Before:   req.Cursor,
+		Limit:    req.Limit,
+	}
+	if req.Pair != "" {
+		from, to, ok := strings.Cut(strings.ToUpper(req.Pair), "/")
+		if !ok || len(from) != 3 || len(to) != 3 {
+			return filter, apperror.Validation("pair must look like USD/EUR")
+		}
+		filter.From, filter.To = from, to
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return filter, apperror.Validation("since must be before until")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	return filter, nil
+}
+
+var csvHeader = []string{
+	"id", "created_at", "request_id", "client_id", "from", "to", "amount",
+	"mid_rate", "rate", "markup_pct", "fee", "rate_source", "rate_timestamp",
+	"raw_result", "result", "rounding", "override_id", "quote_id", "rate_provider", "stale",
+}
+
+// exportCSV выгружает всю выборку, проходя по страницам курсором.
+// Ошибка посреди выгрузки обрывает ответ: заголовки уже отправлены.
+func (h *AuditHandler) exportCSV(c *gin.Context, filter audit.Filter) {
+	ctx := c.Request.Context()
+	filter.Limit = csvExportPageSize
+
+	entries, err := h.store.Query(ctx, filter)
+	if err != nil {
+		respondError(c, apperror.Internal(err))
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="conversions.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(csvHeader)
+	for {
+		for _, e := range entries {
+			_ = w.Write(csvRecord(e))
+		}
+		w.Flush()
+		if len(entries) < filter.Limit || w.Error() != nil {
+			return
+		}
+		filter.Before = entries[len(entries)-1].ID
+		if entries, err = h.store.Query(ctx, filter); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+}
+
+func csvRecord(e audit.Entry) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		strconv.FormatInt(e.ID, 10),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.RequestID,
+		e.ClientID,
+		e.From,
+		e.To,
+		f(e.Amount),
+		f(e.MidRate),
+		f(e.Rate),
+		f(e.MarkupPct),
+		f(e.Fee),
+		e.RateSource,
+		e.RateTimestamp.UTC().Format(time.RFC3339Nano),
+		f(e.RawResult),
+		f(e.Result),
+		e.Rounding,
+		e.OverrideID,
+		e.QuoteID,
+		e.RateProvider,
+		strconv.FormatBool(e.Stale),
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/audit"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/quote"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	svc := newOfflineService(t)
+	store := audit.NewMemoryStore()
+	svc.UseAudit(store)
+	svc.UseQuotes(quote.NewMemoryStore(), time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	keys := []config.APIKey{{Key: "shop-key", Plan: config.PlanBasic, ClientID: "shop"}}
+	h := NewCurrencyHandler(svc)
+	api := router.Group("", middleware.APIKeyMiddleware(keys, false))
+	api.GET("/convert", h.Convert)
+	api.POST("/quotes", h.CreateQuote)
+	api.POST("/quotes/:id/execute", h.ExecuteQuote)
+	router.GET("/admin/conversions", NewAuditHandler(store).Conversions)
+	return router
+}
+
+type conversionsPage struct {
+	Conversions []audit.Entry `json:"conversions"`
+	NextCursor  string        `json:"next_cursor"`
+}
+
+func listConversions(t *testing.T, router *gin.Engine, query string) conversionsPage {
+	t.Helper()
+	w := doRequest(router, http.MethodGet, "/admin/conversions?"+query, "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page conversionsPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page
+}
+
+func TestAudit_RecordsEveryConversion(t *testing.T) {
+	router := setupAuditRouter(t)
+
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/convert?from=USD&to=EUR&amount=100", "shop-key", "").Code)
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/convert?from=USD&to=GBP&amount=10", "", "").Code)
+	q := createQuote(t, router, "shop-key")
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/quotes/"+q.ID+"/execute", "shop-key", "").Code)
+
+	// Создание котировки - не конвертация, ее исполнение - да
+	page := listConversions(t, router, "")
+	require.Len(t, page.Conversions, 3)
+	assert.Empty(t, page.NextCursor)
+
+	latest := page.Conversions[0]
+	assert.Equal(t, q.ID, latest.QuoteID)
+	assert.Equal(t, "shop", latest.ClientID)
+
+	first := page.Conversions[2]
+	assert.Equal(t, "USD", first.From)
+	assert.Equal(t, "EUR", first.To)
+	assert.Equal(t, 100.0, first.Amount)
+	assert.Equal(t, 0.92, first.Rate)
+	assert.InDelta(t, 92.0, first.Result, 1e-9)
+	assert.Equal(t, "upstream", first.RateSource)
+	assert.Equal(t, "fixture", first.RateProvider)
+	assert.Equal(t, "none", first.Rounding)
+	assert.NotEmpty(t, first.RequestID)
+	assert.False(t, first.RateTimestamp.IsZero())
+
+	// Фильтры
+	assert.Len(t, listConversions(t, router, "client_id=shop").Conversions, 2)
+	assert.Len(t, listConversions(t, router, "pair=usd/gbp").Conversions, 1)
+	assert.Len(t, listConversions(t, router, "until="+first.CreatedAt.Add(-time.Hour).Format(time.RFC3339)).Conversions, 0)
+
+	// Пагинация курсором
+	page = listConversions(t, router, "limit=2")
+	require.Len(t, page.Conversions, 2)
+	require.NotEmpty(t, page.NextCursor)
+	page = listConversions(t, router, "limit=2&cursor="+page.NextCursor)
+	require.Len(t, page.Conversions, 1)
+	assert.Equal(t, first.ID, page.Conversions[0].ID)
+
+	w := doRequest(router, http.MethodGet, "/admin/conversions?pair=USDEUR1", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAudit_CSVExport(t *testing.T) {
+	router := setupAuditRouter(t)
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/convert?from=USD&to=EUR&amount=100", "", "").Code)
+	}
+
+	w := doRequest(router, http.MethodGet, "/admin/conversions?format=csv&pair=USD/EUR", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"USD", "EUR", "100", "0.92"}, []string{records[1][4], records[1][5], records[1][6], records[1][8]})
+}
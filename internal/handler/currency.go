@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"currency-converter-v2/internal/apperror"
 	"currency-converter-v2/internal/model"
 	"currency-converter-v2/internal/service"
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("currency-converter-v2/internal/handler")
+
 type CurrencyHandler struct {
 	currencyService service.CurrencyServiceInterface
 }
@@ -19,28 +28,159 @@ func NewCurrencyHandler(currencyService service.CurrencyServiceInterface) *Curre
 }
 
 func (h *CurrencyHandler) Convert(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CurrencyHandler.Convert")
+	defer span.End()
+
 	var req model.ConvertRequest
 	err := c.ShouldBindQuery(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error:   "Invalid request",
-			Details: err.Error(),
+		span.SetStatus(codes.Error, "invalid request")
+		respondValidationError(c, err)
+		return
+	}
+	span.SetAttributes(
+		attribute.String("currency.from", req.From),
+		attribute.String("currency.to", req.To),
+		attribute.Float64("currency.amount", req.Amount),
+	)
+	targets := parseTargets(req.To, req.Targets)
+	multi := len(targets) > 1 || len(req.Targets) > 0
+	if req.AmountIn == model.AmountInTarget {
+		span.SetAttributes(attribute.String("currency.amount_in", req.AmountIn))
+		switch {
+		case req.QuoteID != "":
+			respondError(c, apperror.Validation("quote_id cannot be combined with amount_in=target"))
+			return
+		case multi:
+			respondError(c, apperror.Validation("amount_in=target supports a single target"))
+			return
+		}
+	}
+	if multi {
+		h.convertMany(c, req, targets)
+		return
+	}
+
+	// "to=eur" и "to=EUR," - тоже одна валюта, в сервис идет нормализованный код
+	to := req.To
+	if len(targets) == 1 {
+		to = targets[0]
+	}
+
+	var result *service.ConversionResult
+	switch {
+	case req.AmountIn == model.AmountInTarget:
+		result, err = h.currencyService.ConvertTarget(ctx, req.From, to, req.Amount)
+	case req.QuoteID != "":
+		span.SetAttributes(attribute.String("quote.id", req.QuoteID))
+		result, err = h.currencyService.ExecuteQuote(ctx, req.QuoteID, service.QuoteTerms{
+			From:   req.From,
+			To:     to,
+			Amount: req.Amount,
 		})
+	default:
+		result, err = h.currencyService.Convert(ctx, req.From, to, req.Amount)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "conversion failed")
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, convertResponse(result))
+}
+
+// maxTargets - сколько валют назначения можно запросить за раз
+const maxTargets = 50
+
+// convertMany конвертирует сумму в несколько валют; ошибки - по каждой валюте отдельно
+func (h *CurrencyHandler) convertMany(c *gin.Context, req model.ConvertRequest, targets []string) {
+	ctx, span := tracer.Start(c.Request.Context(), "CurrencyHandler.ConvertMany")
+	defer span.End()
+	span.SetAttributes(attribute.StringSlice("currency.targets", targets))
+
+	switch {
+	case req.QuoteID != "":
+		respondError(c, apperror.Validation("quote_id cannot be combined with several targets"))
+		return
+	case req.From == "" || req.Amount == 0:
+		respondError(c, apperror.Validation("from and amount are required"))
+		return
+	case len(targets) == 0:
+		respondError(c, apperror.Validation("targets must not be empty"))
+		return
+	case len(targets) > maxTargets:
+		respondError(c, apperror.Validation(fmt.Sprintf("at most %d targets per request", maxTargets)))
 		return
 	}
-	result, rate, err := h.currencyService.Convert(c.Request.Context(), req.From, req.To, req.Amount)
+
+	results, err := h.currencyService.ConvertMany(ctx, req.From, targets, req.Amount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error:   "Conversion failed",
-			Details: err.Error(),
-		})
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "conversion failed")
+		respondError(c, err)
 		return
 	}
-	c.JSON(200, model.ConvertResponse{
-		From:   req.From,
-		To:     req.To,
-		Amount: req.Amount,
-		Rate:   rate,
-		Result: result,
-	})
+	response := model.MultiConvertResponse{
+		From:    strings.ToUpper(req.From),
+		Amount:  req.Amount,
+		Results: make(map[string]model.TargetConversion, len(results)),
+	}
+	for target, res := range results {
+		if res.Err != nil {
+			errResp := errorResponse(c, apperror.From(res.Err))
+			response.Results[target] = model.TargetConversion{Error: &errResp}
+			continue
+		}
+		conversion := convertResponse(res.Result)
+		response.Results[target] = model.TargetConversion{Conversion: &conversion}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// parseTargets собирает валюты назначения из to (через запятую) и targets
+func parseTargets(to string, targets []string) []string {
+	var list []string
+	for _, raw := range append([]string{to}, targets...) {
+		for _, code := range strings.Split(raw, ",") {
+			if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+				list = append(list, code)
+			}
+		}
+	}
+	return list
+}
+
+// convertResponse переводит результат сервиса в ответ API
+func convertResponse(result *service.ConversionResult) model.ConvertResponse {
+	response := model.ConvertResponse{
+		From:      result.From,
+		To:        result.To,
+		Amount:    result.Amount,
+		Rate:      result.Rate,
+		Result:    result.Result,
+		MidRate:   result.MidRate,
+		MarkupPct: result.MarkupPct,
+		Fee:       result.Fee,
+		Target:    result.Target,
+		QuoteID:   result.QuoteID,
+
+		Source:   result.Source,
+		Provider: result.Provider,
+		CacheAge: math.Round(result.CacheAge.Seconds()*1000) / 1000,
+		Stale:    result.Stale,
+	}
+	// У записей кеша старого формата время получения неизвестно
+	if !result.Timestamp.IsZero() {
+		ts := result.Timestamp.UTC()
+		response.RateTimestamp = &ts
+	}
+	if o := result.Override; o != nil {
+		response.Override = &model.AppliedOverride{
+			ID:         o.ID,
+			Kind:       string(o.Kind),
+			ValidUntil: o.ValidUntil,
+		}
+	}
+	return response
 }
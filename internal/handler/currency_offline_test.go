@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/override"
+	"currency-converter-v2/internal/pricing"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/pkg/fixtures"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newOfflineService - настоящий CurrencyService поверх фикстур из testdata, без сети и Redis
+func newOfflineService(t *testing.T) *service.CurrencyService {
+	t.Helper()
+	cfg := config.Default()
+	cfg.API.CurrencyAPIURL = "http://127.0.0.1:1" // любое обращение к сети - ошибка
+
+	store, err := fixtures.Open("testdata/rates.csv")
+	require.NoError(t, err)
+
+	logger := zap.NewNop()
+	svc := service.NewCurrencyService(cfg, nil, quota.NewTracker(cfg.Quota, nil, logger), logger)
+	svc.UseFixtures(store, false)
+	require.NoError(t, svc.SetProviders([]string{service.FixtureProviderName}))
+	return svc
+}
+
+func setupOfflineRouter(svc *service.CurrencyService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/convert", NewCurrencyHandler(svc).Convert)
+	return router
+}
+
+func TestCurrencyHandler_Convert_OfflineFixtures(t *testing.T) {
+	router := setupOfflineRouter(newOfflineService(t))
+
+	w := performRequest(router, "GET", "/convert?from=USD&to=EUR&amount=100")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response model.ConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 0.92, response.Rate)
+	assert.InDelta(t, 92.0, response.Result, 1e-9)
+
+	// Пары нет в фикстурах - провайдер недоступен, а не 500
+	w = performRequest(router, "GET", "/convert?from=GBP&to=JPY&amount=1")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestCurrencyHandler_Convert_OverrideApplied(t *testing.T) {
+	svc := newOfflineService(t)
+	overrides := override.NewManager(override.NewMemoryStore(), zap.NewNop())
+	svc.UseOverrides(overrides)
+	peg, err := overrides.Create(context.Background(), override.Override{
+		Kind: override.KindPeg, From: "AED", To: "USD", Rate: 0.25,
+	})
+	require.NoError(t, err)
+
+	router := setupOfflineRouter(svc)
+
+	// AED->EUR = 0.25 (peg к USD) * 0.92 (USD->EUR из фикстур)
+	w := performRequest(router, "GET", "/convert?from=AED&to=EUR&amount=100")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response model.ConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.InDelta(t, 0.23, response.Rate, 1e-12)
+	require.NotNil(t, response.Override)
+	assert.Equal(t, peg.ID, response.Override.ID)
+	assert.Equal(t, "peg", response.Override.Kind)
+
+	// Рыночный курс - без пометки override
+	w = performRequest(router, "GET", "/convert?from=USD&to=GBP&amount=1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"override"`)
+}
+
+func TestCurrencyHandler_Convert_PricingByPlan(t *testing.T) {
+	svc := newOfflineService(t)
+	rules, err := pricing.Load(config.PricingConfig{SpreadPct: 1, FeeFixed: 2})
+	require.NoError(t, err)
+	rules.Plans = map[string]pricing.Rule{config.PlanPremium: {BuyPct: 0.5, SellPct: 0.5}}
+	svc.SetPricing(rules)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	keys := []config.APIKey{{Key: "premium-key", Plan: config.PlanPremium, ClientID: "shop"}}
+	router.GET("/convert", middleware.APIKeyMiddleware(keys, false), NewCurrencyHandler(svc).Convert)
+
+	convert := func(apiKey string) (int, model.ConvertResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/convert?from=USD&to=EUR&amount=100", nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response model.ConvertResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	// Без ключа - тариф free, правило по умолчанию
+	code, response := convert("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0.92, response.MidRate)
+	assert.Equal(t, 1.0, response.MarkupPct)
+	assert.InDelta(t, 0.92*0.99, response.Rate, 1e-12)
+	assert.Equal(t, 2.0, response.Fee)
+	assert.InDelta(t, 98*0.92*0.99, response.Result, 1e-9)
+
+	// Ключ premium - своя наценка, без комиссии
+	code, response = convert("premium-key")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0.5, response.MarkupPct)
+	assert.Zero(t, response.Fee)
+	assert.InDelta(t, 100*0.92*0.995, response.Result, 1e-9)
+
+	code, _ = convert("unknown-key")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestCurrencyHandler_Convert_Provenance(t *testing.T) {
+	svc := newOfflineService(t)
+	overrides := override.NewManager(override.NewMemoryStore(), zap.NewNop())
+	svc.UseOverrides(overrides)
+	_, err := overrides.Create(context.Background(), override.Override{Kind: override.KindFixed, From: "CHF", To: "EUR", Rate: 1.05})
+	require.NoError(t, err)
+	_, err = overrides.Create(context.Background(), override.Override{Kind: override.KindPeg, From: "AED", To: "USD", Rate: 0.25})
+	require.NoError(t, err)
+	router := setupOfflineRouter(svc)
+
+	convert := func(query string) model.ConvertResponse {
+		w := performRequest(router, "GET", "/convert?"+query)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response model.ConvertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// Без Redis курс всегда свежий от провайдера
+	response := convert("from=USD&to=EUR&amount=1")
+	assert.Equal(t, service.RateSourceUpstream, response.Source)
+	assert.Equal(t, service.FixtureProviderName, response.Provider)
+	assert.Zero(t, response.CacheAge)
+	assert.False(t, response.Stale)
+	require.NotNil(t, response.RateTimestamp)
+	assert.WithinDuration(t, time.Now(), *response.RateTimestamp, 5*time.Second)
+
+	response = convert("from=CHF&to=EUR&amount=1")
+	assert.Equal(t, service.RateSourceOverride, response.Source)
+	assert.Empty(t, response.Provider)
+
+	// Привязка: часть курса рыночная, провайдер - ее
+	response = convert("from=AED&to=EUR&amount=1")
+	assert.Equal(t, service.RateSourceDerived, response.Source)
+	assert.Equal(t, service.FixtureProviderName, response.Provider)
+}
+
+func TestCurrencyHandler_Convert_ManyTargets(t *testing.T) {
+	router := setupOfflineRouter(newOfflineService(t))
+
+	w := performRequest(router, "GET", "/convert?from=USD&to=EUR,gbp&targets=JPY&targets=EUR&amount=100")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response model.MultiConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "USD", response.From)
+	require.Len(t, response.Results, 3)
+	assert.InDelta(t, 92.0, response.Results["EUR"].Conversion.Result, 1e-9)
+	assert.InDelta(t, 79.0, response.Results["GBP"].Conversion.Result, 1e-9)
+	assert.Nil(t, response.Results["JPY"].Conversion)
+	assert.Equal(t, "unsupported_currency", response.Results["JPY"].Error.Code)
+
+	w = performRequest(router, "GET", "/convert?to=EUR,GBP&quote_id=q1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Одна валюта после разбора - обычная конвертация
+	for _, to := range []string{"EUR,", "eur", " EUR "} {
+		w = performRequest(router, "GET", "/convert?from=USD&amount=100&to="+url.QueryEscape(to))
+		require.Equal(t, http.StatusOK, w.Code, "%q: %s", to, w.Body.String())
+		var single model.ConvertResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &single))
+		assert.Equal(t, "EUR", single.To)
+		assert.InDelta(t, 92.0, single.Result, 1e-9)
+	}
+}
+
+func TestCurrencyHandler_Convert_AmountInTarget(t *testing.T) {
+	svc := newOfflineService(t)
+	rules, err := pricing.Load(config.PricingConfig{SpreadPct: 1, FeePct: 0.5, FeeFixed: 2, Rounding: pricing.RoundingHalfUp})
+	require.NoError(t, err)
+	svc.SetPricing(rules)
+	router := setupOfflineRouter(svc)
+
+	w := performRequest(router, "GET", "/convert?from=USD&to=EUR&amount=1000&amount_in=target")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response model.ConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1000.0, response.Target)
+	assert.GreaterOrEqual(t, response.Result, 1000.0)
+
+	// Прямая конвертация найденной суммы дает тот же результат, а на цент меньше - не хватает
+	w = performRequest(router, "GET", fmt.Sprintf("/convert?from=USD&to=EUR&amount=%v", response.Amount))
+	var forward model.ConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &forward))
+	assert.Equal(t, response.Result, forward.Result)
+	assert.Zero(t, forward.Target)
+
+	w = performRequest(router, "GET", fmt.Sprintf("/convert?from=USD&to=EUR&amount=%v", response.Amount-0.01))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &forward))
+	assert.Less(t, forward.Result, 1000.0)
+
+	for _, query := range []string{
+		"from=USD&to=EUR,GBP&amount=1000&amount_in=target",
+		"to=EUR&quote_id=q1&amount_in=target",
+		"from=USD&to=EUR&amount=1000&amount_in=result",
+	} {
+		w = performRequest(router, "GET", "/convert?"+query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -2,7 +2,14 @@ package handler
 
 import (
 	"context"
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/quote"
+	"currency-converter-v2/internal/service"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -27,20 +34,43 @@ type MockCurrencyService struct {
 	CallCount  int
 }
 
-func (m *MockCurrencyService) Convert(ctx context.Context, from, to string, amount float64) (float64, float64, error) {
+func (m *MockCurrencyService) Convert(ctx context.Context, from, to string, amount float64) (*service.ConversionResult, error) {
 	m.Called = true
 	m.CallCount++
 	m.LastFrom = from
 	m.LastTo = to
 	m.LastAmount = amount
 	if m.ShouldReturnError {
-		return 0, 0, m.MockError
+		return nil, m.MockError
 	}
-	return m.MockResult, m.MockRate, nil
+	return &service.ConversionResult{
+		From:   from,
+		To:     to,
+		Amount: amount,
+		Rate:   m.MockRate,
+		Result: m.MockResult,
+	}, nil
 }
 func (m *MockCurrencyService) GetExchangeRate(ctx context.Context, from, to string) (float64, error) {
 	return 0.0, nil
 }
+func (m *MockCurrencyService) CreateQuote(ctx context.Context, from, to string, amount float64) (*quote.Quote, error) {
+	return nil, apperror.NotFound("quotes are disabled")
+}
+func (m *MockCurrencyService) ExecuteQuote(ctx context.Context, id string, terms service.QuoteTerms) (*service.ConversionResult, error) {
+	return nil, apperror.NotFound("quotes are disabled")
+}
+func (m *MockCurrencyService) ConvertMany(ctx context.Context, from string, targets []string, amount float64) (map[string]service.TargetResult, error) {
+	results := make(map[string]service.TargetResult, len(targets))
+	for _, to := range targets {
+		result, err := m.Convert(ctx, from, to, amount)
+		results[to] = service.TargetResult{Result: result, Err: err}
+	}
+	return results, nil
+}
+func (m *MockCurrencyService) ConvertTarget(ctx context.Context, from, to string, target float64) (*service.ConversionResult, error) {
+	return nil, apperror.NotFound("reverse conversion is not mocked")
+}
 
 // setupTestRouter создаёт тестовый роутер с хендлером
 func setupTestRouter(service *MockCurrencyService) *gin.Engine {
@@ -102,17 +132,96 @@ func TestCurrencyHandler_Convert_ServiceError(t *testing.T) {
 
 	var errorResponse struct {
 		Error   string `json:"error"`
+		Code    string `json:"code"`
 		Details string `json:"details"`
 	}
 
 	err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
 	require.NoError(t, err)
 
-	assert.Equal(t, "Conversion failed", errorResponse.Error)
-	assert.Contains(t, errorResponse.Details, "assert.AnError")
+	assert.Equal(t, "internal", errorResponse.Code)
+	// Внутренний текст ошибки не должен утекать клиенту
+	assert.NotContains(t, w.Body.String(), "assert.AnError")
 
 	t.Log("ТЕСТ 2 ПРОЙДЕН: Обработка ошибки сервиса работает!")
 }
+
+func TestCurrencyHandler_Convert_ErrorKinds(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "UpstreamUnavailable",
+			err:        apperror.UpstreamUnavailable(errors.New("API returned status 502: 502 Bad Gateway")),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "upstream_unavailable",
+		},
+		{
+			name:       "UnsupportedCurrency",
+			err:        fmt.Errorf("failed to get rate from API: %w", apperror.UnsupportedCurrency("XXX")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "unsupported_currency",
+		},
+		{
+			name:       "Validation",
+			err:        apperror.Validation("amount must be positive"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation",
+		},
+		{
+			name:       "RateLimited",
+			err:        apperror.RateLimited("slow down"),
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   "rate_limited",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockCurrencyService{ShouldReturnError: true, MockError: tc.err}
+			router := setupTestRouter(mockService)
+
+			w := performRequest(router, "GET", "/convert?from=USD&to=XXX&amount=100")
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			var errorResponse model.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Equal(t, tc.wantCode, errorResponse.Code)
+			assert.NotContains(t, w.Body.String(), "Bad Gateway")
+		})
+	}
+}
+
+func TestCurrencyHandler_Convert_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.GET("/convert", NewCurrencyHandler(&MockCurrencyService{
+		ShouldReturnError: true,
+		MockError:         assert.AnError,
+	}).Convert)
+
+	// Идентификатор клиента пробрасывается в заголовок и тело ошибки
+	req, _ := http.NewRequest("GET", "/convert?from=USD&to=EUR&amount=100", nil)
+	req.Header.Set("X-Request-ID", "client-req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-req-42", w.Header().Get("X-Request-ID"))
+	var errorResponse model.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, "client-req-42", errorResponse.RequestID)
+
+	// Без заголовка идентификатор генерируется
+	w = performRequest(router, "GET", "/convert?from=USD&to=EUR&amount=100")
+	generated := w.Header().Get("X-Request-ID")
+	assert.Len(t, generated, 32)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, generated, errorResponse.RequestID)
+}
 func TestCurrencyHandler_Convert_ValidationError_CurrencyLength(t *testing.T) {
 	mockService := &MockCurrencyService{}
 	router := setupTestRouter(mockService)
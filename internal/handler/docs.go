@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"currency-converter-v2/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPISpec отдает описание API в формате OpenAPI 3
+func OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.JSON())
+}
+
+// SwaggerUI отдает страницу Swagger UI, построенную по /openapi.json
+func SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.SwaggerUI)
+}
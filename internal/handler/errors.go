@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondError отдает клиенту ошибку в едином формате.
+// Внутренняя причина (apperror.Error.Err) в ответ не попадает.
+func respondError(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	c.JSON(appErr.Kind.HTTPStatus(), errorResponse(c, appErr))
+}
+
+// errorResponse - ошибка в общем формате, в том числе для вложения в ответ
+func errorResponse(c *gin.Context, appErr *apperror.Error) model.ErrorResponse {
+	return model.ErrorResponse{
+		Error:     appErr.Kind.Title(),
+		Code:      string(appErr.Kind),
+		Message:   appErr.Message,
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
+}
+
+// respondValidationError отдает ошибку разбора/валидации параметров запроса
+func respondValidationError(c *gin.Context, err error) {
+	kind := apperror.KindValidation
+	c.JSON(kind.HTTPStatus(), model.ErrorResponse{
+		Error:     kind.Title(),
+		Code:      string(kind),
+		Details:   err.Error(),
+		RequestID: logging.RequestID(c.Request.Context()),
+	})
+}
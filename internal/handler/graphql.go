@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"currency-converter-v2/internal/graphql"
+	"currency-converter-v2/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler выполняет GraphQL-запросы
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+func NewGraphQLHandler(schema *graphql.Schema) *GraphQLHandler {
+	return &GraphQLHandler{schema: schema}
+}
+
+// Query - POST /graphql. Ошибки резолверов возвращаются в поле errors
+// со статусом 200, как принято в GraphQL; 400 - только для неразобранного тела.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req model.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	resp := h.schema.Exec(c.Request.Context(), req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, resp)
+}
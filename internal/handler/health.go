@@ -3,14 +3,50 @@ package handler
 import (
 	"net/http"
 
+	"currency-converter-v2/internal/health"
+	"currency-converter-v2/internal/version"
+
 	"github.com/gin-gonic/gin"
 )
 
-// HealthCheck возвращает статус здоровья сервиса
+// HealthCheck возвращает статус здоровья сервиса (оставлен для совместимости, аналог /livez)
 func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
 		"service": "currency-converter-api",
-		"version": "v2.0.0",
+		"version": version.Version,
+		"commit":  version.Commit,
+	})
+}
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Livez сообщает, что процесс жив. Зависимости не проверяются,
+// чтобы оркестратор не перезапускал под из-за недоступного Redis.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "alive",
+		"version":    version.Version,
+		"commit":     version.Commit,
+		"build_time": version.BuildTime,
 	})
 }
+
+// Readyz проверяет зависимости и возвращает 503, если упала хотя бы одна критичная
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == health.StatusNotReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
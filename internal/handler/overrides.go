@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/override"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OverrideHandler struct {
+	overrides *override.Manager
+}
+
+func NewOverrideHandler(manager *override.Manager) *OverrideHandler {
+	return &OverrideHandler{overrides: manager}
+}
+
+// List возвращает все ручные курсы, включая неактивные
+func (h *OverrideHandler) List(c *gin.Context) {
+	overrides := h.overrides.List()
+	if overrides == nil {
+		overrides = []override.Override{}
+	}
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+}
+
+// Create добавляет ручной курс
+func (h *OverrideHandler) Create(c *gin.Context) {
+	var req model.CreateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	created, err := h.overrides.Create(c.Request.Context(), override.Override{
+		Kind:       override.Kind(req.Kind),
+		From:       req.From,
+		To:         req.To,
+		Rate:       req.Rate,
+		ValidFrom:  req.ValidFrom,
+		ValidUntil: req.ValidUntil,
+		Comment:    req.Comment,
+	})
+	var validationErr *override.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		respondError(c, apperror.Validation(validationErr.Error()))
+		return
+	case err != nil:
+		respondError(c, apperror.Internal(err))
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// Delete удаляет ручной курс
+func (h *OverrideHandler) Delete(c *gin.Context) {
+	err := h.overrides.Delete(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, override.ErrNotFound):
+		respondError(c, apperror.NotFound("override not found"))
+		return
+	case err != nil:
+		respondError(c, apperror.Internal(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
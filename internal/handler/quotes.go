@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CreateQuote фиксирует курс для клиента: POST /api/v1/quotes
+func (h *CurrencyHandler) CreateQuote(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CurrencyHandler.CreateQuote")
+	defer span.End()
+
+	var req model.CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid request")
+		respondValidationError(c, err)
+		return
+	}
+	span.SetAttributes(
+		attribute.String("currency.from", req.From),
+		attribute.String("currency.to", req.To),
+		attribute.Float64("currency.amount", req.Amount),
+	)
+
+	q, err := h.currencyService.CreateQuote(ctx, req.From, req.To, req.Amount)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "quote failed")
+		respondError(c, err)
+		return
+	}
+	span.SetAttributes(attribute.String("quote.id", q.ID))
+	c.JSON(http.StatusCreated, model.QuoteResponse{
+		ID:        q.ID,
+		From:      q.From,
+		To:        q.To,
+		Amount:    q.Amount,
+		Rate:      q.Rate,
+		MidRate:   q.MidRate,
+		MarkupPct: q.MarkupPct,
+		Fee:       q.Fee,
+		Result:    q.Result,
+		ExpiresAt: q.ExpiresAt,
+	})
+}
+
+// ExecuteQuote исполняет котировку по зафиксированному курсу: POST /api/v1/quotes/:id/execute
+func (h *CurrencyHandler) ExecuteQuote(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CurrencyHandler.ExecuteQuote")
+	defer span.End()
+	span.SetAttributes(attribute.String("quote.id", c.Param("id")))
+
+	result, err := h.currencyService.ExecuteQuote(ctx, c.Param("id"), service.QuoteTerms{})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "quote execution failed")
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, convertResponse(result))
+}
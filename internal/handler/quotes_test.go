@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/quote"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupQuoteRouter(t *testing.T, ttl time.Duration) *gin.Engine {
+	t.Helper()
+	svc := newOfflineService(t)
+	svc.UseQuotes(quote.NewMemoryStore(), ttl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	keys := []config.APIKey{
+		{Key: "shop-key", Plan: config.PlanBasic, ClientID: "shop"},
+		{Key: "other-key", Plan: config.PlanBasic, ClientID: "other"},
+	}
+	h := NewCurrencyHandler(svc)
+	api := router.Group("", middleware.APIKeyMiddleware(keys, false))
+	api.GET("/convert", h.Convert)
+	api.POST("/quotes", h.CreateQuote)
+	api.POST("/quotes/:id/execute", h.ExecuteQuote)
+	return router
+}
+
+func doRequest(router *gin.Engine, method, url, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func createQuote(t *testing.T, router *gin.Engine, apiKey string) model.QuoteResponse {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/quotes", apiKey, `{"from":"USD","to":"EUR","amount":100}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var q model.QuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	return q
+}
+
+func TestQuotes_ExecuteOnce(t *testing.T) {
+	router := setupQuoteRouter(t, time.Minute)
+
+	q := createQuote(t, router, "shop-key")
+	assert.NotEmpty(t, q.ID)
+	assert.Equal(t, 0.92, q.Rate)
+	assert.InDelta(t, 92.0, q.Result, 1e-9)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), q.ExpiresAt, 5*time.Second)
+
+	// Чужой клиент котировку не видит
+	w := doRequest(router, http.MethodPost, "/quotes/"+q.ID+"/execute", "other-key", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(router, http.MethodPost, "/quotes/"+q.ID+"/execute", "shop-key", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response model.ConvertResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, q.ID, response.QuoteID)
+	assert.Equal(t, q.Rate, response.Rate)
+	assert.Equal(t, q.Result, response.Result)
+
+	// Повторное исполнение отклоняется
+	w = doRequest(router, http.MethodPost, "/quotes/"+q.ID+"/execute", "shop-key", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"conflict"`)
+}
+
+func TestQuotes_ConvertWithQuoteID(t *testing.T) {
+	router := setupQuoteRouter(t, time.Minute)
+	q := createQuote(t, router, "")
+
+	// Условия не совпадают - 400, котировка не расходуется
+	w := doRequest(router, http.MethodGet, "/convert?quote_id="+q.ID+"&from=USD&to=EUR&amount=200", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(router, http.MethodGet, "/convert?quote_id="+q.ID+"&from=USD&to=EUR&amount=100", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"quote_id":"`+q.ID+`"`)
+
+	w = doRequest(router, http.MethodGet, "/convert?quote_id="+q.ID, "", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestQuotes_Expired(t *testing.T) {
+	router := setupQuoteRouter(t, 50*time.Millisecond)
+	q := createQuote(t, router, "shop-key")
+
+	time.Sleep(100 * time.Millisecond)
+	w := doRequest(router, http.MethodPost, "/quotes/"+q.ID+"/execute", "shop-key", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "expired")
+}
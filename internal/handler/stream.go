@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/metrics"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/stream"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamHandler отдает обновления курсов через Server-Sent Events
+type StreamHandler struct {
+	hub       *stream.Hub
+	keepalive time.Duration
+}
+
+func NewStreamHandler(hub *stream.Hub, keepalive time.Duration) *StreamHandler {
+	return &StreamHandler{hub: hub, keepalive: keepalive}
+}
+
+// Rates - GET /api/v1/stream?base=USD&symbols=EUR,GBP.
+// Событие "rates" приходит после каждого обновления курсов базы;
+// после переподключения с Last-Event-ID досылаются пропущенные события.
+func (h *StreamHandler) Rates(c *gin.Context) {
+	var query model.StreamQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	base := strings.ToUpper(query.Base)
+	symbols, err := parseSymbols(query.Symbols)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	lastEventID := query.LastEventID
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		lastEventID, err = strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			respondError(c, apperror.Validation("Last-Event-ID must be an integer"))
+			return
+		}
+	}
+
+	sub, missed := h.hub.Subscribe(base, symbols, lastEventID)
+	defer sub.Close()
+	metrics.StreamSubscribers.Inc()
+	defer metrics.StreamSubscribers.Dec()
+
+	// Поток живет дольше WriteTimeout сервера
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	for _, event := range missed {
+		writeEvent(c, event)
+	}
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(h.keepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepalive.C:
+			_, _ = c.Writer.WriteString(": keepalive\n\n")
+			c.Writer.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Отстали или хаб закрыл подписку: клиент переподключится с Last-Event-ID
+				return
+			}
+			writeEvent(c, event)
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, event stream.Event) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.ID, 10),
+		Event: "rates",
+		Data:  event.Update,
+	})
+}
+
+// parseSymbols разбирает список валют через запятую
+func parseSymbols(raw string) ([]string, error) {
+	var symbols []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if len(part) != 3 {
+			return nil, apperror.Validation("symbols must be 3-letter currency codes, got " + strconv.Quote(part))
+		}
+		symbols = append(symbols, part)
+	}
+	return symbols, nil
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupStreamServer(t *testing.T, hub *stream.Hub, keepalive time.Duration) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", NewStreamHandler(hub, keepalive).Rates)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readFrame читает одно SSE-сообщение до пустой строки
+func readFrame(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	var frame strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return frame.String()
+		}
+		frame.WriteString(line)
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestStream_PushesRateUpdates(t *testing.T) {
+	hub := stream.NewHub(nil, zap.NewNop())
+	server := setupStreamServer(t, hub, time.Hour)
+
+	resp, reader := openStream(t, server.URL+"/stream?base=usd&symbols=EUR,GBP", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return len(hub.Pairs(context.Background())) == 2 },
+		time.Second, 10*time.Millisecond)
+	hub.Publish(context.Background(), refresher.Update{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": 0.92, "JPY": 150},
+	})
+
+	frame := readFrame(t, reader)
+	assert.Contains(t, frame, "event:rates")
+	assert.Contains(t, frame, `"EUR":0.92`)
+	assert.NotContains(t, frame, "JPY")
+	assert.Contains(t, frame, "id:")
+}
+
+func TestStream_ResumeAndKeepalive(t *testing.T) {
+	hub := stream.NewHub(nil, zap.NewNop())
+	server := setupStreamServer(t, hub, 50*time.Millisecond)
+
+	sub, _ := hub.Subscribe("USD", nil, 0)
+	hub.Publish(context.Background(), refresher.Update{Base: "USD", Rates: map[string]float64{"EUR": 0.91}})
+	first := <-sub.Events()
+	sub.Close()
+	hub.Publish(context.Background(), refresher.Update{Base: "USD", Rates: map[string]float64{"EUR": 0.95}})
+
+	_, reader := openStream(t, server.URL+"/stream?base=USD", strconv.FormatInt(first.ID, 10))
+	frame := readFrame(t, reader)
+	assert.Contains(t, frame, `"EUR":0.95`, "пропущенное событие досылается")
+	assert.Equal(t, ": keepalive\n", readFrame(t, reader))
+}
+
+func TestStream_InvalidSymbols(t *testing.T) {
+	server := setupStreamServer(t, stream.NewHub(nil, zap.NewNop()), time.Hour)
+
+	resp, err := http.Get(server.URL + "/stream?base=USD&symbols=EURO")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/metrics"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = wsPongTimeout * 9 / 10
+	wsMaxMessage   = 4 << 10
+	// wsMaxPairs - сколько пар может держать одно соединение
+	wsMaxPairs = 50
+	// wsMaxConversions - конвертаций одного соединения одновременно
+	wsMaxConversions = 4
+	// wsQueueSize - ответы, ждущие отправки; переполнение - клиент не читает
+	wsQueueSize = 32
+)
+
+// WSHandler - двунаправленный канал: подписки на курсы и разовые конвертации
+type WSHandler struct {
+	currencyService service.CurrencyServiceInterface
+	hub             *stream.Hub
+	logger          *zap.Logger
+	upgrader        websocket.Upgrader
+}
+
+func NewWSHandler(currencyService service.CurrencyServiceInterface, hub *stream.Hub, logger *zap.Logger) *WSHandler {
+	return &WSHandler{
+		currencyService: currencyService,
+		hub:             hub,
+		logger:          logger,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Ключ передается явно, а не cookie, поэтому Origin не проверяем (как и CORS для REST)
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+// Serve - GET /api/v1/ws. Клиент определяется по API-ключу до upgrade.
+func (h *WSHandler) Serve(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту ошибкой
+		return
+	}
+	metrics.WSConnections.Inc()
+	defer metrics.WSConnections.Dec()
+
+	session := &wsSession{
+		handler: h,
+		conn:    conn,
+		sub:     h.hub.SubscribePairs(),
+		pairs:   make(map[refresher.Pair]bool),
+		out:     make(chan model.WSResponse, wsQueueSize),
+		slots:   make(chan struct{}, wsMaxConversions),
+		logger:  logging.FromContext(c.Request.Context(), h.logger),
+	}
+	defer session.sub.Close()
+	session.run(c.Request.Context())
+}
+
+// wsSession - одно соединение. Читает один goroutine, пишет другой.
+type wsSession struct {
+	handler *WSHandler
+	conn    *websocket.Conn
+	sub     *stream.Subscription
+	pairs   map[refresher.Pair]bool // только в читающем goroutine
+	out     chan model.WSResponse
+	slots   chan struct{}
+	logger  *zap.Logger
+}
+
+func (s *wsSession) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		// Писатель выходит первым при медленном клиенте: чтение прерываем закрытием соединения
+		defer s.conn.Close()
+		defer cancel()
+		s.write(ctx)
+	}()
+
+	s.read(ctx)
+	cancel()
+	<-writerDone
+}
+
+// read обрабатывает сообщения клиента до ошибки чтения
+func (s *wsSession) read(ctx context.Context) {
+	s.conn.SetReadLimit(wsMaxMessage)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		var req model.WSRequest
+		if err := s.conn.ReadJSON(&req); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				s.send(ctx, wsError("", apperror.Validation("message must be a JSON object")))
+				continue
+			}
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		if !s.handle(ctx, req) {
+			return
+		}
+	}
+}
+
+// handle выполняет одно сообщение; false - соединение закрывается
+func (s *wsSession) handle(ctx context.Context, req model.WSRequest) bool {
+	switch req.Type {
+	case model.WSPing:
+		return s.send(ctx, model.WSResponse{Type: model.WSPong, ID: req.ID})
+
+	case model.WSSubscribe, model.WSUnsubscribe:
+		pairs, err := parsePairs(req.Pairs)
+		if err != nil {
+			return s.send(ctx, wsError(req.ID, err))
+		}
+		next := make(map[refresher.Pair]bool, len(s.pairs))
+		for pair := range s.pairs {
+			next[pair] = true
+		}
+		for _, pair := range pairs {
+			next[pair] = req.Type == model.WSSubscribe
+			if !next[pair] {
+				delete(next, pair)
+			}
+		}
+		if len(next) > wsMaxPairs {
+			return s.send(ctx, wsError(req.ID, apperror.Validation("too many subscribed pairs")))
+		}
+		s.pairs = next
+		list := make([]refresher.Pair, 0, len(next))
+		names := make([]string, 0, len(next))
+		for pair := range next {
+			list = append(list, pair)
+			names = append(names, pair.String())
+		}
+		sort.Strings(names)
+		s.sub.SetPairs(list)
+		return s.send(ctx, model.WSResponse{Type: model.WSAck, ID: req.ID, Pairs: names})
+
+	case model.WSConvert:
+		convert := model.WSConvertRequest{From: req.From, To: req.To, Amount: req.Amount}
+		if err := binding.Validator.ValidateStruct(&convert); err != nil {
+			return s.send(ctx, model.WSResponse{Type: model.WSError, ID: req.ID, Error: &model.ErrorResponse{
+				Error:   apperror.KindValidation.Title(),
+				Code:    string(apperror.KindValidation),
+				Details: err.Error(),
+			}})
+		}
+		// Конвертация может ждать upstream, чтение и пинги при этом не останавливаются
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+		go func() {
+			defer func() { <-s.slots }()
+			result, err := s.handler.currencyService.Convert(ctx, convert.From, convert.To, convert.Amount)
+			if err != nil {
+				s.send(ctx, wsError(req.ID, err))
+				return
+			}
+			response := convertResponse(result)
+			s.send(ctx, model.WSResponse{Type: model.WSConversion, ID: req.ID, Conversion: &response})
+		}()
+		return true
+	}
+	return s.send(ctx, wsError(req.ID, apperror.Validation("unknown message type "+strconv.Quote(req.Type))))
+}
+
+// send ставит ответ в очередь. Переполненная очередь значит, что клиент не читает:
+// такое соединение закрывается, а не копит память.
+func (s *wsSession) send(ctx context.Context, msg model.WSResponse) bool {
+	select {
+	case s.out <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	default:
+		s.logger.Warn("WebSocket client is too slow, closing connection")
+		s.closeWith(websocket.CloseTryAgainLater, "slow consumer")
+		return false
+	}
+}
+
+// write отправляет ответы, обновления курсов и пинги
+func (s *wsSession) write(ctx context.Context) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		var msg model.WSResponse
+		select {
+		case <-ctx.Done():
+			s.closeWith(websocket.CloseNormalClosure, "")
+			return
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+			continue
+		case msg = <-s.out:
+		case event, ok := <-s.sub.Events():
+			if !ok {
+				// Хаб отключил подписку: клиент отстал или сервер останавливается
+				s.closeWith(websocket.CloseTryAgainLater, "slow consumer or server shutdown")
+				return
+			}
+			timestamp := event.Update.Timestamp
+			msg = model.WSResponse{
+				Type:      model.WSRates,
+				EventID:   event.ID,
+				Base:      event.Update.Base,
+				Rates:     event.Update.Rates,
+				Timestamp: &timestamp,
+			}
+		}
+
+		_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := s.conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+func (s *wsSession) closeWith(code int, reason string) {
+	_ = s.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	if code != websocket.CloseNormalClosure {
+		_ = s.conn.Close()
+	}
+}
+
+func wsError(id string, err error) model.WSResponse {
+	appErr := apperror.From(err)
+	return model.WSResponse{Type: model.WSError, ID: id, Error: &model.ErrorResponse{
+		Error:   appErr.Kind.Title(),
+		Code:    string(appErr.Kind),
+		Message: appErr.Message,
+	}}
+}
+
+// parsePairs разбирает пары "USD/EUR"
+func parsePairs(raw []string) ([]refresher.Pair, error) {
+	if len(raw) == 0 {
+		return nil, apperror.Validation("pairs must not be empty")
+	}
+	pairs := make([]refresher.Pair, 0, len(raw))
+	for _, s := range raw {
+		pair, ok := refresher.ParsePair(s)
+		if !ok || pair.From == pair.To {
+			return nil, apperror.Validation("invalid pair " + strconv.Quote(s) + ", expected FROM/TO")
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
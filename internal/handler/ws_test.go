@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/middleware"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupWSServer(t *testing.T, hub *stream.Hub) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	keys := []config.APIKey{{Key: "desk-key", Plan: config.PlanPremium, ClientID: "desk"}}
+	router.GET("/ws", middleware.WebSocketAPIKey(), middleware.APIKeyMiddleware(keys, true),
+		NewWSHandler(newOfflineService(t), hub, zap.NewNop()).Serve)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func dialWS(t *testing.T, url string, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) model.WSResponse {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg model.WSResponse
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWS_RequiresAPIKey(t *testing.T) {
+	url := setupWSServer(t, stream.NewHub(nil, zap.NewNop()))
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Браузерам ключ можно передать параметром
+	dialWS(t, url+"?api_key=desk-key", nil)
+}
+
+func TestWS_SubscribeAndReceiveRates(t *testing.T) {
+	hub := stream.NewHub(nil, zap.NewNop())
+	conn := dialWS(t, setupWSServer(t, hub), http.Header{"X-API-Key": {"desk-key"}})
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSSubscribe, ID: "1", Pairs: []string{"usd/eur", "USD/GBP"}}))
+	ack := readWS(t, conn)
+	assert.Equal(t, model.WSAck, ack.Type)
+	assert.Equal(t, "1", ack.ID)
+	assert.Equal(t, []string{"USD/EUR", "USD/GBP"}, ack.Pairs)
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSUnsubscribe, ID: "2", Pairs: []string{"USD/GBP"}}))
+	ack = readWS(t, conn)
+	assert.Equal(t, []string{"USD/EUR"}, ack.Pairs)
+
+	hub.Publish(context.Background(), refresher.Update{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": 0.92, "GBP": 0.79},
+	})
+	rates := readWS(t, conn)
+	assert.Equal(t, model.WSRates, rates.Type)
+	assert.Equal(t, "USD", rates.Base)
+	assert.Equal(t, map[string]float64{"EUR": 0.92}, rates.Rates)
+	assert.NotZero(t, rates.EventID)
+}
+
+func TestWS_ConvertAndErrors(t *testing.T) {
+	conn := dialWS(t, setupWSServer(t, stream.NewHub(nil, zap.NewNop())), http.Header{"X-API-Key": {"desk-key"}})
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSConvert, ID: "c1", From: "USD", To: "EUR", Amount: 100}))
+	msg := readWS(t, conn)
+	require.Equal(t, model.WSConversion, msg.Type, "%+v", msg.Error)
+	assert.Equal(t, "c1", msg.ID)
+	require.NotNil(t, msg.Conversion)
+	assert.Equal(t, "EUR", msg.Conversion.To)
+	assert.Positive(t, msg.Conversion.Result)
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSConvert, ID: "c2", From: "USD", To: "EUR"}))
+	msg = readWS(t, conn)
+	assert.Equal(t, model.WSError, msg.Type)
+	assert.Equal(t, "validation", msg.Error.Code)
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSSubscribe, ID: "s1", Pairs: []string{"USDEUR"}}))
+	msg = readWS(t, conn)
+	assert.Equal(t, "s1", msg.ID)
+	assert.Equal(t, "validation", msg.Error.Code)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{not json")))
+	assert.Equal(t, model.WSError, readWS(t, conn).Type)
+
+	require.NoError(t, conn.WriteJSON(model.WSRequest{Type: model.WSPing, ID: "p"}))
+	assert.Equal(t, model.WSResponse{Type: model.WSPong, ID: "p"}, readWS(t, conn))
+}
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Status - состояние отдельной зависимости или сервиса в целом
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDisabled Status = "disabled"
+
+	StatusReady    Status = "ready"
+	StatusDegraded Status = "degraded"
+	StatusNotReady Status = "not_ready"
+)
+
+// ErrDisabled возвращается проверкой, если зависимость не настроена
+var ErrDisabled = errors.New("dependency is not configured")
+
+// ProbeFunc проверяет зависимость и может вернуть дополнительные сведения для ответа
+type ProbeFunc func(ctx context.Context) (map[string]any, error)
+
+// Check - проверка одной зависимости.
+// Падение критичной проверки переводит сервис в not_ready,
+// некритичной - только в degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	Probe    ProbeFunc
+}
+
+type CheckResult struct {
+	Status    Status         `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMs float64        `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status  Status                 `json:"status"`
+	Version string                 `json:"version"`
+	Commit  string                 `json:"commit"`
+	Checks  map[string]CheckResult `json:"checks"`
+}
+
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	version string
+	commit  string
+}
+
+func NewChecker(timeout time.Duration, version, commit string, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		version: version,
+		commit:  commit,
+	}
+}
+
+// Run выполняет все проверки параллельно, каждую со своим таймаутом
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make(map[string]CheckResult, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := c.runCheck(ctx, check)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	status := StatusReady
+	for _, result := range results {
+		if result.Status != StatusDown {
+			continue
+		}
+		if result.Critical {
+			status = StatusNotReady
+			break
+		}
+		status = StatusDegraded
+	}
+
+	return Report{
+		Status:  status,
+		Version: c.version,
+		Commit:  c.commit,
+		Checks:  results,
+	}
+}
+
+func (c *Checker) runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Probe(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+
+	switch {
+	case errors.Is(err, ErrDisabled):
+		result.Status = StatusDisabled
+	case err != nil:
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
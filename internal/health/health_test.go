@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(err error) ProbeFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, err
+	}
+}
+
+func TestChecker_Run(t *testing.T) {
+	testCases := []struct {
+		name       string
+		checks     []Check
+		wantStatus Status
+	}{
+		{
+			name: "AllUp",
+			checks: []Check{
+				{Name: "redis", Critical: true, Probe: probe(nil)},
+				{Name: "database", Critical: true, Probe: probe(ErrDisabled)},
+			},
+			wantStatus: StatusReady,
+		},
+		{
+			name: "NonCriticalDown",
+			checks: []Check{
+				{Name: "redis", Critical: true, Probe: probe(nil)},
+				{Name: "upstream", Critical: false, Probe: probe(errors.New("stale"))},
+			},
+			wantStatus: StatusDegraded,
+		},
+		{
+			name: "CriticalDown",
+			checks: []Check{
+				{Name: "redis", Critical: true, Probe: probe(errors.New("connection refused"))},
+				{Name: "upstream", Critical: false, Probe: probe(errors.New("stale"))},
+			},
+			wantStatus: StatusNotReady,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := NewChecker(time.Second, "v1.2.3", "abc123", tc.checks...)
+
+			report := checker.Run(context.Background())
+
+			assert.Equal(t, tc.wantStatus, report.Status)
+			assert.Equal(t, "v1.2.3", report.Version)
+			assert.Len(t, report.Checks, len(tc.checks))
+		})
+	}
+}
+
+func TestChecker_Run_Timeout(t *testing.T) {
+	slow := Check{
+		Name:     "redis",
+		Critical: true,
+		Probe: func(ctx context.Context) (map[string]any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	checker := NewChecker(20*time.Millisecond, "dev", "unknown", slow)
+
+	report := checker.Run(context.Background())
+
+	result := report.Checks["redis"]
+	assert.Equal(t, StatusDown, result.Status)
+	assert.Contains(t, result.Error, "deadline exceeded")
+	assert.GreaterOrEqual(t, result.LatencyMs, 20.0)
+}
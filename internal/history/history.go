@@ -0,0 +1,36 @@
+// Package history - история рыночных курсов: каждый курс, полученный у провайдера.
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// Point - курс пары на момент получения у провайдера
+type Point struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Rate     float64   `json:"rate"`
+	Provider string    `json:"provider"`
+	At       time.Time `json:"at"`
+}
+
+// Range - условия выборки истории пары. Пустые границы не ограничивают.
+// Точки отдаются от старых к новым, Until не включается. Если точек больше Limit,
+// без Since возвращаются последние Limit точек, с Since - первые после Since.
+type Range struct {
+	From  string
+	To    string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Store - хранилище истории: только добавление и чтение
+type Store interface {
+	// Append добавляет точки одной записью: либо все, либо ни одной
+	Append(ctx context.Context, points ...Point) error
+	Range(ctx context.Context, r Range) ([]Point, error)
+	// At возвращает последнюю точку не позже t; ok=false, если таких нет
+	At(ctx context.Context, from, to string, t time.Time) (Point, bool, error)
+}
@@ -0,0 +1,205 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PostgresStore хранит историю в таблице rate_history
+type PostgresStore struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS rate_history (
+	id         BIGSERIAL PRIMARY KEY,
+	from_code  CHAR(3) NOT NULL,
+	to_code    CHAR(3) NOT NULL,
+	rate       DOUBLE PRECISION NOT NULL,
+	provider   TEXT NOT NULL DEFAULT '',
+	fetched_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rate_history_pair_idx ON rate_history (from_code, to_code, fetched_at);
+`
+
+// NewPostgresStore создает таблицу, если ее еще нет
+func NewPostgresStore(ctx context.Context, db *sql.DB) (*PostgresStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create rate_history table: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Append вставляет точки одним многострочным INSERT
+func (s *PostgresStore) Append(ctx context.Context, points ...Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(points)*5)
+	values := make([]string, 0, len(points))
+	for _, p := range points {
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, p.From, p.To, p.Rate, p.Provider, p.At)
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO rate_history (from_code, to_code, rate, provider, fetched_at)
+		VALUES `+strings.Join(values, ", "), args...)
+	return err
+}
+
+func (s *PostgresStore) Range(ctx context.Context, r Range) ([]Point, error) {
+	args := []any{r.From, r.To}
+	where := []string{"from_code = $1", "to_code = $2"}
+	if !r.Since.IsZero() {
+		args = append(args, r.Since)
+		where = append(where, fmt.Sprintf("fetched_at >= $%d", len(args)))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, r.Until)
+		where = append(where, fmt.Sprintf("fetched_at < $%d", len(args)))
+	}
+	args = append(args, r.Limit)
+	// Без Since нужны последние точки: берем их с конца и разворачиваем
+	newest := r.Since.IsZero()
+	order := "fetched_at"
+	if newest {
+		order = "fetched_at DESC"
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT from_code, to_code, rate, provider, fetched_at
+		FROM rate_history
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d`, strings.Join(where, " AND "), order, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var p Point
+		if err := rows.Scan(&p.From, &p.To, &p.Rate, &p.Provider, &p.At); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if newest {
+		reverse(points)
+	}
+	return points, nil
+}
+
+func (s *PostgresStore) At(ctx context.Context, from, to string, t time.Time) (Point, bool, error) {
+	var p Point
+	err := s.db.QueryRowContext(ctx, `
+		SELECT from_code, to_code, rate, provider, fetched_at
+		FROM rate_history
+		WHERE from_code = $1 AND to_code = $2 AND fetched_at <= $3
+		ORDER BY fetched_at DESC
+		LIMIT 1`, from, to, t,
+	).Scan(&p.From, &p.To, &p.Rate, &p.Provider, &p.At)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Point{}, false, nil
+	}
+	if err != nil {
+		return Point{}, false, err
+	}
+	return p, true, nil
+}
+
+// memoryLimit - сколько точек на пару держит MemoryStore
+const memoryLimit = 10000
+
+// MemoryStore - история в памяти процесса, когда база не настроена.
+// На каждую пару хранится не больше memoryLimit последних точек.
+type MemoryStore struct {
+	mu     sync.Mutex
+	points map[string][]Point
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{points: make(map[string][]Point)}
+}
+
+func (s *MemoryStore) Append(_ context.Context, points ...Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range points {
+		s.insert(p)
+	}
+	return nil
+}
+
+func (s *MemoryStore) insert(p Point) {
+	key := p.From + "/" + p.To
+	points := s.points[key]
+	// Точки приходят почти по порядку, вставляем с сохранением сортировки
+	i := sort.Search(len(points), func(i int) bool { return points[i].At.After(p.At) })
+	points = append(points, Point{})
+	copy(points[i+1:], points[i:])
+	points[i] = p
+	if len(points) > memoryLimit {
+		points = points[len(points)-memoryLimit:]
+	}
+	s.points[key] = points
+}
+
+func (s *MemoryStore) Range(_ context.Context, r Range) ([]Point, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := s.points[r.From+"/"+r.To]
+	match := func(p Point) bool {
+		return (r.Since.IsZero() || !p.At.Before(r.Since)) && (r.Until.IsZero() || p.At.Before(r.Until))
+	}
+
+	var points []Point
+	if !r.Since.IsZero() {
+		for _, p := range all {
+			if len(points) >= r.Limit {
+				break
+			}
+			if match(p) {
+				points = append(points, p)
+			}
+		}
+		return points, nil
+	}
+	// Без Since нужны последние точки: идем с конца и разворачиваем
+	for i := len(all) - 1; i >= 0 && len(points) < r.Limit; i-- {
+		if match(all[i]) {
+			points = append(points, all[i])
+		}
+	}
+	reverse(points)
+	return points, nil
+}
+
+func (s *MemoryStore) At(_ context.Context, from, to string, t time.Time) (Point, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	points := s.points[from+"/"+to]
+	i := sort.Search(len(points), func(i int) bool { return points[i].At.After(t) })
+	if i == 0 {
+		return Point{}, false, nil
+	}
+	return points[i-1], true, nil
+}
+
+// reverse разворачивает точки, выбранные от новых к старым
+func reverse(points []Point) {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+}
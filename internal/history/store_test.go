@@ -0,0 +1,64 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Точки приходят не по порядку
+	for _, h := range []int{2, 0, 1} {
+		require.NoError(t, store.Append(ctx, Point{From: "USD", To: "EUR", Rate: 0.9 + float64(h)/100, At: base.Add(time.Duration(h) * time.Hour)}))
+	}
+
+	points, err := store.Range(ctx, Range{From: "USD", To: "EUR", Since: base.Add(time.Hour), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, 0.91, points[0].Rate)
+	assert.Equal(t, 0.92, points[1].Rate)
+
+	point, ok, err := store.At(ctx, "USD", "EUR", base.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 0.91, point.Rate)
+
+	_, ok, err = store.At(ctx, "USD", "EUR", base.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_RangeLimitKeepsNewest(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for h := 0; h < 5; h++ {
+		require.NoError(t, store.Append(ctx, Point{From: "USD", To: "EUR", Rate: float64(h), At: base.Add(time.Duration(h) * time.Hour)}))
+	}
+
+	points, err := store.Range(ctx, Range{From: "USD", To: "EUR", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, 3.0, points[0].Rate)
+	assert.Equal(t, 4.0, points[1].Rate)
+
+	// Until отсекает последнюю точку, из оставшихся берутся самые новые
+	points, err = store.Range(ctx, Range{From: "USD", To: "EUR", Until: base.Add(4 * time.Hour), Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, 2.0, points[0].Rate)
+	assert.Equal(t, 3.0, points[1].Rate)
+
+	// С Since выборка идет вперед от Since
+	points, err = store.Range(ctx, Range{From: "USD", To: "EUR", Since: base.Add(time.Hour), Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, 1.0, points[0].Rate)
+	assert.Equal(t, 2.0, points[1].Rate)
+}
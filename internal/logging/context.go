@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext возвращает логгер, дополненный идентификаторами запроса и трейса
+// из контекста. Если их нет, возвращается исходный логгер.
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+// Fields возвращает поля корреляции (request_id, trace_id, span_id) для текущего контекста
+func Fields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if spanCtx.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+	return fields
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// Секреты короче этого не вычищаем, иначе под замену попадут обычные слова и числа
+const minSecretLength = 6
+
+// redactingCore вычищает значения секретов из сообщения и всех полей записи
+// перед тем, как передать ее дальше в настоящий core
+type redactingCore struct {
+	zapcore.Core
+	replacer *strings.Replacer
+}
+
+// NewRedactingCore оборачивает core так, чтобы значения secrets никогда не попадали в лог
+func NewRedactingCore(core zapcore.Core, secrets []string) zapcore.Core {
+	replacer := newReplacer(secrets)
+	if replacer == nil {
+		return core
+	}
+	return &redactingCore{Core: core, replacer: replacer}
+}
+
+// RedactOption - то же самое в виде опции для zap.Logger.WithOptions
+func RedactOption(secrets []string) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewRedactingCore(core, secrets)
+	})
+}
+
+func newReplacer(secrets []string) *strings.Replacer {
+	unique := make(map[string]struct{}, len(secrets))
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			unique[secret] = struct{}{}
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	// Длинные секреты заменяем первыми, если один содержит другой (DSN и пароль из него)
+	sorted := make([]string, 0, len(unique))
+	for secret := range unique {
+		sorted = append(sorted, secret)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	pairs := make([]string, 0, len(sorted)*2)
+	for _, secret := range sorted {
+		pairs = append(pairs, secret, redacted)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), replacer: c.replacer}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.replacer.Replace(entry.Message)
+	entry.Stack = c.replacer.Replace(entry.Stack)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		result[i] = c.redactField(field)
+	}
+	return result
+}
+
+func (c *redactingCore) redactField(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = c.replacer.Replace(field.String)
+		return field
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if data, ok := field.Interface.([]byte); ok {
+			return c.replaceIfChanged(field, string(data))
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return c.replaceIfChanged(field, err.Error())
+		}
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok {
+			return c.replaceIfChanged(field, stringer.String())
+		}
+	case zapcore.ReflectType:
+		if data, err := json.Marshal(field.Interface); err == nil {
+			return c.replaceIfChanged(field, string(data))
+		}
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		encoder := zapcore.NewMapObjectEncoder()
+		field.AddTo(encoder)
+		if data, err := json.Marshal(encoder.Fields); err == nil {
+			return c.replaceIfChanged(field, string(data))
+		}
+	}
+	return field
+}
+
+// replaceIfChanged подменяет поле строкой, только если в нем нашелся секрет,
+// чтобы не терять структуру обычных полей
+func (c *redactingCore) replaceIfChanged(field zapcore.Field, rendered string) zapcore.Field {
+	clean := c.replacer.Replace(rendered)
+	if clean == rendered {
+		return field
+	}
+	return zap.String(field.Key, clean)
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const apiKey = "4cd60470d61ac235ae2e1f77"
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core).WithOptions(RedactOption([]string{apiKey, "abc"}))
+
+	fullURL := "https://v6.exchangerate-api.com/v6/" + apiKey + "/latest/USD"
+	urlErr := &url.Error{Op: "Get", URL: fullURL, Err: errors.New("connection refused")}
+
+	logger.With(zap.String("key", apiKey)).Error("request to "+fullURL+" failed",
+		zap.String("url", fullURL),
+		zap.Error(fmt.Errorf("API request failed: %w", urlErr)),
+		zap.Any("headers", map[string]string{"X-Api-Key": apiKey}),
+		zap.ByteString("body", []byte(`{"key":"`+apiKey+`"}`)),
+		zap.String("short", "abc"),
+	)
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		entry := entries[0]
+		assert.NotContains(t, entry.Message, apiKey)
+		for key, value := range entry.ContextMap() {
+			assert.NotContains(t, fmt.Sprint(value), apiKey, "поле %s содержит ключ", key)
+		}
+		assert.Contains(t, entry.ContextMap()["url"], "[REDACTED]")
+		// Короткие значения не считаются секретами
+		assert.Equal(t, "abc", entry.ContextMap()["short"])
+	}
+}
+
+func TestRedactingCore_NoSecrets(t *testing.T) {
+	core, _ := observer.New(zapcore.InfoLevel)
+	assert.Same(t, core, NewRedactingCore(core, nil))
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "currency_converter"
+
+var (
+	// RedisAvailable - 1, если Redis доступен, 0 - если кеш работает в деградированном режиме
+	RedisAvailable = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "available",
+		Help:      "Whether the Redis cache is currently reachable (1) or degraded (0).",
+	})
+
+	// RedisStateTransitions считает переходы между состояниями connected/degraded
+	RedisStateTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "state_transitions_total",
+		Help:      "Number of Redis availability state transitions by target state.",
+	}, []string{"state"})
+
+	// RedisReconnectAttempts считает неудачные попытки переподключения
+	RedisReconnectAttempts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "reconnect_failures_total",
+		Help:      "Number of failed background Redis reconnection attempts.",
+	})
+
+	// UpstreamCircuitState - состояние circuit breaker провайдера: 0 closed, 1 half-open, 2 open
+	UpstreamCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state per provider (0 closed, 1 half-open, 2 open).",
+	}, []string{"provider"})
+
+	// UpstreamRetries считает повторные запросы к провайдеру по причине
+	UpstreamRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "retries_total",
+		Help:      "Number of retried upstream requests by provider and reason.",
+	}, []string{"provider", "reason"})
+
+	// UpstreamQuotaUsed - запросов к провайдеру за текущий расчетный период
+	UpstreamQuotaUsed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "quota_used",
+		Help:      "Upstream requests made in the current billing period per provider.",
+	}, []string{"provider"})
+
+	// UpstreamQuotaMode - режим бюджета: 0 normal, 1 conserve, 2 stale_only
+	UpstreamQuotaMode = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "quota_mode",
+		Help:      "Upstream budget mode per provider (0 normal, 1 conserve, 2 stale_only).",
+	}, []string{"provider"})
+
+	// AuditWriteFailures считает конвертации, которые не удалось записать в журнал
+	AuditWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "write_failures_total",
+		Help:      "Number of conversions that could not be written to the audit log.",
+	})
+
+	// AlertWebhooks считает доставку вебхуков оповещений: delivered или dead_letter
+	AlertWebhooks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "alerts",
+		Name:      "webhooks_total",
+		Help:      "Number of alert webhooks by delivery outcome.",
+	}, []string{"outcome"})
+
+	// StreamSubscribers - открытые потоки обновлений курсов
+	StreamSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Number of open rate update streams.",
+	})
+
+	// WSConnections - открытые WebSocket-соединения
+	WSConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "connections",
+		Help:      "Number of open WebSocket connections.",
+	})
+)
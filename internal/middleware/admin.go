@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"currency-converter-v2/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuthMiddleware пропускает к /admin/* только запросы с токеном администратора.
+// Токен передается как "Authorization: Bearer <token>" или в X-Admin-Token.
+// Если токен не настроен, админка выключена целиком.
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			abortWithError(c, apperror.Forbidden("admin API is disabled"))
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Token")
+		if provided == "" {
+			provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			abortWithError(c, apperror.Unauthorized("invalid admin token"))
+			return
+		}
+
+		c.Next()
+	}
+}
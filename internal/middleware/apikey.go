@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// APIKeyMiddleware определяет клиента по заголовку X-API-Key и кладет его в контекст.
+// Неизвестный ключ - всегда 401. Без ключа запрос проходит как auth.Anonymous,
+// если только ключи не обязательны (required).
+func APIKeyMiddleware(keys []config.APIKey, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-API-Key")
+		if provided == "" {
+			if required {
+				abortWithError(c, apperror.Unauthorized("API key is required"))
+				return
+			}
+			c.Request = c.Request.WithContext(auth.WithClient(c.Request.Context(), auth.Anonymous))
+			c.Next()
+			return
+		}
+
+		client, ok := auth.Lookup(keys, provided)
+		if !ok {
+			abortWithError(c, apperror.Unauthorized("invalid API key"))
+			return
+		}
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(
+			attribute.String("client.id", client.ID),
+			attribute.String("client.plan", client.Plan),
+		)
+		c.Request = c.Request.WithContext(auth.WithClient(c.Request.Context(), client))
+		c.Next()
+	}
+}
+
+// WebSocketAPIKey ставится перед APIKeyMiddleware на WebSocket-маршрутах:
+// браузер не может задать заголовок при открытии сокета, поэтому ключ
+// принимается и из параметра api_key. Заголовок X-API-Key приоритетнее.
+func WebSocketAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			if key := c.Query("api_key"); key != "" {
+				c.Request.Header.Set("X-API-Key", key)
+			}
+		}
+		c.Next()
+	}
+}
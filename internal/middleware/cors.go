@@ -19,7 +19,11 @@ func CORSMiddleware() gin.HandlerFunc {
 		// Разрешаем заголовки
 		c.Writer.Header().Set("Access-Control-Allow-Headers",
 			"Origin, Content-Type, Content-Length, Accept-Encoding, "+
-				"X-CSRF-Token, Authorization, Accept, X-API-Key, X-Requested-With")
+				"X-CSRF-Token, Authorization, Accept, X-API-Key, X-Requested-With, "+
+				"X-Request-ID, X-Admin-Token, traceparent, tracestate")
+
+		// Разрешаем клиенту читать идентификатор запроса
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		// Разрешаем кеширование preflight запросов (OPTIONS)
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// abortWithError прерывает цепочку и отдает ошибку в общем формате
+func abortWithError(c *gin.Context, appErr *apperror.Error) {
+	c.AbortWithStatusJSON(appErr.Kind.HTTPStatus(), model.ErrorResponse{
+		Error:     appErr.Kind.Title(),
+		Code:      string(appErr.Kind),
+		Message:   appErr.Message,
+		RequestID: logging.RequestID(c.Request.Context()),
+	})
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"time"
 
+	"currency-converter-v2/internal/logging"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -13,7 +15,7 @@ func LoggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		path := c.Request.URL.Path
 		c.Next()
 		latency := time.Since(start)
-		logger.Info("HTTP Request",
+		logging.FromContext(c.Request.Context(), logger).Info("HTTP Request",
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
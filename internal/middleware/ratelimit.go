@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimitMiddleware ограничивает число запросов клиента за период его тарифа.
+// Ставится после APIKeyMiddleware; анонимные клиенты считаются по IP.
+// Тарифы читаются из plans на каждом запросе, поэтому новые лимиты после
+// перезагрузки конфигурации действуют сразу. Счетчики общие для всех реплик
+// и хранятся в Redis; пока он недоступен, счет ведется локально в процессе.
+func RateLimitMiddleware(plans func() config.RateLimitConfig, redisClient *cache.RedisClient, logger *zap.Logger) gin.HandlerFunc {
+	limiter := &rateLimiter{redis: redisClient, local: make(map[string]localCount)}
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		client := auth.ClientFrom(ctx)
+		id := client.ID
+		if client == auth.Anonymous {
+			id += ":" + c.ClientIP()
+		}
+		plan := planLimit(plans(), client.Plan)
+
+		now := time.Now()
+		window := now.Truncate(plan.Period)
+		reset := window.Add(plan.Period)
+		key := fmt.Sprintf("ratelimit:%s:%d:%d", id, int64(plan.Period/time.Second), window.Unix())
+
+		used, err := limiter.increment(ctx, key, reset.Sub(now))
+		if err != nil {
+			logging.FromContext(ctx, logger).Warn("Failed to count request for rate limit, using local counter",
+				zap.String("client_id", client.ID),
+				zap.Error(err),
+			)
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(plan.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(max(int64(plan.Requests)-used, 0), 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if used > int64(plan.Requests) {
+			c.Header("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds()+1)))
+			abortWithError(c, apperror.RateLimited(fmt.Sprintf("rate limit of %d requests per %s exceeded", plan.Requests, plan.Period)))
+			return
+		}
+		c.Next()
+	}
+}
+
+// planLimit - лимит тарифа; неизвестный тариф ограничивается как free
+func planLimit(cfg config.RateLimitConfig, plan string) config.RateLimitPlan {
+	switch plan {
+	case config.PlanBasic:
+		return cfg.Basic
+	case config.PlanPremium:
+		return cfg.Premium
+	default:
+		return cfg.Free
+	}
+}
+
+// rateLimiter ведет счетчики окон в Redis и локальную копию на случай его недоступности
+type rateLimiter struct {
+	redis *cache.RedisClient
+
+	mu        sync.Mutex
+	local     map[string]localCount
+	nextSweep time.Time
+}
+
+type localCount struct {
+	used    int64
+	expires time.Time
+}
+
+// increment учитывает запрос в окне key и возвращает число запросов в нем.
+// Ошибка возвращается только для сбоев Redis, отличных от недоступности;
+// результат и в этом случае - локальный счет.
+func (l *rateLimiter) increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+	l.mu.Lock()
+	if now.After(l.nextSweep) {
+		for k, count := range l.local {
+			if now.After(count.expires) {
+				delete(l.local, k)
+			}
+		}
+		l.nextSweep = now.Add(time.Minute)
+	}
+	count := l.local[key]
+	count.used++
+	count.expires = now.Add(ttl)
+	l.local[key] = count
+	l.mu.Unlock()
+
+	used, err := l.redis.IncrementCounter(ctx, key, ttl)
+	if err != nil {
+		if errors.Is(err, cache.ErrUnavailable) {
+			err = nil
+		}
+		return count.used, err
+	}
+	return used, nil
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
-	"net/http"
+	"fmt"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -10,20 +13,17 @@ import (
 // RecoveryMiddleware создает middleware для перехвата паник
 func RecoveryMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			// Логируем панику
-			logger.Error("Panic recovered",
-				zap.String("panic", err),
-				zap.String("path", c.Request.URL.Path),
-				zap.String("method", c.Request.Method),
-				zap.String("client_ip", c.ClientIP()),
-			)
-		}
+		ctx := c.Request.Context()
+
+		// Логируем панику
+		logging.FromContext(ctx, logger).Error("Panic recovered",
+			zap.String("panic", fmt.Sprint(recovered)),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("method", c.Request.Method),
+			zap.String("client_ip", c.ClientIP()),
+		)
 
-		// Отправляем клиенту ошибку 500
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Internal Server Error",
-			"message": "Something went wrong",
-		})
+		// Отправляем клиенту ошибку 500 в общем формате
+		abortWithError(c, apperror.Internal(nil))
 	})
 }
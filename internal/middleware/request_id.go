@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"currency-converter-v2/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader - заголовок, через который передается идентификатор запроса
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware принимает X-Request-ID от клиента или генерирует новый.
+// Идентификатор возвращается в ответе и кладется в контекст запроса,
+// откуда его берут логгер и обработчик ошибок.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// validRequestID отсекает пустые, слишком длинные и небезопасные для логов значения
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
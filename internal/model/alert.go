@@ -0,0 +1,15 @@
+package model
+
+// AlertRequest - правило оповещения: above/below - порог курса, change_pct - изменение в % за сутки
+type AlertRequest struct {
+	Kind       string  `json:"kind" binding:"required,oneof=above below change_pct"`
+	From       string  `json:"from" binding:"required,len=3"`
+	To         string  `json:"to" binding:"required,len=3"`
+	Threshold  float64 `json:"threshold" binding:"required,gt=0"`
+	WebhookURL string  `json:"webhook_url" binding:"required,url"`
+}
+
+// DeadLettersQuery - параметры GET /api/v1/alerts/dead-letters
+type DeadLettersQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
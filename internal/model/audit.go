@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ConversionsQuery - фильтры /admin/conversions. Pair ("USD/EUR") - сокращение для From и To.
+// Cursor - next_cursor из предыдущего ответа.
+type ConversionsQuery struct {
+	ClientID string    `form:"client_id"`
+	Pair     string    `form:"pair" binding:"omitempty,len=7"`
+	From     string    `form:"from" binding:"omitempty,len=3"`
+	To       string    `form:"to" binding:"omitempty,len=3"`
+	Since    time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until    time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int       `form:"limit" binding:"omitempty,min=1,max=1000"`
+	Cursor   int64     `form:"cursor" binding:"omitempty,min=1"`
+	Format   string    `form:"format" binding:"omitempty,oneof=json csv"`
+}
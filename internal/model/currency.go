@@ -1,24 +1,81 @@
 package model
 
-// ConvertRequest - запрос на конвертацию
+import "time"
+
+// ConvertRequest - запрос на конвертацию.
+// С quote_id конвертация идет по курсу котировки, а from/to/amount, если заданы, должны с ней совпадать.
+// Несколько валют назначения: to=EUR,GBP,JPY или targets=EUR&targets=GBP, ответ - MultiConvertResponse.
+// С amount_in=target amount - сумма, которую нужно получить в валюте to, а подбирается сумма from.
 type ConvertRequest struct {
-	From   string  `form:"from" binding:"required,len=3"` // form вместо json!
-	To     string  `form:"to" binding:"required,len=3"`
-	Amount float64 `form:"amount" binding:"required,min=0.01"`
+	From     string   `form:"from" binding:"required_without=QuoteID,omitempty,len=3"` // form вместо json!
+	To       string   `form:"to" binding:"required_without_all=QuoteID Targets"`
+	Targets  []string `form:"targets"`
+	Amount   float64  `form:"amount" binding:"required_without=QuoteID,omitempty,min=0.01"`
+	AmountIn string   `form:"amount_in" binding:"omitempty,oneof=source target"`
+	QuoteID  string   `form:"quote_id"`
 }
 
+// В какой валюте задана сумма запроса на конвертацию
+const (
+	AmountInSource = "source" // amount списывается в валюте from (по умолчанию)
+	AmountInTarget = "target" // amount должна получиться в валюте to
+)
+
 // ConvertResponse - ответ на конвертацию
 type ConvertResponse struct {
 	From   string  `json:"from"`
 	To     string  `json:"to"`
 	Amount float64 `json:"amount"`
-	Rate   float64 `json:"rate"`
+	Rate   float64 `json:"rate"` // курс после наценки
 	Result float64 `json:"result"`
+
+	MidRate   float64 `json:"mid_rate"`   // средний курс до наценки
+	MarkupPct float64 `json:"markup_pct"` // спред, %
+	Fee       float64 `json:"fee"`        // комиссия в валюте from, вычитается из amount
+	// Target - запрошенная сумма в валюте to при amount_in=target; result не меньше нее
+	Target float64 `json:"target,omitempty"`
+
+	// Происхождение курса: source - cache, upstream, override или derived;
+	// cache_age - сколько секунд курс пролежал в кеше; stale - курс с истекшим TTL
+	RateTimestamp *time.Time `json:"rate_timestamp,omitempty"`
+	Source        string     `json:"source"`
+	Provider      string     `json:"provider,omitempty"`
+	CacheAge      float64    `json:"cache_age"`
+	Stale         bool       `json:"stale"`
+
+	// Override заполняется, если вместо рыночного курса применен ручной
+	Override *AppliedOverride `json:"override,omitempty"`
+	// QuoteID заполняется при конвертации по котировке
+	QuoteID string `json:"quote_id,omitempty"`
+}
+
+// MultiConvertResponse - конвертация одной суммы в несколько валют, results - по валюте назначения
+type MultiConvertResponse struct {
+	From    string                      `json:"from"`
+	Amount  float64                     `json:"amount"`
+	Results map[string]TargetConversion `json:"results"`
+}
+
+// TargetConversion - результат по одной валюте: conversion или error
+type TargetConversion struct {
+	Conversion *ConvertResponse `json:"conversion,omitempty"`
+	Error      *ErrorResponse   `json:"error,omitempty"`
+}
+
+// AppliedOverride - ручной курс (fixed или peg), примененный при конвертации
+type AppliedOverride struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
 }
 
-// ErrorResponse - структура для ошибок
+// ErrorResponse - структура для ошибок.
+// Code - стабильный машинный код (validation, unsupported_currency, ...),
+// Details заполняется только для ошибок валидации входных данных.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Details string `json:"details,omitempty"`
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Message   string `json:"message,omitempty"`
+	Details   string `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
@@ -0,0 +1,8 @@
+package model
+
+// GraphQLRequest - тело POST /graphql
+type GraphQLRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
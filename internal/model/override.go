@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// CreateOverrideRequest - запрос на создание ручного курса.
+// fixed: курс пары From->To; peg: 1 From = Rate To, остальные курсы From выводятся через To.
+type CreateOverrideRequest struct {
+	Kind       string     `json:"kind" binding:"required,oneof=fixed peg"`
+	From       string     `json:"from" binding:"required,len=3"`
+	To         string     `json:"to" binding:"required,len=3"`
+	Rate       float64    `json:"rate" binding:"required,gt=0"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	Comment    string     `json:"comment"`
+}
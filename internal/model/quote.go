@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// CreateQuoteRequest - запрос котировки: курс фиксируется на QUOTE_TTL
+type CreateQuoteRequest struct {
+	From   string  `json:"from" binding:"required,len=3"`
+	To     string  `json:"to" binding:"required,len=3"`
+	Amount float64 `json:"amount" binding:"required,min=0.01"`
+}
+
+// QuoteResponse - котировка. ID передается в /quotes/{id}/execute или в convert?quote_id=
+type QuoteResponse struct {
+	ID        string    `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    float64   `json:"amount"`
+	Rate      float64   `json:"rate"`
+	MidRate   float64   `json:"mid_rate"`
+	MarkupPct float64   `json:"markup_pct"`
+	Fee       float64   `json:"fee"`
+	Result    float64   `json:"result"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
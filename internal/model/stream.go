@@ -0,0 +1,9 @@
+package model
+
+// StreamQuery - параметры GET /api/v1/stream
+type StreamQuery struct {
+	Base    string `form:"base" binding:"required,len=3"`
+	Symbols string `form:"symbols"` // через запятую, пусто - все обновляемые курсы базы
+	// LastEventID - альтернатива заголовку Last-Event-ID для клиентов, которые не умеют его ставить
+	LastEventID int64 `form:"last_event_id" binding:"omitempty,min=0"`
+}
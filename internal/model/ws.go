@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Типы сообщений WebSocket (/api/v1/ws)
+const (
+	WSSubscribe   = "subscribe"   // клиент: добавить пары
+	WSUnsubscribe = "unsubscribe" // клиент: убрать пары
+	WSConvert     = "convert"     // клиент: разовая конвертация
+	WSPing        = "ping"        // клиент: проверка связи
+
+	WSAck        = "ack"        // сервер: подписка изменена, pairs - текущий набор
+	WSRates      = "rates"      // сервер: обновление курсов
+	WSConversion = "conversion" // сервер: результат convert
+	WSPong       = "pong"       // сервер: ответ на ping
+	WSError      = "error"      // сервер: ошибка запроса с тем же id
+)
+
+// WSRequest - сообщение клиента. ID возвращается в ответе как есть.
+type WSRequest struct {
+	Type   string   `json:"type"`
+	ID     string   `json:"id,omitempty"`
+	Pairs  []string `json:"pairs,omitempty"` // "USD/EUR", "USD/*" - все курсы базы
+	From   string   `json:"from,omitempty"`
+	To     string   `json:"to,omitempty"`
+	Amount float64  `json:"amount,omitempty"`
+}
+
+// WSConvertRequest - проверка полей convert
+type WSConvertRequest struct {
+	From   string  `binding:"required,len=3"`
+	To     string  `binding:"required,len=3"`
+	Amount float64 `binding:"required,min=0.01"`
+}
+
+// WSResponse - сообщение сервера; заполнены поля, относящиеся к Type
+type WSResponse struct {
+	Type       string             `json:"type"`
+	ID         string             `json:"id,omitempty"`
+	Pairs      []string           `json:"pairs,omitempty"`
+	EventID    int64              `json:"event_id,omitempty"`
+	Base       string             `json:"base,omitempty"`
+	Rates      map[string]float64 `json:"rates,omitempty"`
+	Timestamp  *time.Time         `json:"timestamp,omitempty"`
+	Conversion *ConvertResponse   `json:"conversion,omitempty"`
+	Error      *ErrorResponse     `json:"error,omitempty"`
+}
@@ -0,0 +1,98 @@
+// Package openapi - описание HTTP API в формате OpenAPI 3. Схемы тел запросов и ответов
+// строятся по Go-типам из internal/model и доменных пакетов, пути перечислены в spec.go.
+package openapi
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Document - корень документа OpenAPI
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+	specJSON []byte
+)
+
+// Spec возвращает документ. Строится один раз, менять его нельзя.
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = build()
+		var err error
+		if specJSON, err = json.Marshal(spec); err != nil {
+			panic("openapi: failed to marshal spec: " + err.Error())
+		}
+	})
+	return spec
+}
+
+// JSON - документ, сериализованный для /openapi.json
+func JSON() []byte {
+	Spec()
+	return specJSON
+}
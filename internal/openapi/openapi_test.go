@@ -0,0 +1,38 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"currency-converter-v2/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_RequestUsesBindingRules(t *testing.T) {
+	g := newGenerator(nil)
+	ref := g.request(model.CreateOverrideRequest{})
+	assert.Equal(t, "#/components/schemas/CreateOverrideRequest", ref.Ref)
+
+	s := g.schemas["CreateOverrideRequest"]
+	assert.ElementsMatch(t, []string{"kind", "from", "to", "rate"}, s.Required)
+	assert.Equal(t, []string{"fixed", "peg"}, s.Properties["kind"].Enum)
+	assert.Equal(t, 3, *s.Properties["from"].MinLength)
+	assert.True(t, s.Properties["rate"].ExclusiveMinimum)
+	assert.Equal(t, "date-time", s.Properties["valid_until"].Format)
+	assert.True(t, s.Properties["valid_until"].Nullable)
+}
+
+func TestGenerator_ResponseRequiresNonOmitempty(t *testing.T) {
+	g := newGenerator(nil)
+	g.response(model.ErrorResponse{})
+	assert.Equal(t, []string{"error", "code"}, g.schemas["ErrorResponse"].Required)
+}
+
+func TestSpec_IsValidJSON(t *testing.T) {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(JSON(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	assert.Contains(t, doc["paths"], "/api/v1/alerts/{id}")
+}
@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema - JSON Schema в диалекте OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// generator строит схемы компонентов по Go-типам.
+// Структура становится компонентом с именем типа; для запросов обязательность поля
+// берется из binding:"required", для ответов - обязательны поля без omitempty.
+type generator struct {
+	names   map[reflect.Type]string
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+// newGenerator создает генератор; names переопределяет имена компонентов для отдельных типов
+func newGenerator(names map[reflect.Type]string) *generator {
+	return &generator{names: names, schemas: make(map[string]*Schema), types: make(map[string]reflect.Type)}
+}
+
+// request - ссылка на схему тела запроса
+func (g *generator) request(v any) *Schema {
+	return g.named(reflect.TypeOf(v), true)
+}
+
+// response - ссылка на схему ответа
+func (g *generator) response(v any) *Schema {
+	return g.named(reflect.TypeOf(v), false)
+}
+
+func (g *generator) named(t reflect.Type, request bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := t.Name()
+	if alias, ok := g.names[t]; ok {
+		name = alias
+	}
+	if known, ok := g.types[name]; ok {
+		if known != t {
+			panic(fmt.Sprintf("openapi: schema name %s is used by %s and %s", name, known, t))
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	g.types[name] = t
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.schemas[name] = schema
+	g.fields(schema, t, request)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// fields добавляет в schema поля структуры; встроенные структуры раскрываются, как в encoding/json
+func (g *generator) fields(schema *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, omitempty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct {
+			g.fields(schema, f.Type, request)
+			continue
+		}
+		prop := g.schemaOf(f.Type, request)
+		binding := f.Tag.Get("binding")
+		applyBinding(prop, binding)
+		schema.Properties[name] = prop
+
+		required := !omitempty && f.Type.Kind() != reflect.Pointer
+		if request {
+			required = hasRule(binding, "required")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func (g *generator) schemaOf(t reflect.Type, request bool) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schemaOf(t.Elem(), request)
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem(), request)}
+	case reflect.Struct:
+		return g.named(t, request)
+	}
+	// interface{} - любое значение
+	return &Schema{}
+}
+
+// jsonName разбирает тег json так же, как encoding/json
+func jsonName(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(","+opts+",", ",omitempty,"), false
+}
+
+// applyBinding переносит ограничения validator (len, min, max, gt, oneof, url) в схему
+func applyBinding(s *Schema, binding string) {
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "len":
+			n, _ := strconv.Atoi(value)
+			s.MinLength, s.MaxLength = &n, &n
+		case "min", "gt":
+			n, _ := strconv.ParseFloat(value, 64)
+			if s.Type == "string" {
+				length := int(n)
+				s.MinLength = &length
+			} else {
+				s.Minimum = &n
+				s.ExclusiveMinimum = key == "gt"
+			}
+		case "max":
+			n, _ := strconv.ParseFloat(value, 64)
+			if s.Type == "string" {
+				length := int(n)
+				s.MaxLength = &length
+			} else {
+				s.Maximum = &n
+			}
+		case "oneof":
+			s.Enum = strings.Fields(value)
+		case "url":
+			s.Format = "uri"
+		}
+	}
+}
+
+func hasRule(binding, rule string) bool {
+	for _, r := range strings.Split(binding, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// queryParameters описывает параметры запроса по тегам form
+func (g *generator) queryParameters(v any) []*Parameter {
+	t := reflect.TypeOf(v)
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("form")
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := g.schemaOf(f.Type, true)
+		binding := f.Tag.Get("binding")
+		applyBinding(schema, binding)
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: hasRule(binding, "required"),
+			Schema:   schema,
+		})
+	}
+	return params
+}
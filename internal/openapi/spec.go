@@ -0,0 +1,392 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"currency-converter-v2/internal/alert"
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/audit"
+	"currency-converter-v2/internal/health"
+	"currency-converter-v2/internal/model"
+	"currency-converter-v2/internal/override"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/internal/version"
+)
+
+// Тела ответов, которые хендлеры собирают через gin.H.
+// Совпадение с фактическими ответами проверяют тесты internal/app.
+
+type HealthStatus struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+}
+
+type Liveness struct {
+	Status    string `json:"status"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+type QuotaReport struct {
+	Enabled   bool          `json:"enabled"`
+	Providers []quota.Usage `json:"providers"`
+}
+
+type AlertList struct {
+	Alerts []alert.Rule `json:"alerts"`
+}
+
+type AlertCreated struct {
+	Alert  alert.Rule `json:"alert"`
+	Secret string     `json:"secret"` // ключ подписи вебхуков, показывается один раз
+}
+
+type DeadLetterList struct {
+	DeadLetters []alert.DeadLetter `json:"dead_letters"`
+}
+
+type OverrideList struct {
+	Overrides []override.Override `json:"overrides"`
+}
+
+type ConversionList struct {
+	Conversions []audit.Entry `json:"conversions"`
+	NextCursor  string        `json:"next_cursor,omitempty"`
+}
+
+type GraphQLResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Path       []any             `json:"path,omitempty"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Схемы безопасности
+const (
+	securityAPIKey = "ApiKey"
+	securityAdmin  = "AdminToken"
+)
+
+// builder собирает документ
+type builder struct {
+	g   *generator
+	doc *Document
+}
+
+// schemaNames - имена компонентов для типов, чьи Go-имена без пакета слишком общие
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeOf(alert.Rule{}):         "AlertRule",
+	reflect.TypeOf(alert.DeadLetter{}):   "DeadLetter",
+	reflect.TypeOf(audit.Entry{}):        "AuditEntry",
+	reflect.TypeOf(health.Report{}):      "ReadinessReport",
+	reflect.TypeOf(health.CheckResult{}): "CheckResult",
+	reflect.TypeOf(override.Override{}):  "Override",
+	reflect.TypeOf(quota.Usage{}):        "QuotaUsage",
+}
+
+func build() *Document {
+	b := &builder{
+		g: newGenerator(schemaNames),
+		doc: &Document{
+			OpenAPI: "3.0.3",
+			Info: Info{
+				Title:       "Currency Converter API",
+				Description: "Конвертация валют, котировки, оповещения о курсе и администрирование.",
+				Version:     version.Version,
+			},
+			Tags: []Tag{
+				{Name: "currency", Description: "Конвертация и котировки"},
+				{Name: "stream", Description: "Обновления курсов в реальном времени"},
+				{Name: "alerts", Description: "Оповещения о курсе"},
+				{Name: "graphql"},
+				{Name: "admin", Description: "Администрирование"},
+				{Name: "system", Description: "Служебные эндпоинты"},
+			},
+			Paths: make(map[string]map[string]*Operation),
+		},
+	}
+	b.system()
+	b.currency()
+	b.stream()
+	b.alerts()
+	b.admin()
+
+	b.doc.Components = Components{
+		Schemas: b.g.schemas,
+		SecuritySchemes: map[string]*SecurityScheme{
+			securityAPIKey: {
+				Type: "apiKey", In: "header", Name: "X-API-Key",
+				Description: "Ключ клиента. Без ключа запрос выполняется анонимно, если AUTH_REQUIRED=false.",
+			},
+			securityAdmin: {
+				Type: "http", Scheme: "bearer",
+				Description: "ADMIN_TOKEN; можно передать и в заголовке X-Admin-Token.",
+			},
+		},
+	}
+	return b.doc
+}
+
+func (b *builder) add(method, path string, op *Operation) {
+	// Запросы с ключом клиента проходят лимит тарифа
+	if len(op.Security) > 0 && op.Security[0][securityAPIKey] != nil {
+		code := strconv.Itoa(apperror.KindRateLimited.HTTPStatus())
+		op.Responses[code] = jsonResponse(apperror.KindRateLimited.Title(), b.g.response(model.ErrorResponse{}))
+	}
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = make(map[string]*Operation)
+	}
+	b.doc.Paths[path][strings.ToLower(method)] = op
+}
+
+// jsonBody - обязательное тело запроса в JSON
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{"application/json": {Schema: schema}}}
+}
+
+// responses - успешный ответ и ошибки в общем формате ErrorResponse
+func (b *builder) responses(status int, ok *Response, kinds ...apperror.Kind) map[string]*Response {
+	responses := map[string]*Response{strconv.Itoa(status): ok}
+	errorSchema := b.g.response(model.ErrorResponse{})
+	for _, kind := range append(kinds, apperror.KindInternal) {
+		code := strconv.Itoa(kind.HTTPStatus())
+		if existing, ok := responses[code]; ok {
+			existing.Description += "; " + kind.Title()
+			continue
+		}
+		responses[code] = jsonResponse(kind.Title(), errorSchema)
+	}
+	return responses
+}
+
+func pathID(description string) *Parameter {
+	return &Parameter{Name: "id", In: "path", Required: true, Description: description, Schema: &Schema{Type: "string"}}
+}
+
+var (
+	apiKeySecurity = []map[string][]string{{securityAPIKey: {}}, {}}
+	adminSecurity  = []map[string][]string{{securityAdmin: {}}}
+)
+
+func (b *builder) system() {
+	b.add(http.MethodGet, "/health", &Operation{
+		Tags: []string{"system"}, OperationID: "health",
+		Summary:   "Статус сервиса (аналог /livez, оставлен для совместимости)",
+		Responses: map[string]*Response{"200": jsonResponse("Сервис работает", b.g.response(HealthStatus{}))},
+	})
+	b.add(http.MethodGet, "/livez", &Operation{
+		Tags: []string{"system"}, OperationID: "livez",
+		Summary:   "Процесс жив; зависимости не проверяются",
+		Responses: map[string]*Response{"200": jsonResponse("Процесс жив", b.g.response(Liveness{}))},
+	})
+	report := b.g.response(health.Report{})
+	b.add(http.MethodGet, "/readyz", &Operation{
+		Tags: []string{"system"}, OperationID: "readyz",
+		Summary: "Готовность: проверка Redis, базы и провайдера курсов",
+		Responses: map[string]*Response{
+			"200": jsonResponse("Готов принимать запросы", report),
+			"503": jsonResponse("Упала критичная зависимость", report),
+		},
+	})
+	b.add(http.MethodGet, "/metrics", &Operation{
+		Tags: []string{"system"}, OperationID: "metrics",
+		Summary: "Метрики Prometheus",
+		Responses: map[string]*Response{"200": {
+			Description: "Метрики в текстовом формате",
+			Content:     map[string]*MediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+		}},
+	})
+	b.add(http.MethodGet, "/openapi.json", &Operation{
+		Tags: []string{"system"}, OperationID: "openapi",
+		Summary:   "Этот документ",
+		Responses: map[string]*Response{"200": jsonResponse("Документ OpenAPI 3", &Schema{Type: "object"})},
+	})
+	b.add(http.MethodGet, "/docs", &Operation{
+		Tags: []string{"system"}, OperationID: "docs",
+		Summary: "Swagger UI",
+		Responses: map[string]*Response{"200": {
+			Description: "HTML-страница",
+			Content:     map[string]*MediaType{"text/html": {Schema: &Schema{Type: "string"}}},
+		}},
+	})
+}
+
+func (b *builder) currency() {
+	conversion := jsonResponse("Результат конвертации", b.g.response(model.ConvertResponse{}))
+	b.add(http.MethodGet, "/api/v1/convert", &Operation{
+		Tags: []string{"currency"}, OperationID: "convert", Security: apiKeySecurity,
+		Summary: "Конвертация суммы",
+		Description: "С quote_id конвертация идет по курсу котировки; from, to и amount, если заданы, должны с ней совпадать. " +
+			"Несколько валют назначения (to=EUR,GBP,JPY или targets=EUR&targets=GBP) дают MultiConvertResponse " +
+			"с результатом или ошибкой по каждой валюте. С amount_in=target amount - сумма, которую нужно получить в to: " +
+			"в ответе amount - необходимая сумма from, target - запрошенная, result не меньше target.",
+		Parameters: b.g.queryParameters(model.ConvertRequest{}),
+		Responses: b.responses(http.StatusOK, &Response{
+			Description: "Результат конвертации",
+			Content: map[string]*MediaType{"application/json": {Schema: &Schema{OneOf: []*Schema{
+				b.g.response(model.ConvertResponse{}),
+				b.g.response(model.MultiConvertResponse{}),
+			}}}},
+		},
+			apperror.KindValidation, apperror.KindUnauthorized, apperror.KindNotFound, apperror.KindConflict,
+			apperror.KindUnsupportedCurrency, apperror.KindUpstreamUnavailable),
+	})
+	b.add(http.MethodPost, "/api/v1/quotes", &Operation{
+		Tags: []string{"currency"}, OperationID: "createQuote", Security: apiKeySecurity,
+		Summary:     "Котировка: курс фиксируется на QUOTE_TTL",
+		RequestBody: jsonBody(b.g.request(model.CreateQuoteRequest{})),
+		Responses: b.responses(http.StatusCreated, jsonResponse("Котировка создана", b.g.response(model.QuoteResponse{})),
+			apperror.KindValidation, apperror.KindUnauthorized, apperror.KindUnsupportedCurrency,
+			apperror.KindUpstreamUnavailable),
+	})
+	b.add(http.MethodPost, "/api/v1/quotes/{id}/execute", &Operation{
+		Tags: []string{"currency"}, OperationID: "executeQuote", Security: apiKeySecurity,
+		Summary:    "Исполнение котировки по зафиксированному курсу",
+		Parameters: []*Parameter{pathID("ID котировки")},
+		Responses: b.responses(http.StatusOK, conversion,
+			apperror.KindUnauthorized, apperror.KindNotFound, apperror.KindConflict,
+			apperror.KindUpstreamUnavailable),
+	})
+	b.add(http.MethodPost, "/graphql", &Operation{
+		Tags: []string{"graphql"}, OperationID: "graphql", Security: apiKeySecurity,
+		Summary:     "GraphQL: валюты, курсы, конвертации и история",
+		Description: "Ошибки выполнения запроса приходят в errors со статусом 200, код ошибки - в extensions.code.",
+		RequestBody: jsonBody(b.g.request(model.GraphQLRequest{})),
+		Responses: b.responses(http.StatusOK, jsonResponse("Результат запроса", b.g.response(GraphQLResponse{})),
+			apperror.KindValidation, apperror.KindUnauthorized),
+	})
+}
+
+func (b *builder) stream() {
+	params := b.g.queryParameters(model.StreamQuery{})
+	params = append(params, &Parameter{
+		Name: "Last-Event-ID", In: "header", Schema: &Schema{Type: "integer", Format: "int64"},
+		Description: "ID последнего полученного события; пропущенные события будут досланы",
+	})
+	b.add(http.MethodGet, "/api/v1/stream", &Operation{
+		Tags: []string{"stream"}, OperationID: "streamRates", Security: apiKeySecurity,
+		Summary:    "Обновления курсов через Server-Sent Events",
+		Parameters: params,
+		Responses: b.responses(http.StatusOK, &Response{
+			Description: `Поток событий "rates"; data - {"base","rates","providers","timestamp"}`,
+			Content:     map[string]*MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}},
+		}, apperror.KindValidation, apperror.KindUnauthorized),
+	})
+	b.add(http.MethodGet, "/api/v1/ws", &Operation{
+		Tags: []string{"stream"}, OperationID: "websocket", Security: apiKeySecurity,
+		Summary: "WebSocket: подписка на курсы и конвертации",
+		Description: "Сообщения клиента - WSRequest, сервера - WSResponse. " +
+			"Из браузера ключ можно передать в параметре api_key.",
+		Parameters: []*Parameter{{Name: "api_key", In: "query", Schema: &Schema{Type: "string"},
+			Description: "Ключ клиента, если нельзя передать заголовок X-API-Key"}},
+		Responses: b.responses(http.StatusSwitchingProtocols, &Response{Description: "Соединение переключено на WebSocket"},
+			apperror.KindValidation, apperror.KindUnauthorized),
+	})
+	// Схемы сообщений WebSocket не видны из путей, регистрируем их явно
+	b.g.request(model.WSRequest{})
+	b.g.response(model.WSResponse{})
+}
+
+func (b *builder) alerts() {
+	rule := jsonResponse("Правило", b.g.response(alert.Rule{}))
+	body := jsonBody(b.g.request(model.AlertRequest{}))
+	id := pathID("ID правила")
+	b.add(http.MethodGet, "/api/v1/alerts", &Operation{
+		Tags: []string{"alerts"}, OperationID: "listAlerts", Security: apiKeySecurity,
+		Summary:   "Правила клиента",
+		Responses: b.responses(http.StatusOK, jsonResponse("Правила", b.g.response(AlertList{})), apperror.KindUnauthorized),
+	})
+	b.add(http.MethodPost, "/api/v1/alerts", &Operation{
+		Tags: []string{"alerts"}, OperationID: "createAlert", Security: apiKeySecurity,
+		Summary:     "Новое правило; секрет подписи вебхуков отдается только в этом ответе",
+		RequestBody: body,
+		Responses: b.responses(http.StatusCreated, jsonResponse("Правило создано", b.g.response(AlertCreated{})),
+			apperror.KindValidation, apperror.KindUnauthorized),
+	})
+	b.add(http.MethodGet, "/api/v1/alerts/dead-letters", &Operation{
+		Tags: []string{"alerts"}, OperationID: "listDeadLetters", Security: apiKeySecurity,
+		Summary:    "Вебхуки, которые не удалось доставить",
+		Parameters: b.g.queryParameters(model.DeadLettersQuery{}),
+		Responses: b.responses(http.StatusOK, jsonResponse("Недоставленные вебхуки", b.g.response(DeadLetterList{})),
+			apperror.KindValidation, apperror.KindUnauthorized),
+	})
+	b.add(http.MethodGet, "/api/v1/alerts/{id}", &Operation{
+		Tags: []string{"alerts"}, OperationID: "getAlert", Security: apiKeySecurity,
+		Summary:    "Правило клиента",
+		Parameters: []*Parameter{id},
+		Responses:  b.responses(http.StatusOK, rule, apperror.KindUnauthorized, apperror.KindNotFound),
+	})
+	b.add(http.MethodPut, "/api/v1/alerts/{id}", &Operation{
+		Tags: []string{"alerts"}, OperationID: "updateAlert", Security: apiKeySecurity,
+		Summary:     "Замена условий правила; секрет сохраняется, состояние сбрасывается",
+		Parameters:  []*Parameter{id},
+		RequestBody: body,
+		Responses: b.responses(http.StatusOK, rule,
+			apperror.KindValidation, apperror.KindUnauthorized, apperror.KindNotFound),
+	})
+	b.add(http.MethodDelete, "/api/v1/alerts/{id}", &Operation{
+		Tags: []string{"alerts"}, OperationID: "deleteAlert", Security: apiKeySecurity,
+		Summary:    "Удаление правила",
+		Parameters: []*Parameter{id},
+		Responses: b.responses(http.StatusNoContent, &Response{Description: "Правило удалено"},
+			apperror.KindUnauthorized, apperror.KindNotFound),
+	})
+}
+
+func (b *builder) admin() {
+	b.add(http.MethodGet, "/admin/quota", &Operation{
+		Tags: []string{"admin"}, OperationID: "quota", Security: adminSecurity,
+		Summary: "Расход и остаток бюджета запросов к провайдерам",
+		Responses: b.responses(http.StatusOK, jsonResponse("Бюджеты провайдеров", b.g.response(QuotaReport{})),
+			apperror.KindUnauthorized, apperror.KindForbidden),
+	})
+	b.add(http.MethodGet, "/admin/overrides", &Operation{
+		Tags: []string{"admin"}, OperationID: "listOverrides", Security: adminSecurity,
+		Summary: "Ручные курсы, включая неактивные",
+		Responses: b.responses(http.StatusOK, jsonResponse("Ручные курсы", b.g.response(OverrideList{})),
+			apperror.KindUnauthorized, apperror.KindForbidden),
+	})
+	b.add(http.MethodPost, "/admin/overrides", &Operation{
+		Tags: []string{"admin"}, OperationID: "createOverride", Security: adminSecurity,
+		Summary:     "Новый ручной курс (fixed) или привязка (peg)",
+		RequestBody: jsonBody(b.g.request(model.CreateOverrideRequest{})),
+		Responses: b.responses(http.StatusCreated, jsonResponse("Ручной курс создан", b.g.response(override.Override{})),
+			apperror.KindValidation, apperror.KindUnauthorized, apperror.KindForbidden),
+	})
+	b.add(http.MethodDelete, "/admin/overrides/{id}", &Operation{
+		Tags: []string{"admin"}, OperationID: "deleteOverride", Security: adminSecurity,
+		Summary:    "Удаление ручного курса",
+		Parameters: []*Parameter{pathID("ID ручного курса")},
+		Responses: b.responses(http.StatusNoContent, &Response{Description: "Ручной курс удален"},
+			apperror.KindUnauthorized, apperror.KindForbidden, apperror.KindNotFound),
+	})
+	conversions := jsonResponse("Страница журнала; next_cursor - курсор следующей страницы", b.g.response(ConversionList{}))
+	conversions.Content["text/csv"] = &MediaType{Schema: &Schema{Type: "string"}}
+	b.add(http.MethodGet, "/admin/conversions", &Operation{
+		Tags: []string{"admin"}, OperationID: "listConversions", Security: adminSecurity,
+		Summary:    "Журнал конвертаций от новых к старым; format=csv - выгрузка всей выборки",
+		Parameters: b.g.queryParameters(model.ConversionsQuery{}),
+		Responses: b.responses(http.StatusOK, conversions,
+			apperror.KindValidation, apperror.KindUnauthorized, apperror.KindForbidden),
+	})
+}
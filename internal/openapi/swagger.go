@@ -0,0 +1,8 @@
+package openapi
+
+import _ "embed"
+
+// SwaggerUI - страница Swagger UI для /docs, документ берется с /openapi.json
+//
+//go:embed swagger.html
+var SwaggerUI []byte
@@ -0,0 +1,229 @@
+// Package override - ручные курсы поверх рыночных: зафиксированные финансами
+// пары (fixed) и привязанные к другой валюте валюты (peg).
+package override
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Kind string
+
+const (
+	// KindFixed - курс пары From->To зафиксирован (например, контрактный EUR->USD на квартал)
+	KindFixed Kind = "fixed"
+	// KindPeg - валюта From привязана к To: 1 From = Rate To.
+	// Курсы From к остальным валютам выводятся через курс To.
+	KindPeg Kind = "peg"
+)
+
+// ErrNotFound - override с таким ID нет
+var ErrNotFound = errors.New("override not found")
+
+// ValidationError - override заполнен неверно
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+type Override struct {
+	ID         string     `json:"id"`
+	Kind       Kind       `json:"kind"`
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	Rate       float64    `json:"rate"` // fixed: курс From->To; peg: множитель, 1 From = Rate To
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Active - действует ли override в момент now
+func (o Override) Active(now time.Time) bool {
+	if o.ValidFrom != nil && now.Before(*o.ValidFrom) {
+		return false
+	}
+	if o.ValidUntil != nil && !now.Before(*o.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// Validate проверяет и нормализует override перед сохранением
+func (o *Override) Validate() error {
+	var errs []error
+	o.From, o.To = strings.ToUpper(o.From), strings.ToUpper(o.To)
+	if o.Kind != KindFixed && o.Kind != KindPeg {
+		errs = append(errs, fmt.Errorf("kind must be %q or %q", KindFixed, KindPeg))
+	}
+	if len(o.From) != 3 || len(o.To) != 3 {
+		errs = append(errs, errors.New("currency codes must be 3 characters"))
+	}
+	if o.From == o.To {
+		errs = append(errs, errors.New("from and to must differ"))
+	}
+	if o.Rate <= 0 {
+		errs = append(errs, errors.New("rate must be positive"))
+	}
+	if o.ValidFrom != nil && o.ValidUntil != nil && !o.ValidUntil.After(*o.ValidFrom) {
+		errs = append(errs, errors.New("valid_until must be after valid_from"))
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Err: errors.Join(errs...)}
+	}
+	return nil
+}
+
+// Resolution - как получить курс пары с учетом override.
+// Если ViaFrom пуст, итоговый курс - Rate. Иначе курс равен Rate * рыночный курс ViaFrom->ViaTo.
+type Resolution struct {
+	Override Override
+	Rate     float64
+	ViaFrom  string
+	ViaTo    string
+}
+
+// Store - постоянное хранилище override
+type Store interface {
+	List(ctx context.Context) ([]Override, error)
+	Save(ctx context.Context, o Override) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Manager держит override в памяти для быстрых проверок на каждом запросе
+// и синхронизирует их с хранилищем
+type Manager struct {
+	store  Store
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu    sync.RWMutex
+	items []Override
+}
+
+func NewManager(store Store, logger *zap.Logger) *Manager {
+	return &Manager{store: store, logger: logger, now: time.Now}
+}
+
+// Load перечитывает override из хранилища
+func (m *Manager) Load(ctx context.Context) error {
+	items, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load overrides: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.After(items[j].CreatedAt) })
+
+	m.mu.Lock()
+	m.items = items
+	m.mu.Unlock()
+	return nil
+}
+
+// Refresh периодически перечитывает хранилище, чтобы подхватить изменения с других инстансов
+func (m *Manager) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Load(ctx); err != nil {
+				m.logger.Warn("Failed to refresh overrides, keeping previous set", zap.Error(err))
+			}
+		}
+	}
+}
+
+// List возвращает все override, новые первыми
+func (m *Manager) List() []Override {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Override(nil), m.items...)
+}
+
+// Create проверяет и сохраняет новый override
+func (m *Manager) Create(ctx context.Context, o Override) (Override, error) {
+	if err := o.Validate(); err != nil {
+		return Override{}, err
+	}
+	o.ID = newID()
+	o.CreatedAt = m.now().UTC()
+
+	if err := m.store.Save(ctx, o); err != nil {
+		return Override{}, fmt.Errorf("failed to save override: %w", err)
+	}
+	m.mu.Lock()
+	m.items = append([]Override{o}, m.items...)
+	m.mu.Unlock()
+	return o, nil
+}
+
+// Delete удаляет override
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	if err := m.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, o := range m.items {
+		if o.ID == id {
+			m.items = append(m.items[:i:i], m.items[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Resolve ищет действующий override для пары from->to.
+// Приоритет: override самой пары, затем обратной пары, затем peg одной из валют.
+// Среди равных побеждает более новый.
+func (m *Manager) Resolve(from, to string) (Resolution, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	now := m.now()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var peg *Resolution
+	for _, o := range m.items {
+		if !o.Active(now) {
+			continue
+		}
+		switch {
+		case o.From == from && o.To == to:
+			return Resolution{Override: o, Rate: o.Rate}, true
+		case o.From == to && o.To == from:
+			return Resolution{Override: o, Rate: 1 / o.Rate}, true
+		case peg != nil || o.Kind != KindPeg:
+			continue
+		case o.From == from:
+			// 1 from = Rate anchor, дальше по рынку anchor->to
+			peg = &Resolution{Override: o, Rate: o.Rate, ViaFrom: o.To, ViaTo: to}
+		case o.From == to:
+			// from->anchor по рынку, 1 anchor = 1/Rate to
+			peg = &Resolution{Override: o, Rate: 1 / o.Rate, ViaFrom: from, ViaTo: o.To}
+		}
+	}
+	if peg != nil {
+		return *peg, true
+	}
+	return Resolution{}, false
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
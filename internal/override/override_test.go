@@ -0,0 +1,108 @@
+package override
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestManager(t *testing.T, now time.Time) *Manager {
+	t.Helper()
+	m := NewManager(NewMemoryStore(), zap.NewNop())
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestManager_ResolveFixed(t *testing.T) {
+	now := time.Date(2026, 4, 15, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(t, now)
+
+	quarterEnd := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	created, err := m.Create(context.Background(), Override{
+		Kind: KindFixed, From: "eur", To: "usd", Rate: 1.25, ValidUntil: &quarterEnd,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "EUR", created.From, "коды нормализуются")
+
+	res, ok := m.Resolve("EUR", "USD")
+	require.True(t, ok)
+	assert.Equal(t, 1.25, res.Rate)
+	assert.Empty(t, res.ViaFrom)
+
+	res, ok = m.Resolve("USD", "EUR")
+	require.True(t, ok)
+	assert.Equal(t, 0.8, res.Rate, "обратная пара")
+
+	_, ok = m.Resolve("EUR", "GBP")
+	assert.False(t, ok, "fixed не распространяется на другие пары")
+
+	// После окончания квартала действует рыночный курс
+	m.now = func() time.Time { return quarterEnd }
+	_, ok = m.Resolve("EUR", "USD")
+	assert.False(t, ok)
+}
+
+func TestManager_ResolvePeg(t *testing.T) {
+	m := newTestManager(t, time.Now())
+	_, err := m.Create(context.Background(), Override{Kind: KindPeg, From: "AED", To: "USD", Rate: 0.2723})
+	require.NoError(t, err)
+
+	// AED->EUR = 0.2723 * USD->EUR
+	res, ok := m.Resolve("AED", "EUR")
+	require.True(t, ok)
+	assert.Equal(t, 0.2723, res.Rate)
+	assert.Equal(t, "USD", res.ViaFrom)
+	assert.Equal(t, "EUR", res.ViaTo)
+
+	// EUR->AED = EUR->USD / 0.2723
+	res, ok = m.Resolve("EUR", "AED")
+	require.True(t, ok)
+	assert.InDelta(t, 1/0.2723, res.Rate, 1e-12)
+	assert.Equal(t, "EUR", res.ViaFrom)
+	assert.Equal(t, "USD", res.ViaTo)
+
+	// Сама пара привязки
+	res, ok = m.Resolve("USD", "AED")
+	require.True(t, ok)
+	assert.Empty(t, res.ViaFrom)
+}
+
+func TestManager_FixedWinsOverPeg(t *testing.T) {
+	m := newTestManager(t, time.Now())
+	ctx := context.Background()
+	_, err := m.Create(ctx, Override{Kind: KindPeg, From: "AED", To: "USD", Rate: 0.2723})
+	require.NoError(t, err)
+	fixed, err := m.Create(ctx, Override{Kind: KindFixed, From: "AED", To: "EUR", Rate: 0.25})
+	require.NoError(t, err)
+
+	res, ok := m.Resolve("AED", "EUR")
+	require.True(t, ok)
+	assert.Equal(t, fixed.ID, res.Override.ID)
+
+	require.NoError(t, m.Delete(ctx, fixed.ID))
+	res, ok = m.Resolve("AED", "EUR")
+	require.True(t, ok)
+	assert.Equal(t, KindPeg, res.Override.Kind)
+
+	assert.ErrorIs(t, m.Delete(ctx, fixed.ID), ErrNotFound)
+}
+
+func TestOverride_Validate(t *testing.T) {
+	from := time.Now()
+	until := from.Add(-time.Hour)
+	_, err := newTestManager(t, time.Now()).Create(context.Background(), Override{
+		Kind: "manual", From: "USD", To: "USD", Rate: 0, ValidFrom: &from, ValidUntil: &until,
+	})
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ErrorContains(t, err, "kind must be")
+	assert.ErrorContains(t, err, "from and to must differ")
+	assert.ErrorContains(t, err, "rate must be positive")
+	assert.ErrorContains(t, err, "valid_until must be after valid_from")
+}
@@ -0,0 +1,121 @@
+package override
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// PostgresStore хранит override в таблице rate_overrides
+type PostgresStore struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS rate_overrides (
+	id          TEXT PRIMARY KEY,
+	kind        TEXT NOT NULL,
+	from_code   CHAR(3) NOT NULL,
+	to_code     CHAR(3) NOT NULL,
+	rate        DOUBLE PRECISION NOT NULL,
+	valid_from  TIMESTAMPTZ,
+	valid_until TIMESTAMPTZ,
+	comment     TEXT NOT NULL DEFAULT '',
+	created_at  TIMESTAMPTZ NOT NULL
+)`
+
+// NewPostgresStore создает таблицу, если ее еще нет
+func NewPostgresStore(ctx context.Context, db *sql.DB) (*PostgresStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create rate_overrides table: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) List(ctx context.Context) ([]Override, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, kind, from_code, to_code, rate, valid_from, valid_until, comment, created_at
+		FROM rate_overrides`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []Override
+	for rows.Next() {
+		var (
+			o                     Override
+			validFrom, validUntil sql.NullTime
+		)
+		if err := rows.Scan(&o.ID, &o.Kind, &o.From, &o.To, &o.Rate,
+			&validFrom, &validUntil, &o.Comment, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		if validFrom.Valid {
+			o.ValidFrom = &validFrom.Time
+		}
+		if validUntil.Valid {
+			o.ValidUntil = &validUntil.Time
+		}
+		items = append(items, o)
+	}
+	return items, rows.Err()
+}
+
+func (s *PostgresStore) Save(ctx context.Context, o Override) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO rate_overrides (id, kind, from_code, to_code, rate, valid_from, valid_until, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		o.ID, o.Kind, o.From, o.To, o.Rate, o.ValidFrom, o.ValidUntil, o.Comment, o.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rate_overrides WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemoryStore - хранилище в памяти процесса, когда база не настроена.
+// Override теряются при рестарте.
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]Override
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]Override)}
+}
+
+func (s *MemoryStore) List(context.Context) ([]Override, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]Override, 0, len(s.items))
+	for _, o := range s.items {
+		items = append(items, o)
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, o Override) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[o.ID] = o
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.items, id)
+	return nil
+}
@@ -0,0 +1,211 @@
+// Package pricing считает наценку (спред) к среднему курсу и комиссию за конвертацию.
+package pricing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"currency-converter-v2/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule - наценка и комиссия. Проценты задаются в процентах: 0.5 = 0.5%.
+//
+// Спред зависит от направления относительно базовой валюты правила:
+// BuyPct - клиент покупает базовую валюту, SellPct - продает ее.
+type Rule struct {
+	BuyPct   float64 `yaml:"buy_pct"`
+	SellPct  float64 `yaml:"sell_pct"`
+	FeePct   float64 `yaml:"fee_pct"`
+	FeeFixed float64 `yaml:"fee_fixed"` // в валюте списания
+}
+
+// UnmarshalYAML поддерживает сокращение spread_pct: одинаковый спред в обе стороны
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	// node.Decode не наследует KnownFields декодера, поля проверяем сами
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch key := node.Content[i].Value; key {
+		case "buy_pct", "sell_pct", "fee_pct", "fee_fixed", "spread_pct":
+		default:
+			return fmt.Errorf("line %d: unknown field %q", node.Content[i].Line, key)
+		}
+	}
+	type plain Rule // без UnmarshalYAML, иначе рекурсия
+	var raw struct {
+		plain     `yaml:",inline"`
+		SpreadPct *float64 `yaml:"spread_pct"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*r = Rule(raw.plain)
+	if raw.SpreadPct != nil {
+		r.BuyPct, r.SellPct = *raw.SpreadPct, *raw.SpreadPct
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	for name, pct := range map[string]float64{"buy_pct": r.BuyPct, "sell_pct": r.SellPct, "fee_pct": r.FeePct} {
+		if pct < 0 || pct >= 100 {
+			return fmt.Errorf("%s must be in [0, 100), got %v", name, pct)
+		}
+	}
+	if r.FeeFixed < 0 {
+		return fmt.Errorf("fee_fixed must not be negative")
+	}
+	return nil
+}
+
+// Rules - набор правил. Применяется самое специфичное:
+// пара ("USD/EUR") > валюта ("JPY") > тариф клиента > Default.
+type Rules struct {
+	Default    Rule            `yaml:"-"`
+	Rounding   string          `yaml:"-"`
+	Pairs      map[string]Rule `yaml:"pairs"`
+	Currencies map[string]Rule `yaml:"currencies"`
+	Plans      map[string]Rule `yaml:"plans"`
+}
+
+// Load собирает правила из конфигурации: правило по умолчанию из cfg,
+// остальные - из файла cfg.File (YAML или JSON), если он задан
+func Load(cfg config.PricingConfig) (*Rules, error) {
+	rules := &Rules{}
+	if cfg.File != "" {
+		data, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("read pricing file: %w", err)
+		}
+		// JSON - подмножество YAML, один декодер подходит для обоих форматов
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(rules); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse pricing file: %w", err)
+		}
+	}
+	rules.Default = Rule{
+		BuyPct:   cfg.SpreadPct,
+		SellPct:  cfg.SpreadPct,
+		FeePct:   cfg.FeePct,
+		FeeFixed: cfg.FeeFixed,
+	}
+	rules.Rounding = cfg.Rounding
+	if err := rules.normalize(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// normalize приводит ключи к верхнему регистру (тарифы - к нижнему) и проверяет правила
+func (r *Rules) normalize() error {
+	if r.Rounding == "" {
+		r.Rounding = RoundingNone
+	}
+	if err := ValidateRounding(r.Rounding); err != nil {
+		return err
+	}
+	if err := r.Default.validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	pairs := make(map[string]Rule, len(r.Pairs))
+	for key, rule := range r.Pairs {
+		base, quote, ok := strings.Cut(strings.ToUpper(key), "/")
+		if !ok || len(base) != 3 || len(quote) != 3 {
+			return fmt.Errorf("pairs: %q must look like USD/EUR", key)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("pairs.%s: %w", key, err)
+		}
+		pairs[base+"/"+quote] = rule
+	}
+	r.Pairs = pairs
+
+	currencies := make(map[string]Rule, len(r.Currencies))
+	for key, rule := range r.Currencies {
+		if len(key) != 3 {
+			return fmt.Errorf("currencies: %q must be a 3-letter code", key)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("currencies.%s: %w", key, err)
+		}
+		currencies[strings.ToUpper(key)] = rule
+	}
+	r.Currencies = currencies
+
+	plans := make(map[string]Rule, len(r.Plans))
+	for key, rule := range r.Plans {
+		plan := strings.ToLower(key)
+		switch plan {
+		case config.PlanFree, config.PlanBasic, config.PlanPremium:
+		default:
+			return fmt.Errorf("plans: unknown plan %q", key)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("plans.%s: %w", key, err)
+		}
+		plans[plan] = rule
+	}
+	r.Plans = plans
+	return nil
+}
+
+// Quote - результат применения правила к конвертации
+type Quote struct {
+	MidRate     float64 // курс до наценки
+	AppliedRate float64 // курс после наценки
+	MarkupPct   float64
+	Fee         float64 // в валюте списания, вычитается из суммы до конвертации
+	Result      float64 // после округления
+	RawResult   float64 // до округления
+	Rounding    string
+	Source      string // какое правило сработало: pair, currency, plan, default
+}
+
+// Price применяет к конвертации from->to самое специфичное правило.
+// Клиент продает from и покупает to; наценка ухудшает курс для клиента.
+func (r *Rules) Price(from, to, plan string, amount, mid float64) (Quote, error) {
+	markup, rule, source := r.match(strings.ToUpper(from), strings.ToUpper(to), plan)
+
+	q := Quote{
+		MidRate:     mid,
+		MarkupPct:   markup,
+		AppliedRate: mid * (1 - markup/100),
+		Fee:         Round(rule.FeeFixed+amount*rule.FeePct/100, from, r.Rounding),
+		Rounding:    r.Rounding,
+		Source:      source,
+	}
+	if q.Fee >= amount {
+		return Quote{}, fmt.Errorf("fee %.2f %s exceeds the amount", q.Fee, from)
+	}
+	q.RawResult = (amount - q.Fee) * q.AppliedRate
+	q.Result = Round(q.RawResult, to, r.Rounding)
+	return q, nil
+}
+
+// match выбирает правило и спред с учетом направления
+func (r *Rules) match(from, to, plan string) (float64, Rule, string) {
+	// Пара: продаем базовую валюту - SellPct, покупаем - BuyPct
+	if rule, ok := r.Pairs[from+"/"+to]; ok {
+		return rule.SellPct, rule, "pair"
+	}
+	if rule, ok := r.Pairs[to+"/"+from]; ok {
+		return rule.BuyPct, rule, "pair"
+	}
+	// Валюта: покупаем ее - BuyPct, продаем - SellPct
+	if rule, ok := r.Currencies[to]; ok {
+		return rule.BuyPct, rule, "currency"
+	}
+	if rule, ok := r.Currencies[from]; ok {
+		return rule.SellPct, rule, "currency"
+	}
+	// Без базовой валюты клиент покупает to
+	if rule, ok := r.Plans[plan]; ok {
+		return rule.BuyPct, rule, "plan"
+	}
+	return r.Default.BuyPct, r.Default, "default"
+}
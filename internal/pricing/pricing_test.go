@@ -0,0 +1,157 @@
+package pricing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadRules(t *testing.T, body string) *Rules {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	rules, err := Load(config.PricingConfig{File: path, SpreadPct: 1, FeeFixed: 0.5})
+	require.NoError(t, err)
+	return rules
+}
+
+func TestRules_MostSpecificWins(t *testing.T) {
+	rules := loadRules(t, `
+pairs:
+  usd/eur: {buy_pct: 0.2, sell_pct: 0.3}
+currencies:
+  JPY: {spread_pct: 2, fee_pct: 1}
+plans:
+  premium: {spread_pct: 0.1}
+`)
+
+	tests := []struct {
+		name         string
+		from, to     string
+		plan         string
+		markup       float64
+		source       string
+		fee          float64
+		amountResult float64
+	}{
+		{"pair sell base", "USD", "EUR", "free", 0.3, "pair", 0, 100 * 0.9 * (1 - 0.003)},
+		{"pair buy base", "EUR", "USD", "premium", 0.2, "pair", 0, 100 * 0.9 * (1 - 0.002)},
+		{"currency", "USD", "JPY", "premium", 2, "currency", 1, 99 * 0.9 * 0.98},
+		{"plan", "USD", "GBP", "premium", 0.1, "plan", 0, 100 * 0.9 * (1 - 0.001)},
+		{"default", "USD", "GBP", "basic", 1, "default", 0.5, 99.5 * 0.9 * 0.99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := rules.Price(tt.from, tt.to, tt.plan, 100, 0.9)
+			require.NoError(t, err)
+			assert.Equal(t, tt.source, q.Source)
+			assert.Equal(t, tt.markup, q.MarkupPct)
+			assert.Equal(t, 0.9, q.MidRate)
+			assert.InDelta(t, 0.9*(1-tt.markup/100), q.AppliedRate, 1e-12)
+			assert.InDelta(t, tt.fee, q.Fee, 1e-12)
+			assert.InDelta(t, tt.amountResult, q.Result, 1e-9)
+		})
+	}
+}
+
+func TestRules_FeeExceedsAmount(t *testing.T) {
+	rules, err := Load(config.PricingConfig{FeeFixed: 5})
+	require.NoError(t, err)
+
+	_, err = rules.Price("USD", "EUR", "free", 5, 0.9)
+	assert.ErrorContains(t, err, "exceeds the amount")
+}
+
+func TestLoad_RejectsInvalidRules(t *testing.T) {
+	for name, body := range map[string]string{
+		"bad pair":      "pairs: {USDEUR: {spread_pct: 1}}",
+		"unknown plan":  "plans: {gold: {spread_pct: 1}}",
+		"negative fee":  "currencies: {EUR: {fee_fixed: -1}}",
+		"unknown field": "pairs: {USD/EUR: {margin: 1}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pricing.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+			_, err := Load(config.PricingConfig{File: path})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, 84.83, Round(84.825, "EUR", RoundingHalfUp))
+	assert.Equal(t, 84.82, Round(84.825, "EUR", RoundingHalfEven))
+	assert.Equal(t, 84.82, Round(84.829, "EUR", RoundingDown))
+	assert.Equal(t, 16150.0, Round(16149.5, "JPY", RoundingHalfUp))
+	assert.Equal(t, 1.235, Round(1.2345, "KWD", RoundingHalfUp))
+	assert.Equal(t, 84.829, Round(84.829, "EUR", RoundingNone))
+}
+
+func TestRules_PriceRoundsResultAndFee(t *testing.T) {
+	rules, err := Load(config.PricingConfig{FeePct: 0.333, Rounding: RoundingHalfUp})
+	require.NoError(t, err)
+
+	q, err := rules.Price("USD", "JPY", "free", 100, 161.57)
+	require.NoError(t, err)
+	assert.Equal(t, 0.33, q.Fee)
+	assert.InDelta(t, 99.67*161.57, q.RawResult, 1e-9)
+	assert.Equal(t, 16104.0, q.Result)
+	assert.Equal(t, RoundingHalfUp, q.Rounding)
+}
+
+func TestRules_PriceTargetReachesTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PricingConfig
+		from, to string
+		target   float64
+		mid      float64
+	}{
+		{"no rounding", config.PricingConfig{SpreadPct: 0.5, FeePct: 0.3, FeeFixed: 1}, "USD", "EUR", 1000, 0.92},
+		{"half up", config.PricingConfig{SpreadPct: 0.5, FeePct: 0.333, FeeFixed: 0.5, Rounding: RoundingHalfUp}, "USD", "EUR", 1000, 0.9213},
+		{"down to JPY", config.PricingConfig{SpreadPct: 1, FeeFixed: 2, Rounding: RoundingDown}, "USD", "JPY", 16150, 161.57},
+		{"from JPY", config.PricingConfig{SpreadPct: 1, FeePct: 1, Rounding: RoundingHalfEven}, "JPY", "USD", 99.99, 0.00619},
+		// Единица from намного меньше единицы to: до минимума тысячи единиц IDR от target/rate
+		{"IDR to KWD", config.PricingConfig{SpreadPct: 0.5, FeePct: 0.2, FeeFixed: 5000, Rounding: RoundingHalfUp}, "IDR", "KWD", 1000, 0.0000186},
+		{"IDR to KWD down", config.PricingConfig{SpreadPct: 0.5, Rounding: RoundingDown}, "IDR", "KWD", 0.001, 0.0000186},
+		{"KWD to JPY", config.PricingConfig{SpreadPct: 1, FeeFixed: 0.25, Rounding: RoundingHalfEven}, "KWD", "JPY", 1000001, 488.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Load(tt.cfg)
+			require.NoError(t, err)
+
+			amount, q, err := rules.PriceTarget(tt.from, tt.to, "free", tt.target, tt.mid)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, q.Result, tt.target)
+
+			forward, err := rules.Price(tt.from, tt.to, "free", amount, tt.mid)
+			require.NoError(t, err)
+			assert.Equal(t, q, forward)
+
+			if tt.cfg.Rounding != "" {
+				// Сумма кратна минимальной единице и на единицу меньше уже не хватает
+				unit := 1 / math.Pow10(MinorUnits(tt.from))
+				assert.InDelta(t, Round(amount, tt.from, RoundingHalfUp), amount, 1e-9)
+				less, err := rules.Price(tt.from, tt.to, "free", amount-unit, tt.mid)
+				if err == nil {
+					assert.Less(t, less.Result, tt.target)
+				}
+			}
+		})
+	}
+}
+
+func TestRules_PriceTargetRejectsNonPositive(t *testing.T) {
+	rules, err := Load(config.PricingConfig{})
+	require.NoError(t, err)
+
+	_, _, err = rules.PriceTarget("USD", "EUR", "free", 0, 0.9)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxSolveSteps ограничивает подбор суммы вверх от аналитической нижней оценки
+const maxSolveSteps = 100
+
+// PriceTarget решает обратную задачу: сколько from нужно продать, чтобы получить
+// не меньше target в валюте to. Возвращает найденную сумму списания и Quote прямой
+// конвертации этой суммы, т.е. Quote.Result >= target. При округлении сумма
+// списания кратна минимальной единице from и минимальна среди таких сумм.
+func (r *Rules) PriceTarget(from, to, plan string, target, mid float64) (float64, Quote, error) {
+	if target <= 0 || mid <= 0 {
+		return 0, Quote{}, fmt.Errorf("target amount and rate must be positive")
+	}
+	markup, rule, _ := r.match(strings.ToUpper(from), strings.ToUpper(to), plan)
+
+	applied := mid * (1 - markup/100)
+
+	if r.Rounding == RoundingNone {
+		// result = (amount - fixed - amount*pct) * applied; ошибка округления float
+		// может дать result чуть меньше target - добираем по ulp
+		amount := (target/applied + rule.FeeFixed) / (1 - rule.FeePct/100)
+		for i := 0; i < maxSolveSteps; i++ {
+			if q, err := r.Price(from, to, plan, amount, mid); err == nil && q.Result >= target {
+				return amount, q, nil
+			}
+			amount = math.Nextafter(amount, math.Inf(1))
+		}
+		return 0, Quote{}, fmt.Errorf("cannot reach %v %s", target, to)
+	}
+
+	// С округлением комиссия и результат ступенчатые, но не убывают с ростом суммы:
+	// комиссия за одну минимальную единицу from растет не больше чем на одну единицу.
+	// Поэтому достаточно начать с нижней оценки и идти вверх до первой подходящей суммы.
+	scale := math.Pow10(MinorUnits(from))
+	units := math.Max(1, math.Floor(r.lowerBound(from, to, target, applied, rule)*scale))
+	for i := 0; i < maxSolveSteps; i++ {
+		if q, err := r.Price(from, to, plan, units/scale, mid); err == nil && q.Result >= target {
+			return units / scale, q, nil
+		}
+		units++
+	}
+	return 0, Quote{}, fmt.Errorf("cannot reach %v %s", target, to)
+}
+
+// lowerBound - сумма from, меньше которой target недостижим при округлении r.Rounding.
+// Интервал округления результата и комиссии обращается аналитически, так что от оценки
+// до ответа - несколько минимальных единиц from при любом соотношении единиц валют.
+func (r *Rules) lowerBound(from, to string, target, applied float64, rule Rule) float64 {
+	// Результат кратен единице to: нужен хотя бы target, округленный вверх до единицы
+	unitTo := 1 / math.Pow10(MinorUnits(to))
+	need := math.Ceil(target/unitTo-roundingSlack) * unitTo
+	// Наименьший сырой результат, который округляется до need
+	if r.Rounding != RoundingDown {
+		need -= unitTo / 2
+	}
+	need -= unitTo * roundingSlack
+	// Комиссия после округления меньше точной не больше чем на единицу from
+	unitFrom := 1 / math.Pow10(MinorUnits(from))
+	return (need/applied + rule.FeeFixed - unitFrom) / (1 - rule.FeePct/100)
+}
+
+// roundingSlack - запас на шум двоичного представления, как в Round
+const roundingSlack = 1e-6
@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Режимы округления результата и комиссии до минимальной единицы валюты
+const (
+	RoundingNone     = "none"
+	RoundingHalfUp   = "half_up"
+	RoundingHalfEven = "half_even"
+	RoundingDown     = "down"
+)
+
+// ValidateRounding проверяет название режима округления
+func ValidateRounding(mode string) error {
+	switch mode {
+	case RoundingNone, RoundingHalfUp, RoundingHalfEven, RoundingDown:
+		return nil
+	}
+	return fmt.Errorf("unknown rounding mode %q", mode)
+}
+
+// minorUnits - число знаков после запятой по ISO 4217 для валют, где оно не равно 2
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits возвращает число знаков после запятой для валюты
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return 2
+}
+
+// Round округляет сумму до минимальной единицы валюты
+func Round(amount float64, currency, mode string) float64 {
+	if mode == RoundingNone || mode == "" {
+		return amount
+	}
+	scale := math.Pow10(MinorUnits(currency))
+	// Срезаем шум двоичного представления: 84.825 не должно стать 84.82499999
+	scaled := math.Round(amount*scale*1e6) / 1e6
+	switch mode {
+	case RoundingHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case RoundingDown:
+		scaled = math.Floor(scaled)
+	default:
+		scaled = math.Round(scaled)
+	}
+	return scaled / scale
+}
@@ -0,0 +1,226 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/metrics"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+// Mode - режим расходования бюджета запросов к upstream
+type Mode string
+
+const (
+	ModeNormal    Mode = "normal"     // обычный TTL кеша
+	ModeConserve  Mode = "conserve"   // кешируем дольше, чтобы реже ходить в upstream
+	ModeStaleOnly Mode = "stale_only" // upstream не вызываем, отдаем последний известный курс
+)
+
+func (m Mode) gaugeValue() float64 {
+	switch m {
+	case ModeConserve:
+		return 1
+	case ModeStaleOnly:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Usage - расход бюджета провайдера за текущий расчетный период
+type Usage struct {
+	Provider    string    `json:"provider"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Budget      int       `json:"budget"`
+	Used        int64     `json:"used"`
+	Remaining   int64     `json:"remaining"`
+	Mode        Mode      `json:"mode"`
+	Source      string    `json:"source"` // "redis" или "local", если Redis недоступен
+}
+
+// Tracker считает запросы к upstream в Redis по расчетным периодам.
+// Пока Redis недоступен, счет ведется локально в процессе.
+type Tracker struct {
+	config config.QuotaConfig
+	redis  *cache.RedisClient
+	logger *zap.Logger
+	now    func() time.Time
+
+	extendedTTL atomic.Int64 // меняется при перезагрузке конфигурации
+
+	mu        sync.Mutex
+	local     map[string]int64 // ключ периода -> запросы, учтенные этим процессом
+	lastKnown map[string]int64 // ключ периода -> последнее значение из Redis
+	lastMode  map[string]Mode  // провайдер -> режим, для логирования переходов
+}
+
+func NewTracker(cfg config.QuotaConfig, redisClient *cache.RedisClient, logger *zap.Logger) *Tracker {
+	t := &Tracker{
+		config:    cfg,
+		redis:     redisClient,
+		logger:    logger,
+		now:       time.Now,
+		local:     make(map[string]int64),
+		lastKnown: make(map[string]int64),
+		lastMode:  make(map[string]Mode),
+	}
+	t.SetExtendedTTL(cfg.ExtendedTTL)
+	return t
+}
+
+// SetExtendedTTL меняет TTL кеша для режимов экономии
+func (t *Tracker) SetExtendedTTL(ttl time.Duration) {
+	t.extendedTTL.Store(int64(ttl))
+}
+
+// Enabled - задан ли бюджет вообще
+func (t *Tracker) Enabled() bool {
+	return t.config.Budget > 0
+}
+
+// Record учитывает один запрос к upstream
+func (t *Tracker) Record(ctx context.Context, provider string) {
+	start, end := t.period()
+	key := periodKey(provider, start)
+
+	t.mu.Lock()
+	t.local[key]++
+	t.mu.Unlock()
+
+	// Ключ живет до конца периода плюс запас, чтобы значение можно было посмотреть после
+	used, err := t.redis.IncrementCounter(ctx, key, end.Sub(t.now())+7*24*time.Hour)
+	if err != nil {
+		if !errors.Is(err, cache.ErrUnavailable) {
+			logging.FromContext(ctx, t.logger).Warn("Failed to record upstream call in quota",
+				zap.String("provider", provider),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	t.mu.Lock()
+	t.lastKnown[key] = used
+	t.mu.Unlock()
+	metrics.UpstreamQuotaUsed.WithLabelValues(provider).Set(float64(used))
+}
+
+// Usage возвращает расход бюджета за текущий период
+func (t *Tracker) Usage(ctx context.Context, provider string) Usage {
+	start, end := t.period()
+	key := periodKey(provider, start)
+
+	usage := Usage{
+		Provider:    provider,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Budget:      t.config.Budget,
+		Source:      "redis",
+	}
+
+	used, err := t.redis.GetCounter(ctx, key)
+	t.mu.Lock()
+	if err != nil {
+		// Redis недоступен: берем максимум из последнего известного и локального счета
+		usage.Source = "local"
+		used = max(t.lastKnown[key], t.local[key])
+	} else {
+		t.lastKnown[key] = used
+	}
+	t.mu.Unlock()
+
+	usage.Used = used
+	if t.Enabled() {
+		usage.Remaining = max(int64(t.config.Budget)-used, 0)
+	}
+	usage.Mode = t.modeFor(used)
+	t.observeMode(ctx, provider, usage)
+	return usage
+}
+
+// Mode возвращает режим, в котором сейчас нужно работать с провайдером
+func (t *Tracker) Mode(ctx context.Context, provider string) Mode {
+	if !t.Enabled() {
+		return ModeNormal
+	}
+	return t.Usage(ctx, provider).Mode
+}
+
+// CacheTTL возвращает TTL кеша для режима: в режимах экономии курсы живут дольше
+func (t *Tracker) CacheTTL(mode Mode, defaultTTL time.Duration) time.Duration {
+	extended := time.Duration(t.extendedTTL.Load())
+	if mode == ModeNormal || extended <= defaultTTL {
+		return defaultTTL
+	}
+	return extended
+}
+
+func (t *Tracker) modeFor(used int64) Mode {
+	if !t.Enabled() {
+		return ModeNormal
+	}
+	ratio := float64(used) / float64(t.config.Budget)
+	switch {
+	case ratio >= t.config.StaleOnlyThreshold:
+		return ModeStaleOnly
+	case ratio >= t.config.ConserveThreshold:
+		return ModeConserve
+	default:
+		return ModeNormal
+	}
+}
+
+// observeMode пишет в лог и метрики смену режима
+func (t *Tracker) observeMode(ctx context.Context, provider string, usage Usage) {
+	metrics.UpstreamQuotaMode.WithLabelValues(provider).Set(usage.Mode.gaugeValue())
+
+	t.mu.Lock()
+	previous, seen := t.lastMode[provider]
+	t.lastMode[provider] = usage.Mode
+	t.mu.Unlock()
+
+	if !seen || previous == usage.Mode {
+		return
+	}
+	logging.FromContext(ctx, t.logger).Warn("Upstream budget mode changed",
+		zap.String("provider", provider),
+		zap.String("from", string(previous)),
+		zap.String("to", string(usage.Mode)),
+		zap.Int64("used", usage.Used),
+		zap.Int("budget", usage.Budget),
+	)
+}
+
+// period возвращает границы текущего расчетного периода в UTC
+func (t *Tracker) period() (time.Time, time.Time) {
+	return billingPeriod(t.now(), t.config.BillingDay)
+}
+
+func billingPeriod(now time.Time, billingDay int) (time.Time, time.Time) {
+	if billingDay < 1 {
+		billingDay = 1
+	}
+	if billingDay > 28 {
+		billingDay = 28
+	}
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), billingDay, 0, 0, 0, 0, time.UTC)
+	if now.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start, start.AddDate(0, 1, 0)
+}
+
+func periodKey(provider string, start time.Time) string {
+	return fmt.Sprintf("quota:%s:%s", provider, start.Format("2006-01-02"))
+}
@@ -0,0 +1,79 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBillingPeriod(t *testing.T) {
+	testCases := []struct {
+		name       string
+		now        time.Time
+		billingDay int
+		wantStart  string
+		wantEnd    string
+	}{
+		{"FirstDay", time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC), 1, "2025-03-01", "2025-04-01"},
+		{"BeforeBillingDay", time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC), 10, "2025-02-10", "2025-03-10"},
+		{"OnBillingDay", time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), 10, "2025-03-10", "2025-04-10"},
+		{"YearBoundary", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), 15, "2024-12-15", "2025-01-15"},
+		{"ClampedDay", time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), 31, "2025-02-28", "2025-03-28"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := billingPeriod(tc.now, tc.billingDay)
+			assert.Equal(t, tc.wantStart, start.Format("2006-01-02"))
+			assert.Equal(t, tc.wantEnd, end.Format("2006-01-02"))
+		})
+	}
+}
+
+func TestTracker_ModesWithoutRedis(t *testing.T) {
+	tracker := NewTracker(config.QuotaConfig{
+		Budget:             10,
+		BillingDay:         1,
+		ConserveThreshold:  0.5,
+		StaleOnlyThreshold: 0.9,
+		ExtendedTTL:        6 * time.Hour,
+	}, nil, zap.NewNop())
+	ctx := context.Background()
+
+	assert.Equal(t, ModeNormal, tracker.Mode(ctx, "test"))
+
+	for i := 0; i < 5; i++ {
+		tracker.Record(ctx, "test")
+	}
+	assert.Equal(t, ModeConserve, tracker.Mode(ctx, "test"))
+	assert.Equal(t, 6*time.Hour, tracker.CacheTTL(ModeConserve, 30*time.Minute))
+
+	for i := 0; i < 4; i++ {
+		tracker.Record(ctx, "test")
+	}
+	usage := tracker.Usage(ctx, "test")
+	assert.Equal(t, ModeStaleOnly, usage.Mode)
+	assert.Equal(t, int64(9), usage.Used)
+	assert.Equal(t, int64(1), usage.Remaining)
+	assert.Equal(t, "local", usage.Source)
+
+	// Другой провайдер считается отдельно
+	assert.Equal(t, ModeNormal, tracker.Mode(ctx, "other"))
+}
+
+func TestTracker_Disabled(t *testing.T) {
+	tracker := NewTracker(config.QuotaConfig{}, nil, zap.NewNop())
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		tracker.Record(ctx, "test")
+	}
+	assert.False(t, tracker.Enabled())
+	assert.Equal(t, ModeNormal, tracker.Mode(ctx, "test"))
+	assert.Equal(t, 30*time.Minute, tracker.CacheTTL(ModeNormal, 30*time.Minute))
+}
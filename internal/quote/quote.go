@@ -0,0 +1,68 @@
+// Package quote хранит котировки: курс, зафиксированный для клиента на короткое время.
+package quote
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"currency-converter-v2/internal/override"
+)
+
+var (
+	// ErrNotFound - котировки нет или она уже истекла
+	ErrNotFound = errors.New("quote not found")
+	// ErrUsed - котировка уже исполнена
+	ErrUsed = errors.New("quote already executed")
+)
+
+// Quote - зафиксированный результат конвертации
+type Quote struct {
+	ID        string    `json:"id"`
+	ClientID  string    `json:"client_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    float64   `json:"amount"`
+	Rate      float64   `json:"rate"`
+	MidRate   float64   `json:"mid_rate"`
+	MarkupPct float64   `json:"markup_pct"`
+	Fee       float64   `json:"fee"`
+	Result    float64   `json:"result"`
+	RawResult float64   `json:"raw_result"`
+	Rounding  string    `json:"rounding"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Происхождение курса на момент котировки
+	RateSource    string             `json:"rate_source"`
+	RateProvider  string             `json:"rate_provider,omitempty"`
+	RateTimestamp time.Time          `json:"rate_timestamp"`
+	CacheAge      time.Duration      `json:"cache_age"`
+	Stale         bool               `json:"stale"`
+	Override      *override.Override `json:"override,omitempty"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+}
+
+// Expired сообщает, истек ли срок действия котировки
+func (q Quote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// Store - хранилище котировок. Котировка исполняется не больше одного раза:
+// MarkUsed атомарно помечает ее и возвращает ErrUsed при повторе.
+type Store interface {
+	Save(ctx context.Context, q Quote) error
+	Get(ctx context.Context, id string) (Quote, error)
+	MarkUsed(ctx context.Context, q Quote) error
+}
+
+// NewID возвращает случайный идентификатор котировки. Он же служит токеном,
+// поэтому длиннее, чем id ручных курсов.
+func NewID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "q_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,108 @@
+package quote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"currency-converter-v2/pkg/cache"
+)
+
+// usedGrace - пометка об исполнении живет чуть дольше самой котировки,
+// чтобы повтор на границе срока не прошел как первое исполнение
+const usedGrace = time.Minute
+
+// RedisStore хранит котировки в Redis с TTL до истечения срока.
+// Пока Redis недоступен, методы возвращают cache.ErrUnavailable.
+type RedisStore struct {
+	redis *cache.RedisClient
+	now   func() time.Time
+}
+
+func NewRedisStore(redis *cache.RedisClient) *RedisStore {
+	return &RedisStore{redis: redis, now: time.Now}
+}
+
+func quoteKey(id string) string {
+	return "quote:" + id
+}
+
+func usedKey(id string) string {
+	return "quote:used:" + id
+}
+
+func (s *RedisStore) Save(ctx context.Context, q Quote) error {
+	data, err := json.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("marshal quote: %w", err)
+	}
+	return s.redis.SetValue(ctx, quoteKey(q.ID), data, q.ExpiresAt.Sub(s.now()))
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (Quote, error) {
+	data, err := s.redis.GetValue(ctx, quoteKey(id))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return Quote{}, ErrNotFound
+	}
+	if err != nil {
+		return Quote{}, err
+	}
+	var q Quote
+	if err := json.Unmarshal(data, &q); err != nil {
+		return Quote{}, fmt.Errorf("unmarshal quote %s: %w", id, err)
+	}
+	return q, nil
+}
+
+func (s *RedisStore) MarkUsed(ctx context.Context, q Quote) error {
+	ttl := q.ExpiresAt.Sub(s.now()) + usedGrace
+	ok, err := s.redis.SetValueIfAbsent(ctx, usedKey(q.ID), []byte(s.now().UTC().Format(time.RFC3339Nano)), ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUsed
+	}
+	return nil
+}
+
+// MemoryStore - хранилище в памяти процесса, для тестов
+type MemoryStore struct {
+	mu     sync.Mutex
+	quotes map[string]Quote
+	used   map[string]bool
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{quotes: make(map[string]Quote), used: make(map[string]bool)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, q Quote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotes[q.ID] = q
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.quotes[id]
+	if !ok {
+		return Quote{}, ErrNotFound
+	}
+	return q, nil
+}
+
+func (s *MemoryStore) MarkUsed(_ context.Context, q Quote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used[q.ID] {
+		return ErrUsed
+	}
+	s.used[q.ID] = true
+	return nil
+}
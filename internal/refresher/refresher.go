@@ -0,0 +1,233 @@
+// Package refresher периодически обновляет курсы отслеживаемых пар в кеше
+// и оповещает подписчиков (алерты, стримы) о новых значениях.
+package refresher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"currency-converter-v2/internal/service"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+// lockKey - в каждом цикле курсы обновляет только один инстанс
+const lockKey = "refresher:lock"
+
+// AllSymbols в Pair.To - все курсы базовой валюты ("USD/*")
+const AllSymbols = "*"
+
+// Pair - валютная пара
+type Pair struct {
+	From string
+	To   string
+}
+
+// ParsePair разбирает "USD/EUR" и "USD/*"
+func ParsePair(s string) (Pair, bool) {
+	from, to, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if !ok || len(from) != 3 || (len(to) != 3 && to != AllSymbols) {
+		return Pair{}, false
+	}
+	return Pair{From: from, To: to}, true
+}
+
+func (p Pair) String() string {
+	return p.From + "/" + p.To
+}
+
+// Update - обновленные курсы одной базовой валюты за цикл
+type Update struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	Providers map[string]string  `json:"providers,omitempty"` // валюта -> провайдер
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// RateSource - откуда брать свежие курсы: одна таблица на базовую валюту
+type RateSource interface {
+	RefreshTable(ctx context.Context, base string) (service.RateTable, error)
+}
+
+// Refresher раз в интервал обновляет курсы пар, которые нужны подписчикам
+type Refresher struct {
+	source   RateSource
+	redis    *cache.RedisClient
+	logger   *zap.Logger
+	interval time.Duration
+	instance string
+
+	mu       sync.RWMutex
+	static   []Pair
+	watchers []func(ctx context.Context) []Pair
+	handlers []func(ctx context.Context, u Update)
+}
+
+// New создает обновлятор; static - пары, которые обновляются всегда
+func New(source RateSource, redis *cache.RedisClient, interval time.Duration, static []Pair, logger *zap.Logger) *Refresher {
+	host, _ := os.Hostname()
+	return &Refresher{
+		source:   source,
+		redis:    redis,
+		logger:   logger,
+		interval: interval,
+		instance: host,
+		static:   static,
+	}
+}
+
+// Watch регистрирует источник пар для обновления (например, пары активных алертов)
+func (r *Refresher) Watch(pairs func(ctx context.Context) []Pair) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watchers = append(r.watchers, pairs)
+}
+
+// Subscribe регистрирует обработчик обновлений. Вызывается синхронно в цикле
+// обновления, поэтому долгую работу обработчик уносит в фон сам.
+func (r *Refresher) Subscribe(handler func(ctx context.Context, u Update)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, handler)
+}
+
+// Run обновляет курсы, пока не отменен ctx
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.acquire(ctx) {
+				r.RefreshOnce(ctx)
+			}
+		}
+	}
+}
+
+// acquire берет блокировку цикла в Redis. Без Redis каждый инстанс обновляет курсы сам.
+func (r *Refresher) acquire(ctx context.Context) bool {
+	// Блокировка живет чуть меньше интервала, чтобы к следующему циклу освободиться
+	ok, err := r.redis.SetValueIfAbsent(ctx, lockKey, []byte(r.instance), r.interval*9/10)
+	if errors.Is(err, cache.ErrUnavailable) {
+		return true
+	}
+	if err != nil {
+		r.logger.Warn("Failed to acquire refresher lock, refreshing anyway", zap.Error(err))
+		return true
+	}
+	if !ok {
+		r.logger.Debug("Rates are refreshed by another instance this cycle")
+	}
+	return ok
+}
+
+// RefreshOnce обновляет все отслеживаемые пары и рассылает обновления по базовым валютам
+func (r *Refresher) RefreshOnce(ctx context.Context) {
+	r.mu.RLock()
+	watchers := r.watchers
+	handlers := r.handlers
+	pairs := append([]Pair(nil), r.static...)
+	r.mu.RUnlock()
+
+	for _, watch := range watchers {
+		pairs = append(pairs, watch(ctx)...)
+	}
+
+	for _, base := range groupByBase(pairs) {
+		// Одна таблица на базу: запрос к провайдеру не зависит от числа пар
+		table, err := r.source.RefreshTable(ctx, base.name)
+		if err != nil {
+			r.logger.Warn("Failed to refresh rates",
+				zap.String("base", base.name),
+				zap.Strings("symbols", base.symbols),
+				zap.Error(err),
+			)
+			continue
+		}
+		// Последняя известная таблица - не обновление
+		if table.Stale {
+			continue
+		}
+
+		symbols := base.symbols
+		if base.all {
+			symbols = make([]string, 0, len(table.Rates))
+			for symbol := range table.Rates {
+				if symbol != base.name {
+					symbols = append(symbols, symbol)
+				}
+			}
+		}
+		update := Update{
+			Base:      base.name,
+			Rates:     make(map[string]float64, len(symbols)),
+			Providers: make(map[string]string, len(symbols)),
+			Timestamp: time.Now().UTC(),
+		}
+		for _, symbol := range symbols {
+			value, ok := table.Rates[symbol]
+			if !ok {
+				r.logger.Warn("Refreshed rates have no watched symbol",
+					zap.String("from", base.name),
+					zap.String("to", symbol),
+				)
+				continue
+			}
+			update.Rates[symbol] = value
+			update.Providers[symbol] = table.Provider
+		}
+		if len(update.Rates) == 0 {
+			continue
+		}
+		r.logger.Debug("Rates refreshed",
+			zap.String("base", update.Base),
+			zap.Int("symbols", len(update.Rates)),
+		)
+		for _, handle := range handlers {
+			handle(ctx, update)
+		}
+	}
+}
+
+type baseSymbols struct {
+	name    string
+	symbols []string
+	all     bool // нужны все курсы базы, symbols не важны
+}
+
+// groupByBase убирает дубликаты и группирует пары по базовой валюте в стабильном порядке
+func groupByBase(pairs []Pair) []baseSymbols {
+	set := make(map[string]map[string]bool)
+	for _, p := range pairs {
+		if p.From == p.To {
+			continue
+		}
+		if set[p.From] == nil {
+			set[p.From] = make(map[string]bool)
+		}
+		set[p.From][p.To] = true
+	}
+
+	groups := make([]baseSymbols, 0, len(set))
+	for base, symbols := range set {
+		group := baseSymbols{name: base, all: symbols[AllSymbols]}
+		for symbol := range symbols {
+			if symbol != AllSymbols {
+				group.symbols = append(group.symbols, symbol)
+			}
+		}
+		sort.Strings(group.symbols)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
@@ -0,0 +1,79 @@
+package refresher
+
+import (
+	"context"
+	"testing"
+
+	"currency-converter-v2/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSource struct {
+	calls []string
+}
+
+func (f *fakeSource) RefreshTable(_ context.Context, base string) (service.RateTable, error) {
+	f.calls = append(f.calls, base)
+	table := service.RateTable{
+		Base:       base,
+		Rates:      map[string]float64{"EUR": 1.5, "GBP": 0.8, "JPY": 150},
+		Provenance: service.Provenance{Provider: "fixture"},
+	}
+	if base == "GBP" {
+		table.Stale = true
+	}
+	return table, nil
+}
+
+func TestRefreshOnce(t *testing.T) {
+	source := &fakeSource{}
+	r := New(source, nil, 0, []Pair{{From: "USD", To: "EUR"}}, zap.NewNop())
+	r.Watch(func(context.Context) []Pair {
+		return []Pair{
+			{From: "USD", To: "EUR"}, {From: "USD", To: "JPY"}, {From: "USD", To: "XXX"},
+			{From: "EUR", To: "GBP"}, {From: "GBP", To: "EUR"}, {From: "GBP", To: "GBP"},
+		}
+	})
+	var updates []Update
+	r.Subscribe(func(_ context.Context, u Update) { updates = append(updates, u) })
+
+	r.RefreshOnce(context.Background())
+
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, source.calls, "один запрос на базовую валюту")
+	require.Len(t, updates, 2, "устаревшая таблица не рассылается")
+	assert.Equal(t, "EUR", updates[0].Base)
+	assert.Equal(t, map[string]float64{"EUR": 1.5, "JPY": 150}, updates[1].Rates)
+	assert.Equal(t, "fixture", updates[1].Providers["EUR"])
+}
+
+func TestRefreshOnce_WholeTable(t *testing.T) {
+	source := &fakeSource{}
+	r := New(source, nil, 0, nil, zap.NewNop())
+	r.Watch(func(context.Context) []Pair {
+		return []Pair{{From: "USD", To: AllSymbols}, {From: "USD", To: "EUR"}}
+	})
+	var updates []Update
+	r.Subscribe(func(_ context.Context, u Update) { updates = append(updates, u) })
+
+	r.RefreshOnce(context.Background())
+
+	assert.Equal(t, []string{"USD"}, source.calls)
+	require.Len(t, updates, 1)
+	assert.Equal(t, map[string]float64{"EUR": 1.5, "GBP": 0.8, "JPY": 150}, updates[0].Rates)
+}
+
+func TestParsePair(t *testing.T) {
+	pair, ok := ParsePair(" usd/rub ")
+	require.True(t, ok)
+	assert.Equal(t, Pair{From: "USD", To: "RUB"}, pair)
+
+	pair, ok = ParsePair("usd/*")
+	require.True(t, ok)
+	assert.Equal(t, Pair{From: "USD", To: AllSymbols}, pair)
+
+	_, ok = ParsePair("USDRUB")
+	assert.False(t, ok)
+}
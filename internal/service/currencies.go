@@ -0,0 +1,68 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"currency-converter-v2/internal/pricing"
+)
+
+// Currency - валюта из справочника
+type Currency struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	MinorUnits int    `json:"minor_units"`
+}
+
+// currencyNames - справочник поддерживаемых валют (ISO 4217)
+var currencyNames = map[string]string{
+	"AED": "UAE Dirham",
+	"AUD": "Australian Dollar",
+	"BRL": "Brazilian Real",
+	"CAD": "Canadian Dollar",
+	"CHF": "Swiss Franc",
+	"CNY": "Chinese Yuan",
+	"CZK": "Czech Koruna",
+	"DKK": "Danish Krone",
+	"EUR": "Euro",
+	"GBP": "Pound Sterling",
+	"HKD": "Hong Kong Dollar",
+	"HUF": "Hungarian Forint",
+	"INR": "Indian Rupee",
+	"JPY": "Japanese Yen",
+	"KRW": "South Korean Won",
+	"KWD": "Kuwaiti Dinar",
+	"KZT": "Kazakhstani Tenge",
+	"MXN": "Mexican Peso",
+	"NOK": "Norwegian Krone",
+	"NZD": "New Zealand Dollar",
+	"PLN": "Polish Zloty",
+	"RUB": "Russian Ruble",
+	"SEK": "Swedish Krona",
+	"SGD": "Singapore Dollar",
+	"THB": "Thai Baht",
+	"TRY": "Turkish Lira",
+	"UAH": "Ukrainian Hryvnia",
+	"USD": "US Dollar",
+	"ZAR": "South African Rand",
+}
+
+// Currencies возвращает справочник валют, отсортированный по коду
+func Currencies() []Currency {
+	list := make([]Currency, 0, len(currencyNames))
+	for code, name := range currencyNames {
+		list = append(list, Currency{Code: code, Name: name, MinorUnits: pricing.MinorUnits(code)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// LookupCurrency ищет валюту в справочнике
+func LookupCurrency(code string) (Currency, bool) {
+	code = strings.ToUpper(code)
+	name, ok := currencyNames[code]
+	if !ok {
+		return Currency{}, false
+	}
+	return Currency{Code: code, Name: name, MinorUnits: pricing.MinorUnits(code)}, true
+}
@@ -2,42 +2,131 @@ package service
 
 import (
 	"context"
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/audit"
+	"currency-converter-v2/internal/auth"
 	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/metrics"
+	"currency-converter-v2/internal/override"
+	"currency-converter-v2/internal/pricing"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/internal/quote"
 	"currency-converter-v2/pkg/cache"
+	"currency-converter-v2/pkg/fixtures"
+	"currency-converter-v2/pkg/resilience"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("currency-converter-v2/internal/service")
+
 // CurrencyServiceInterface - интерфейс для тестирования
 type CurrencyServiceInterface interface {
-	Convert(ctx context.Context, from, to string, amount float64) (float64, float64, error)
+	Convert(ctx context.Context, from, to string, amount float64) (*ConversionResult, error)
 	GetExchangeRate(ctx context.Context, from, to string) (float64, error)
+	CreateQuote(ctx context.Context, from, to string, amount float64) (*quote.Quote, error)
+	ExecuteQuote(ctx context.Context, id string, terms QuoteTerms) (*ConversionResult, error)
+	ConvertMany(ctx context.Context, from string, targets []string, amount float64) (map[string]TargetResult, error)
+	ConvertTarget(ctx context.Context, from, to string, target float64) (*ConversionResult, error)
 }
 type CurrencyService struct {
 	config     *config.Config
 	redis      *cache.RedisClient
 	logger     *zap.Logger
 	httpClient *http.Client
-
-	// TODO: Добавить поля структуры
+	breaker    *resilience.CircuitBreaker
+	quota      *quota.Tracker
+
+	fetchers  map[string]rateFetcher
+	tables    map[string]tableFetcher
+	providers atomic.Pointer[[]string]
+	recorder  *fixtures.Store // режим record: ответы API сохраняются в фикстуры
+	overrides *override.Manager
+	pricing   atomic.Pointer[pricing.Rules]
+	quotes    quote.Store
+	audit     audit.Store
+	history   history.Store
+	quoteTTL  time.Duration
+
+	// Время последнего успешного ответа upstream (unix nano), для readiness
+	lastUpstreamSuccess atomic.Int64
 }
 
-func NewCurrencyService(cfg *config.Config, redisClient *cache.RedisClient, logger *zap.Logger) *CurrencyService {
+// ProviderName - имя провайдера курсов в метриках, логах и учете квоты
+const ProviderName = "exchangerate-api"
+
+func NewCurrencyService(cfg *config.Config, redisClient *cache.RedisClient, quotaTracker *quota.Tracker, logger *zap.Logger) *CurrencyService {
+	breaker := resilience.NewCircuitBreaker(ProviderName, resilience.BreakerConfig{
+		FailureThreshold: cfg.API.BreakerFailureThreshold,
+		OpenTimeout:      cfg.API.BreakerOpenTimeout,
+		HalfOpenRequests: cfg.API.BreakerHalfOpenRequests,
+	}, func(name string, from, to resilience.State) {
+		metrics.UpstreamCircuitState.WithLabelValues(name).Set(float64(to))
+		logger.Warn("Upstream circuit breaker state changed",
+			zap.String("provider", name),
+			zap.String("from", from.String()),
+			zap.String("to", to.String()),
+		)
+	})
+	metrics.UpstreamCircuitState.WithLabelValues(ProviderName).Set(float64(resilience.StateClosed))
+
+	transport := resilience.NewTransport(http.DefaultTransport, breaker, resilience.RetryConfig{
+		MaxRetries:    cfg.API.MaxRetries,
+		BaseDelay:     cfg.API.RetryBaseDelay,
+		MaxDelay:      cfg.API.RetryMaxDelay,
+		MaxRetryAfter: cfg.API.MaxRetryAfter,
+	}, resilience.Hooks{
+		OnAttempt: func(req *http.Request) {
+			quotaTracker.Record(req.Context(), ProviderName)
+		},
+		OnRetry: func(attempt int, reason string, delay time.Duration) {
+			metrics.UpstreamRetries.WithLabelValues(ProviderName, reason).Inc()
+			logger.Debug("Retrying upstream request",
+				zap.String("provider", ProviderName),
+				zap.Int("attempt", attempt),
+				zap.String("reason", reason),
+				zap.Duration("delay", delay),
+			)
+		},
+	})
+
 	client := &http.Client{
-		Timeout: cfg.API.Timeout,
+		Timeout:   cfg.API.Timeout,
+		Transport: transport,
 	}
-	return &CurrencyService{
+	s := &CurrencyService{
 		config:     cfg,
 		redis:      redisClient,
 		logger:     logger,
 		httpClient: client,
+		breaker:    breaker,
+		quota:      quotaTracker,
 	}
+	s.fetchers = map[string]rateFetcher{
+		ProviderName: s.FetchRateFromAPI,
+	}
+	s.tables = map[string]tableFetcher{
+		ProviderName: s.FetchTableFromAPI,
+	}
+	s.providers.Store(&[]string{ProviderName})
+	s.pricing.Store(&pricing.Rules{Rounding: pricing.RoundingNone})
+	return s
 }
 
 type DataConvert struct {
@@ -48,234 +137,611 @@ type ConversionResult struct {
 	From   string  `json:"from"`
 	To     string  `json:"to"`
 	Amount float64 `json:"amount"`
-	Rate   float64 `json:"rate"`
+	Rate   float64 `json:"rate"` // курс после наценки
 	Result float64 `json:"result"`
+
+	MidRate   float64 `json:"mid_rate"`   // курс до наценки
+	MarkupPct float64 `json:"markup_pct"` // примененный спред, %
+	Fee       float64 `json:"fee"`        // комиссия в валюте from, вычтена из Amount
+	RawResult float64 `json:"raw_result"` // до округления
+	Rounding  string  `json:"rounding"`
+	// Target - запрошенная сумма в валюте to для обратной конвертации (ConvertTarget)
+	Target float64 `json:"target,omitempty"`
+
+	Provenance
+
+	// Override - ручной курс, если он был применен вместо рыночного
+	Override *override.Override `json:"override,omitempty"`
+	// QuoteID - котировка, по зафиксированному курсу которой выполнена конвертация
+	QuoteID string `json:"quote_id,omitempty"`
+}
+
+// Источники курса
+const (
+	RateSourceCache    = "cache"    // из кеша Redis
+	RateSourceUpstream = "upstream" // только что от провайдера
+	RateSourceOverride = "override" // ручной курс
+	RateSourceDerived  = "derived"  // вычислен: привязка к другой валюте или одинаковые валюты
+)
+
+// Provenance - происхождение и свежесть курса
+type Provenance struct {
+	Source    string        `json:"source"`
+	Provider  string        `json:"provider,omitempty"`
+	Timestamp time.Time     `json:"rate_timestamp"` // когда курс получен у провайдера или задан вручную
+	CacheAge  time.Duration `json:"cache_age"`      // сколько курс пролежал в кеше
+	Stale     bool          `json:"stale"`          // последний известный курс с истекшим TTL
+}
+
+// Rate - курс вместе с информацией о его происхождении
+type Rate struct {
+	Value float64
+	Provenance
+	Override *override.Override
+}
+
+// UseOverrides подключает ручные курсы, они проверяются раньше кеша и провайдеров.
+// Вызывается до начала обработки запросов.
+func (s *CurrencyService) UseOverrides(manager *override.Manager) {
+	s.overrides = manager
+}
+
+// SetPricing атомарно заменяет правила наценки и комиссий
+func (s *CurrencyService) SetPricing(rules *pricing.Rules) {
+	s.pricing.Store(rules)
 }
 
 func (s *CurrencyService) GetExchangeRate(ctx context.Context, from, to string) (float64, error) {
+	rate, err := s.GetRate(ctx, from, to)
+	return rate.Value, err
+}
+
+// GetRate возвращает курс с учетом ручных override, затем кеша и провайдеров
+func (s *CurrencyService) GetRate(ctx context.Context, from, to string) (rate Rate, err error) {
+	ctx, span := tracer.Start(ctx, "CurrencyService.GetExchangeRate",
+		trace.WithAttributes(
+			attribute.String("currency.from", from),
+			attribute.String("currency.to", to),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	if from == "" || to == "" {
-		return 0, fmt.Errorf("currency codes cannot be empty")
+		return Rate{}, apperror.Validation("currency codes cannot be empty")
 	}
 	if len(from) != 3 || len(to) != 3 {
-		return 0, fmt.Errorf("currency codes must be 3 characters")
+		return Rate{}, apperror.Validation("currency codes must be 3 characters")
 	}
 	if strings.ToUpper(from) == strings.ToUpper(to) {
-		return 1.0, nil
+		return Rate{Value: 1.0, Provenance: Provenance{Source: RateSourceDerived, Timestamp: time.Now()}}, nil
 	}
-	rate, err := s.redis.GetExchangeRate(ctx, from, to)
+
+	if s.overrides != nil {
+		if res, ok := s.overrides.Resolve(from, to); ok {
+			span.SetAttributes(
+				attribute.String("override.id", res.Override.ID),
+				attribute.String("override.kind", string(res.Override.Kind)),
+			)
+			rate := Rate{
+				Value:      res.Rate,
+				Provenance: Provenance{Source: RateSourceOverride, Timestamp: res.Override.CreatedAt},
+				Override:   &res.Override,
+			}
+			if res.ViaFrom != "" {
+				// Привязка: курс выведен через рыночный, свежесть - как у рыночной части
+				if res.ViaFrom != res.ViaTo {
+					market, err := s.marketRate(ctx, res.ViaFrom, res.ViaTo)
+					if err != nil {
+						return Rate{}, err
+					}
+					rate.Value *= market.Value
+					rate.Provenance = market.Provenance
+				}
+				rate.Source = RateSourceDerived
+			}
+			logging.FromContext(ctx, s.logger).Debug("Rate override applied",
+				zap.String("from", from),
+				zap.String("to", to),
+				zap.String("override_id", res.Override.ID),
+				zap.String("kind", string(res.Override.Kind)),
+				zap.Float64("rate", rate.Value),
+			)
+			return rate, nil
+		}
+	}
+
+	return s.marketRate(ctx, from, to)
+}
+
+// marketRate - рыночный курс: кеш, затем провайдеры по порядку
+func (s *CurrencyService) marketRate(ctx context.Context, from, to string) (Rate, error) {
+	span := trace.SpanFromContext(ctx)
+	logger := logging.FromContext(ctx, s.logger)
+
+	cached, err := s.redis.GetExchangeRate(ctx, from, to)
 	if err == nil {
-		s.logger.Debug("Cache hit",
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		logger.Debug("Cache hit",
 			zap.String("from", from),
 			zap.String("to", to),
-			zap.Float64("rate", rate),
+			zap.Float64("rate", cached.Rate),
 		)
-		return rate, nil
+		return cachedRate(cached, false), nil
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		// Реальная ошибка Redis (не "не найден")
-		s.logger.Warn("Redis error (will try API)",
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	switch {
+	case errors.Is(err, cache.ErrCacheMiss):
+		// Cache miss - нормально
+		logger.Debug("Cache miss",
 			zap.String("from", from),
 			zap.String("to", to),
-			zap.Error(err),
 		)
-	} else {
-		// Cache miss - нормально
-		s.logger.Debug("Cache miss",
+	case errors.Is(err, cache.ErrUnavailable):
+		// Кеш в деградированном режиме, сразу идем в API
+		logger.Debug("Cache unavailable, skipping",
+			zap.String("from", from),
+			zap.String("to", to),
+		)
+	default:
+		// Реальная ошибка Redis
+		logger.Warn("Redis error (will try API)",
 			zap.String("from", from),
 			zap.String("to", to),
+			zap.Error(err),
 		)
 	}
-	rateAPI, err := s.FetchRateFromAPI(ctx, from, to)
+	rate, mode, err := s.fetchRate(ctx, from, to)
+	span.SetAttributes(attribute.String("quota.mode", string(mode)))
 	if err != nil {
-		return 0, fmt.Errorf("failed to get rate from API: %w", err)
+		return Rate{}, fmt.Errorf("failed to get rate from API: %w", err)
+	}
+	if mode == quota.ModeStaleOnly {
+		// Последний известный курс уже лежит в кеше, перезаписывать нечего
+		return rate, nil
 	}
+	ttl := s.quota.CacheTTL(mode, s.redis.TTL())
+	// Кешируем в фоне, сохраняя связь с трейсом запроса
+	spanCtx := trace.SpanContextFromContext(ctx)
 	go func() {
-		caheCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		caheCtx, cancel := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), spanCtx), 2*time.Second)
 		defer cancel()
-		err := s.redis.SetExchangeRate(caheCtx, from, to, rateAPI)
+		err := s.redis.SetExchangeRateWithTTL(caheCtx, from, to, cache.CachedRate{
+			Rate:      rate.Value,
+			Provider:  rate.Provider,
+			FetchedAt: rate.Timestamp,
+		}, ttl)
+		if errors.Is(err, cache.ErrUnavailable) {
+			return
+		}
 		if err != nil {
-			s.logger.Warn("Failed to cache rate (non-critical)",
+			logger.Warn("Failed to cache rate (non-critical)",
 				zap.String("from", from),
 				zap.String("to", to),
 				zap.Error(err),
 			)
+			return
 		}
-		s.logger.Debug("Rate cached in Redis",
+		logger.Debug("Rate cached in Redis",
 			zap.String("from", from),
 			zap.String("to", to),
-			zap.Float64("rate", rateAPI),
-			zap.Duration("ttl", s.config.Redis.TTL),
+			zap.Float64("rate", rate.Value),
+			zap.Duration("ttl", ttl),
 		)
 
 	}()
 
-	return rateAPI, nil
+	return rate, nil
 }
 
-// TODO: Получить курс (сначала из кеша, потом из API)
+// cachedRate - курс из кеша с его возрастом. У записей старого формата
+// нет времени получения, для них возраст и время неизвестны.
+func cachedRate(cached cache.CachedRate, stale bool) Rate {
+	return Rate{
+		Value: cached.Rate,
+		Provenance: Provenance{
+			Source:    RateSourceCache,
+			Provider:  cached.Provider,
+			Timestamp: cached.FetchedAt,
+			CacheAge:  cached.Age(time.Now()),
+			Stale:     stale,
+		},
+	}
+}
 
-func (s *CurrencyService) FetchRateFromAPI(ctx context.Context, from, to string) (float64, error) {
-	s.logger.Error("🔍 DEBUG - Проверка конфигурации:",
-		zap.String("CurrencyAPIURL", s.config.API.CurrencyAPIURL),
-		zap.String("CurrencyKeyAPI", "["+s.config.API.CurrencyKeyAPI+"]"),
-		zap.Int("KeyLength", len(s.config.API.CurrencyKeyAPI)),
+// staleRate отдает последний известный курс без обращения к upstream
+func (s *CurrencyService) staleRate(ctx context.Context, from, to string) (Rate, error) {
+	logger := logging.FromContext(ctx, s.logger)
+
+	cached, err := s.redis.GetStaleExchangeRate(ctx, from, to)
+	if err != nil {
+		logger.Warn("Upstream budget exhausted and no stale rate available",
+			zap.String("from", from),
+			zap.String("to", to),
+			zap.Error(err),
+		)
+		return Rate{}, apperror.New(apperror.KindUpstreamUnavailable,
+			"exchange rate provider budget is exhausted and no cached rate is available", err)
+	}
+
+	logger.Info("Serving stale rate, upstream budget exhausted",
+		zap.String("from", from),
+		zap.String("to", to),
+		zap.Float64("rate", cached.Rate),
+		zap.Time("fetched_at", cached.FetchedAt),
 	)
+	return cachedRate(cached, true), nil
+}
+
+// FetchTableFromAPI запрашивает у ExchangeRate-API курсы базовой валюты ко всем валютам
+func (s *CurrencyService) FetchTableFromAPI(ctx context.Context, base string) (_ map[string]float64, err error) {
+	ctx, span := tracer.Start(ctx, "CurrencyService.FetchTableFromAPI",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("currency.from", base),
+			attribute.String("server.address", s.config.API.CurrencyAPIURL),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	logger := logging.FromContext(ctx, s.logger)
 
 	// Формируем URL
 	apiURL := fmt.Sprintf("%s/v6/%s/latest/%s",
 		s.config.API.CurrencyAPIURL,
-		s.config.API.CurrencyKeyAPI,
-		from,
+		s.config.API.CurrencyKeyAPI.Value(),
+		base,
 	)
 
-	// 🔥 ДОБАВЬ ЭТУ СТРОКУ
-	s.logger.Error("🔍 DEBUG - Сформированный URL:", zap.String("full_url", apiURL))
-
-	// Маскируем ключ для логов
-	maskedURL := strings.Replace(apiURL, s.config.API.CurrencyKeyAPI, "***", 1)
+	// Ключ в URL не должен попасть ни в логи, ни в ошибки, ни в спаны
+	maskedURL := fmt.Sprintf("%s/v6/***/latest/%s", s.config.API.CurrencyAPIURL, base)
 
-	s.logger.Debug("Fetching rate from ExchangeRate-API",
-		zap.String("from", from),
-		zap.String("to", to),
+	logger.Debug("Fetching rates from ExchangeRate-API",
+		zap.String("base", base),
 		zap.String("url", maskedURL),
 	)
 
 	// === ВСЕ ЭТО ОСТАЕТСЯ БЕЗ ИЗМЕНЕНИЙ ===
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
-		s.logger.Error("Failed to create HTTP request",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("Failed to create HTTP request",
+			zap.String("base", base),
 			zap.Error(err),
 		)
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, apperror.Internal(fmt.Errorf("failed to create request: %w", err))
 	}
+	// Передаем W3C traceparent во внешний API
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
+		err = maskURLError(err, maskedURL)
 		if ctx.Err() == context.DeadlineExceeded {
-			s.logger.Error("API request timeout",
-				zap.String("from", from),
-				zap.String("to", to),
+			logger.Error("API request timeout",
+				zap.String("base", base),
 				zap.Duration("timeout", s.config.API.Timeout),
 			)
-			return 0, fmt.Errorf("API request timeout after %v", s.config.API.Timeout)
+			return nil, apperror.UpstreamUnavailable(fmt.Errorf("API request timeout after %v", s.config.API.Timeout))
 		}
 		if ctx.Err() == context.Canceled {
-			s.logger.Warn("API request canceled by client",
-				zap.String("from", from),
-				zap.String("to", to),
+			logger.Warn("API request canceled by client",
+				zap.String("base", base),
 			)
-			return 0, fmt.Errorf("API request canceled")
+			return nil, apperror.Internal(fmt.Errorf("API request canceled"))
+		}
+		if errors.Is(err, resilience.ErrCircuitOpen) {
+			logger.Warn("API request rejected, circuit breaker is open",
+				zap.String("base", base),
+				zap.String("provider", ProviderName),
+			)
+			return nil, apperror.UpstreamUnavailable(err)
 		}
 
-		s.logger.Error("API request failed",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("API request failed",
+			zap.String("base", base),
 			zap.Error(err),
 		)
-		return 0, fmt.Errorf("API request failed: %w", err)
+		return nil, apperror.UpstreamUnavailable(fmt.Errorf("API request failed: %w", err))
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		s.logger.Error("API returned error status",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("API returned error status",
+			zap.String("base", base),
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("status", resp.Status),
 			zap.String("response", string(body)),
 		)
-		return 0, fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status)
+		if upstreamErrorType(body) == "unsupported-code" {
+			return nil, apperror.UnsupportedCurrency(base)
+		}
+		return nil, apperror.UpstreamUnavailable(fmt.Errorf("API returned status %d: %s", resp.StatusCode, resp.Status))
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		s.logger.Error("Failed to read API response",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("Failed to read API response",
+			zap.String("base", base),
 			zap.Error(err),
 		)
-		return 0, fmt.Errorf("failed to read response: %w", err)
+		return nil, apperror.UpstreamUnavailable(fmt.Errorf("failed to read response: %w", err))
 	}
 
 	// === ИЗМЕНЕНО: Новая структура для ExchangeRate-API ===
 	var apiResponse struct {
 		Result          string             `json:"result"`
+		ErrorType       string             `json:"error-type"`
 		BaseCode        string             `json:"base_code"`
 		ConversionRates map[string]float64 `json:"conversion_rates"`
 	}
 
 	if err := json.Unmarshal(data, &apiResponse); err != nil {
-		s.logger.Error("Invalid JSON from ExchangeRate-API",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("Invalid JSON from ExchangeRate-API",
+			zap.String("base", base),
 			zap.String("response", string(data)),
 			zap.Error(err),
 		)
-		return 0, fmt.Errorf("invalid JSON response: %w", err)
+		return nil, apperror.UpstreamUnavailable(fmt.Errorf("invalid JSON response: %w", err))
 	}
 
 	// === ИЗМЕНЕНО: Проверяем поле "result" ===
 	if apiResponse.Result != "success" {
-		s.logger.Error("ExchangeRate-API returned error",
-			zap.String("from", from),
-			zap.String("to", to),
+		logger.Error("ExchangeRate-API returned error",
+			zap.String("base", base),
 			zap.String("result", apiResponse.Result),
+			zap.String("error_type", apiResponse.ErrorType),
 			zap.String("response", string(data)),
 		)
-		return 0, fmt.Errorf("ExchangeRate-API error: %s", apiResponse.Result)
+		if apiResponse.ErrorType == "unsupported-code" {
+			return nil, apperror.UnsupportedCurrency(base)
+		}
+		return nil, apperror.UpstreamUnavailable(fmt.Errorf("ExchangeRate-API error: %s (%s)", apiResponse.Result, apiResponse.ErrorType))
+	}
+
+	s.lastUpstreamSuccess.Store(time.Now().UnixNano())
+
+	if s.recorder != nil {
+		if err := s.recorder.RecordTable(base, apiResponse.ConversionRates); err != nil {
+			logger.Warn("Failed to record fixtures", zap.String("base", base), zap.Error(err))
+		}
 	}
+	return apiResponse.ConversionRates, nil
+}
 
-	// === ИЗМЕНЕНО: Берем из conversion_rates вместо data ===
-	rate, exists := apiResponse.ConversionRates[to]
+// FetchRateFromAPI запрашивает курс пары; из таблицы базовой валюты берется один курс
+func (s *CurrencyService) FetchRateFromAPI(ctx context.Context, from, to string) (float64, error) {
+	table, err := s.FetchTableFromAPI(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	logger := logging.FromContext(ctx, s.logger)
+	rate, exists := table[to]
 	if !exists {
 		var availableCurrencies []string
-		for currency := range apiResponse.ConversionRates {
+		for currency := range table {
 			availableCurrencies = append(availableCurrencies, currency)
 		}
 
-		s.logger.Error("Currency not found in ExchangeRate-API response",
+		logger.Error("Currency not found in ExchangeRate-API response",
 			zap.String("from", from),
 			zap.String("to", to),
 			zap.Strings("available_currencies", availableCurrencies),
 		)
-		return 0, fmt.Errorf("currency %s not found in API response", to)
+		return 0, apperror.UnsupportedCurrency(to)
 	}
 
-	s.logger.Debug("Rate successfully fetched from ExchangeRate-API",
+	logger.Debug("Rate successfully fetched from ExchangeRate-API",
 		zap.String("from", from),
 		zap.String("to", to),
 		zap.Float64("rate", rate),
 	)
-
 	return rate, nil
 }
-func (s *CurrencyService) Convert(ctx context.Context, from, to string, amount float64) (result, rate float64, err error) {
+
+// LastUpstreamSuccess возвращает время последнего успешного запроса к API
+// или нулевое время, если успешных запросов еще не было
+func (s *CurrencyService) LastUpstreamSuccess() time.Time {
+	nanos := s.lastUpstreamSuccess.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// CircuitState возвращает состояние circuit breaker провайдера
+func (s *CurrencyService) CircuitState() resilience.State {
+	return s.breaker.State()
+}
+
+// maskURLError заменяет в *url.Error адрес с ключом API на замаскированный
+func maskURLError(err error, maskedURL string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: maskedURL, Err: urlErr.Err}
+	}
+	return err
+}
+
+// upstreamErrorType достает поле "error-type" из тела ошибки ExchangeRate-API
+func upstreamErrorType(body []byte) string {
+	var payload struct {
+		ErrorType string `json:"error-type"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.ErrorType
+}
+
+// UseAudit подключает журнал конвертаций. Вызывается до начала обработки запросов.
+func (s *CurrencyService) UseAudit(store audit.Store) {
+	s.audit = store
+}
+
+// Convert конвертирует сумму по текущему курсу и записывает конвертацию в журнал
+func (s *CurrencyService) Convert(ctx context.Context, from, to string, amount float64) (*ConversionResult, error) {
+	result, err := s.convert(ctx, from, to, amount)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, result)
+	return result, nil
+}
+
+// convert считает конвертацию без записи в журнал (например, для котировки)
+func (s *CurrencyService) convert(ctx context.Context, from, to string, amount float64) (*ConversionResult, error) {
 	// Валидация суммы
 	if amount <= 0 {
-		return 0, 0, fmt.Errorf("amount must be positive, got: %.2f", amount)
+		return nil, apperror.Validation(fmt.Sprintf("amount must be positive, got: %.2f", amount))
 	}
 
 	// Получаем курс
-	rate, err = s.GetExchangeRate(ctx, from, to)
+	rate, err := s.GetRate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.price(ctx, from, to, amount, rate)
+}
+
+// ConvertRate конвертирует сумму по уже полученному курсу (например, загруженному
+// вместе с другими курсами запроса) и записывает конвертацию в журнал
+func (s *CurrencyService) ConvertRate(ctx context.Context, from, to string, amount float64, rate Rate) (*ConversionResult, error) {
+	if amount <= 0 {
+		return nil, apperror.Validation(fmt.Sprintf("amount must be positive, got: %.2f", amount))
+	}
+	result, err := s.price(ctx, from, to, amount, rate)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, result)
+	return result, nil
+}
+
+// ConvertTarget - обратная конвертация: подбирает сумму from, прямая конвертация
+// которой с наценкой, комиссией и округлением дает не меньше target в валюте to
+func (s *CurrencyService) ConvertTarget(ctx context.Context, from, to string, target float64) (*ConversionResult, error) {
+	if target <= 0 {
+		return nil, apperror.Validation(fmt.Sprintf("amount must be positive, got: %.2f", target))
+	}
+	rate, err := s.GetRate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	amount, _, err := s.pricing.Load().PriceTarget(from, to, auth.ClientFrom(ctx).Plan, target, rate.Value)
 	if err != nil {
-		return 0, 0, err
+		return nil, apperror.Validation(err.Error())
+	}
+	result, err := s.price(ctx, from, to, amount, rate)
+	if err != nil {
+		return nil, err
+	}
+	result.Target = target
+	s.recordAudit(ctx, result)
+	return result, nil
+}
+
+// price применяет к курсу наценку и комиссию по тарифу клиента
+func (s *CurrencyService) price(ctx context.Context, from, to string, amount float64, rate Rate) (*ConversionResult, error) {
+	// Применяем наценку и комиссию по тарифу клиента
+	client := auth.ClientFrom(ctx)
+	quote, err := s.pricing.Load().Price(from, to, client.Plan, amount, rate.Value)
+	if err != nil {
+		return nil, apperror.Validation(err.Error())
 	}
 
 	// Вычисляем результат
-	result = amount * rate
+	result := &ConversionResult{
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Rate:      quote.AppliedRate,
+		Result:    quote.Result,
+		MidRate:   quote.MidRate,
+		MarkupPct: quote.MarkupPct,
+		Fee:       quote.Fee,
+		RawResult: quote.RawResult,
+		Rounding:  quote.Rounding,
+		Override:  rate.Override,
+
+		Provenance: rate.Provenance,
+	}
 
-	s.logger.Info("Currency conversion completed",
+	fields := []zap.Field{
 		zap.String("from", from),
 		zap.String("to", to),
 		zap.Float64("amount", amount),
-		zap.Float64("rate", rate),
-		zap.Float64("result", result),
-	)
+		zap.Float64("mid_rate", result.MidRate),
+		zap.String("rate_source", result.Source),
+		zap.String("provider", result.Provider),
+		zap.Bool("stale", result.Stale),
+		zap.Float64("rate", result.Rate),
+		zap.Float64("fee", result.Fee),
+		zap.String("pricing_rule", quote.Source),
+		zap.String("client_id", client.ID),
+		zap.Float64("result", result.Result),
+	}
+	if rate.Override != nil {
+		fields = append(fields, zap.String("override_id", rate.Override.ID))
+	}
+	logging.FromContext(ctx, s.logger).Info("Currency conversion completed", fields...)
+
+	return result, nil
+}
 
-	return result, rate, nil
+// recordAudit пишет конвертацию в журнал. Ошибка записи не отменяет конвертацию,
+// но логируется и считается в метрике audit_write_failures_total.
+func (s *CurrencyService) recordAudit(ctx context.Context, result *ConversionResult) {
+	if s.audit == nil {
+		return
+	}
+	entry := &audit.Entry{
+		RequestID:     logging.RequestID(ctx),
+		ClientID:      auth.ClientFrom(ctx).ID,
+		From:          strings.ToUpper(result.From),
+		To:            strings.ToUpper(result.To),
+		Amount:        result.Amount,
+		MidRate:       result.MidRate,
+		Rate:          result.Rate,
+		MarkupPct:     result.MarkupPct,
+		Fee:           result.Fee,
+		RateSource:    result.Source,
+		RateProvider:  result.Provider,
+		RateTimestamp: result.Timestamp.UTC(),
+		Stale:         result.Stale,
+		RawResult:     result.RawResult,
+		Result:        result.Result,
+		Rounding:      result.Rounding,
+		QuoteID:       result.QuoteID,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if result.Override != nil {
+		entry.OverrideID = result.Override.ID
+	}
+	// Запись не должна оборваться, если клиент уже отключился
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	if err := s.audit.Append(writeCtx, entry); err != nil {
+		metrics.AuditWriteFailures.Inc()
+		logging.FromContext(ctx, s.logger).Error("Failed to write conversion audit entry",
+			zap.String("from", entry.From),
+			zap.String("to", entry.To),
+			zap.Float64("amount", entry.Amount),
+			zap.Float64("rate", entry.Rate),
+			zap.Error(err),
+		)
+	}
 }
 
 var _ CurrencyServiceInterface = (*CurrencyService)(nil)
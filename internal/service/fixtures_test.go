@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/pkg/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newOfflineService собирает настоящий CurrencyService без Redis
+func newOfflineService(t *testing.T, cfg *config.Config) *CurrencyService {
+	t.Helper()
+	logger := zap.NewNop()
+	return NewCurrencyService(cfg, nil, quota.NewTracker(cfg.Quota, nil, logger), logger)
+}
+
+func TestFixtures_RecordThenReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v6/test-key/latest/USD", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"success","base_code":"USD","conversion_rates":{"USD":1,"EUR":0.9,"GBP":0.8}}`))
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+
+	// record: настоящий API, ответ сохраняется в фикстуры
+	cfg := config.Default()
+	cfg.API.CurrencyAPIURL = upstream.URL
+	cfg.API.CurrencyKeyAPI = "test-key"
+	recorder := newOfflineService(t, cfg)
+	store, err := fixtures.Open(path)
+	require.NoError(t, err)
+	recorder.UseFixtures(store, true)
+
+	rate, err := recorder.GetExchangeRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	upstream.Close()
+
+	// replay: только фикстуры, сеть не нужна
+	replayCfg := config.Default()
+	replayCfg.API.CurrencyAPIURL = "http://127.0.0.1:1"
+	replay := newOfflineService(t, replayCfg)
+	replayStore, err := fixtures.Open(path)
+	require.NoError(t, err)
+	replay.UseFixtures(replayStore, false)
+	require.NoError(t, replay.SetProviders([]string{FixtureProviderName}))
+
+	result, err := replay.Convert(context.Background(), "USD", "GBP", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, result.Rate)
+	assert.InDelta(t, 8.0, result.Result, 1e-9)
+
+	_, err = replay.GetExchangeRate(context.Background(), "USD", "JPY")
+	assert.True(t, apperror.IsKind(err, apperror.KindUpstreamUnavailable))
+}
+
+func TestSetProviders_RejectsUnknown(t *testing.T) {
+	svc := newOfflineService(t, config.Default())
+
+	assert.ErrorContains(t, svc.SetProviders([]string{FixtureProviderName}), "unknown providers: fixture")
+	assert.Equal(t, []string{ProviderName}, svc.Providers())
+}
+
+func TestConvertMany_UsesOneRateTable(t *testing.T) {
+	var requests int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"success","base_code":"USD","conversion_rates":{"USD":1,"EUR":0.9,"GBP":0.8,"JPY":150}}`))
+	}))
+	defer upstream.Close()
+
+	cfg := config.Default()
+	cfg.API.CurrencyAPIURL = upstream.URL
+	svc := newOfflineService(t, cfg)
+	store := history.NewMemoryStore()
+	svc.UseHistory(store)
+
+	results, err := svc.ConvertMany(context.Background(), "usd", []string{"EUR", "GBP", "JPY", "XXX", "USD", "EUR"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	require.Len(t, results, 5)
+	assert.InDelta(t, 9.0, results["EUR"].Result.Result, 1e-9)
+	assert.InDelta(t, 1500.0, results["JPY"].Result.Result, 1e-9)
+	assert.Equal(t, RateSourceUpstream, results["GBP"].Result.Source)
+	assert.Equal(t, RateSourceDerived, results["USD"].Result.Source)
+	assert.True(t, apperror.IsKind(results["XXX"].Err, apperror.KindUnsupportedCurrency))
+
+	// Все курсы таблицы попадают в историю, а не только запрошенные
+	assert.Eventually(t, func() bool {
+		points, err := store.Range(context.Background(), history.Range{From: "USD", To: "GBP", Limit: 10})
+		return err == nil && len(points) == 1 && points[0].Rate == 0.8
+	}, time.Second, 10*time.Millisecond)
+	points, err := store.Range(context.Background(), history.Range{From: "USD", To: "USD", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, points)
+
+	_, err = svc.ConvertMany(context.Background(), "USD", []string{"EUR"}, 0)
+	assert.True(t, apperror.IsKind(err, apperror.KindValidation))
+}
+
+// batchStore считает вызовы Append поверх истории в памяти
+type batchStore struct {
+	*history.MemoryStore
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *batchStore) Append(ctx context.Context, points ...history.Point) error {
+	s.mu.Lock()
+	s.batches = append(s.batches, len(points))
+	s.mu.Unlock()
+	return s.MemoryStore.Append(ctx, points...)
+}
+
+func TestRecordTableHistory_OneBatch(t *testing.T) {
+	svc := newOfflineService(t, config.Default())
+	store := &batchStore{MemoryStore: history.NewMemoryStore()}
+	svc.UseHistory(store)
+
+	rates := map[string]float64{"USD": 1}
+	for i := 0; i < 160; i++ {
+		rates[fmt.Sprintf("C%02d", i)] = float64(i + 1)
+	}
+	svc.recordTableHistory(context.Background(), RateTable{Base: "USD", Rates: rates, Provenance: Provenance{Provider: ProviderName, Timestamp: time.Now()}})
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.batches) > 0
+	}, time.Second, 10*time.Millisecond)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, []int{160}, store.batches)
+}
+
+func TestRefreshTable_OneRequestPerBase(t *testing.T) {
+	var requests int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"success","base_code":"USD","conversion_rates":{"USD":1,"EUR":0.9,"GBP":0.8}}`))
+	}))
+	defer upstream.Close()
+
+	cfg := config.Default()
+	cfg.API.CurrencyAPIURL = upstream.URL
+	svc := newOfflineService(t, cfg)
+
+	table, err := svc.RefreshTable(context.Background(), "usd")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, "USD", table.Base)
+	assert.Equal(t, map[string]float64{"USD": 1, "EUR": 0.9, "GBP": 0.8}, table.Rates)
+	assert.Equal(t, RateSourceUpstream, table.Source)
+	assert.False(t, table.Stale)
+}
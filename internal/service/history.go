@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/history"
+	"currency-converter-v2/internal/logging"
+
+	"go.uber.org/zap"
+)
+
+// RateSourceHistory - курс взят из истории на заданную дату
+const RateSourceHistory = "history"
+
+// maxHistoryPoints - сколько точек истории отдается за один запрос
+const maxHistoryPoints = 1000
+
+// historyWriteTimeout - сколько фоновая запись в историю может занять
+const historyWriteTimeout = 5 * time.Second
+
+// historyBatchSize - сколько точек пишется в историю одной вставкой
+const historyBatchSize = 500
+
+// UseHistory подключает историю курсов: каждый курс, полученный у провайдера,
+// записывается в нее. Вызывается до начала обработки запросов.
+func (s *CurrencyService) UseHistory(store history.Store) {
+	s.history = store
+}
+
+// recordHistory пишет курс от провайдера в историю в фоне, не задерживая запрос
+func (s *CurrencyService) recordHistory(ctx context.Context, from, to string, rate Rate) {
+	if s.history == nil {
+		return
+	}
+	s.appendHistory(ctx, []history.Point{{
+		From:     strings.ToUpper(from),
+		To:       strings.ToUpper(to),
+		Rate:     rate.Value,
+		Provider: rate.Provider,
+		At:       rate.Timestamp,
+	}})
+}
+
+// recordTableHistory пишет в историю все курсы таблицы от провайдера
+func (s *CurrencyService) recordTableHistory(ctx context.Context, table RateTable) {
+	if s.history == nil {
+		return
+	}
+	points := make([]history.Point, 0, len(table.Rates))
+	for to, value := range table.Rates {
+		if to == table.Base {
+			continue
+		}
+		points = append(points, history.Point{
+			From:     table.Base,
+			To:       to,
+			Rate:     value,
+			Provider: table.Provider,
+			At:       table.Timestamp,
+		})
+	}
+	s.appendHistory(ctx, points)
+}
+
+// appendHistory записывает точки одной фоновой горутиной пачками по historyBatchSize.
+// Неудачная пачка не останавливает запись остальных, потерянные точки считаются в лог.
+func (s *CurrencyService) appendHistory(ctx context.Context, points []history.Point) {
+	logger := logging.FromContext(ctx, s.logger)
+	go func() {
+		writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historyWriteTimeout)
+		defer cancel()
+		dropped := 0
+		var lastErr error
+		for start := 0; start < len(points); start += historyBatchSize {
+			batch := points[start:min(start+historyBatchSize, len(points))]
+			if err := s.history.Append(writeCtx, batch...); err != nil {
+				dropped += len(batch)
+				lastErr = err
+			}
+		}
+		if dropped > 0 {
+			logger.Warn("Failed to record rate history (non-critical)",
+				zap.String("from", points[0].From),
+				zap.Int("dropped", dropped),
+				zap.Int("total", len(points)),
+				zap.Error(lastErr),
+			)
+		}
+	}()
+}
+
+// RateAt возвращает последний известный рыночный курс на момент t
+func (s *CurrencyService) RateAt(ctx context.Context, from, to string, t time.Time) (Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if len(from) != 3 || len(to) != 3 {
+		return Rate{}, apperror.Validation("currency codes must be 3 characters")
+	}
+	if from == to {
+		return Rate{Value: 1.0, Provenance: Provenance{Source: RateSourceDerived, Timestamp: t}}, nil
+	}
+	if s.history == nil {
+		return Rate{}, apperror.NotFound("rate history is not available")
+	}
+	point, ok, err := s.history.At(ctx, from, to, t)
+	if err != nil {
+		return Rate{}, apperror.Internal(fmt.Errorf("failed to read rate history: %w", err))
+	}
+	if !ok {
+		return Rate{}, apperror.NotFound(fmt.Sprintf("no rate for %s to %s at %s", from, to, t.UTC().Format(time.RFC3339)))
+	}
+	return Rate{
+		Value: point.Rate,
+		Provenance: Provenance{
+			Source:    RateSourceHistory,
+			Provider:  point.Provider,
+			Timestamp: point.At,
+		},
+	}, nil
+}
+
+// History возвращает точки истории пары за период, от старых к новым.
+// limit ограничивается maxHistoryPoints; без since отдаются последние limit точек.
+func (s *CurrencyService) History(ctx context.Context, from, to string, since, until time.Time, limit int) ([]history.Point, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if len(from) != 3 || len(to) != 3 {
+		return nil, apperror.Validation("currency codes must be 3 characters")
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return nil, apperror.Validation("since must be before until")
+	}
+	if limit <= 0 || limit > maxHistoryPoints {
+		limit = maxHistoryPoints
+	}
+	if s.history == nil {
+		return nil, nil
+	}
+	points, err := s.history.Range(ctx, history.Range{From: from, To: to, Since: since, Until: until, Limit: limit})
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to read rate history: %w", err))
+	}
+	return points, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+// RateTable - курсы базовой валюты ко всем валютам провайдера
+type RateTable struct {
+	Base  string
+	Rates map[string]float64
+	Provenance
+}
+
+// TargetResult - конвертация в одну из валют ConvertMany или ошибка по ней
+type TargetResult struct {
+	Result *ConversionResult
+	Err    error
+}
+
+// ConvertMany конвертирует одну сумму сразу в несколько валют. Рыночные курсы берутся
+// из одной таблицы базовой валюты (кеш, затем провайдеры), ручные курсы действуют как в Convert.
+// Ошибка по отдельной валюте не мешает остальным; повторы в targets схлопываются.
+func (s *CurrencyService) ConvertMany(ctx context.Context, from string, targets []string, amount float64) (map[string]TargetResult, error) {
+	if amount <= 0 {
+		return nil, apperror.Validation(fmt.Sprintf("amount must be positive, got: %.2f", amount))
+	}
+	from = strings.ToUpper(from)
+	if len(from) != 3 {
+		return nil, apperror.Validation("currency codes must be 3 characters")
+	}
+
+	// Таблица загружается один раз и только если нужен хотя бы один рыночный курс
+	var (
+		table    RateTable
+		tableErr error
+		loaded   bool
+	)
+	loadTable := func() (RateTable, error) {
+		if !loaded {
+			table, tableErr = s.GetRateTable(ctx, from)
+			loaded = true
+		}
+		return table, tableErr
+	}
+
+	results := make(map[string]TargetResult, len(targets))
+	for _, to := range targets {
+		to = strings.ToUpper(to)
+		if _, ok := results[to]; ok {
+			continue
+		}
+		rate, err := s.TableRate(ctx, from, to, loadTable)
+		if err != nil {
+			results[to] = TargetResult{Err: err}
+			continue
+		}
+		result, err := s.price(ctx, from, to, amount, rate)
+		if err != nil {
+			results[to] = TargetResult{Err: err}
+			continue
+		}
+		s.recordAudit(ctx, result)
+		results[to] = TargetResult{Result: result}
+	}
+	return results, nil
+}
+
+// TableLoader лениво загружает таблицу курсов базовой валюты, обычно одну на запрос
+type TableLoader func() (RateTable, error)
+
+// TableRate - курс from->to как в GetRate, но рыночный курс берется из таблицы базы from.
+// Так курсы одной базы к нескольким валютам стоят одного обращения к кешу или провайдеру.
+func (s *CurrencyService) TableRate(ctx context.Context, from, to string, loadTable TableLoader) (Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	for _, code := range []string{from, to} {
+		if len(code) != 3 {
+			return Rate{}, apperror.Validation(fmt.Sprintf("currency code %q must be 3 characters", code))
+		}
+	}
+	if from == to {
+		return Rate{Value: 1.0, Provenance: Provenance{Source: RateSourceDerived, Timestamp: time.Now()}}, nil
+	}
+	if s.overrides != nil {
+		if _, ok := s.overrides.Resolve(from, to); ok {
+			return s.GetRate(ctx, from, to)
+		}
+	}
+	table, err := loadTable()
+	if err != nil {
+		return Rate{}, err
+	}
+	value, ok := table.Rates[to]
+	if !ok {
+		return Rate{}, apperror.UnsupportedCurrency(to)
+	}
+	return Rate{Value: value, Provenance: table.Provenance}, nil
+}
+
+// GetRateTable возвращает таблицу курсов базовой валюты: из кеша, иначе от провайдеров
+func (s *CurrencyService) GetRateTable(ctx context.Context, base string) (RateTable, error) {
+	logger := logging.FromContext(ctx, s.logger)
+	base = strings.ToUpper(base)
+
+	cached, err := s.redis.GetRateTable(ctx, base)
+	if err == nil {
+		return cachedTable(base, cached, false), nil
+	}
+	if !errors.Is(err, cache.ErrCacheMiss) && !errors.Is(err, cache.ErrUnavailable) {
+		logger.Warn("Redis error (will try API)", zap.String("base", base), zap.Error(err))
+	}
+
+	table, mode, err := s.fetchTable(ctx, base)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("failed to get rates from API: %w", err)
+	}
+	if mode == quota.ModeStaleOnly {
+		return table, nil
+	}
+	ttl := s.quota.CacheTTL(mode, s.redis.TTL())
+	go func() {
+		cacheCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+		err := s.redis.SetRateTableWithTTL(cacheCtx, base, cache.CachedTable{
+			Rates:     table.Rates,
+			Provider:  table.Provider,
+			FetchedAt: table.Timestamp,
+		}, ttl)
+		if err != nil && !errors.Is(err, cache.ErrUnavailable) {
+			logger.Warn("Failed to cache rate table (non-critical)", zap.String("base", base), zap.Error(err))
+		}
+	}()
+	return table, nil
+}
+
+// fetchTable опрашивает провайдеров по порядку, как fetchRate, но целой таблицей
+func (s *CurrencyService) fetchTable(ctx context.Context, base string) (table RateTable, mode quota.Mode, err error) {
+	logger := logging.FromContext(ctx, s.logger)
+
+	var lastErr error
+	for _, name := range *s.providers.Load() {
+		mode = s.quota.Mode(ctx, name)
+		if mode == quota.ModeStaleOnly {
+			logger.Debug("Provider budget exhausted, skipping", zap.String("provider", name))
+			continue
+		}
+
+		rates, err := s.tables[name](ctx, base)
+		if err == nil {
+			table = RateTable{
+				Base:  base,
+				Rates: rates,
+				Provenance: Provenance{
+					Source:    RateSourceUpstream,
+					Provider:  name,
+					Timestamp: time.Now().UTC(),
+				},
+			}
+			s.recordTableHistory(ctx, table)
+			return table, mode, nil
+		}
+		if !apperror.IsKind(err, apperror.KindUpstreamUnavailable) {
+			return RateTable{}, mode, err
+		}
+		lastErr = err
+		logger.Warn("Provider unavailable, trying next",
+			zap.String("provider", name),
+			zap.Error(err),
+		)
+	}
+
+	if lastErr != nil {
+		return RateTable{}, mode, lastErr
+	}
+	cached, err := s.redis.GetStaleRateTable(ctx, base)
+	if err != nil {
+		logger.Warn("Upstream budget exhausted and no stale rate table available",
+			zap.String("base", base),
+			zap.Error(err),
+		)
+		return RateTable{}, quota.ModeStaleOnly, apperror.New(apperror.KindUpstreamUnavailable,
+			"exchange rate provider budget is exhausted and no cached rate is available", err)
+	}
+	return cachedTable(base, cached, true), quota.ModeStaleOnly, nil
+}
+
+// cachedTable - таблица из кеша с ее возрастом
+func cachedTable(base string, cached cache.CachedTable, stale bool) RateTable {
+	return RateTable{
+		Base:  base,
+		Rates: cached.Rates,
+		Provenance: Provenance{
+			Source:    RateSourceCache,
+			Provider:  cached.Provider,
+			Timestamp: cached.FetchedAt,
+			CacheAge:  cached.Age(time.Now()),
+			Stale:     stale,
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/pkg/fixtures"
+
+	"go.uber.org/zap"
+)
+
+// FixtureProviderName - офлайн-провайдер, отдающий курсы из локальных фикстур
+const FixtureProviderName = "fixture"
+
+// rateFetcher запрашивает курс у конкретного провайдера
+type rateFetcher func(ctx context.Context, from, to string) (float64, error)
+
+// tableFetcher запрашивает у провайдера курсы базовой валюты ко всем валютам
+type tableFetcher func(ctx context.Context, base string) (map[string]float64, error)
+
+// UseFixtures подключает локальные фикстуры: регистрирует провайдера "fixture" (replay),
+// а при record=true сохраняет в них каждый успешный ответ API.
+// Вызывается до начала обработки запросов.
+func (s *CurrencyService) UseFixtures(store *fixtures.Store, record bool) {
+	s.fetchers[FixtureProviderName] = func(ctx context.Context, from, to string) (float64, error) {
+		rate, ok := store.Rate(from, to)
+		if !ok {
+			return 0, apperror.New(apperror.KindUpstreamUnavailable,
+				fmt.Sprintf("no fixture rate for %s to %s", from, to), nil)
+		}
+		logging.FromContext(ctx, s.logger).Debug("Rate served from fixtures",
+			zap.String("from", from),
+			zap.String("to", to),
+			zap.Float64("rate", rate),
+		)
+		return rate, nil
+	}
+	s.tables[FixtureProviderName] = func(ctx context.Context, base string) (map[string]float64, error) {
+		table, ok := store.Table(base)
+		if !ok {
+			return nil, apperror.New(apperror.KindUpstreamUnavailable,
+				fmt.Sprintf("no fixture rates for %s", base), nil)
+		}
+		return table, nil
+	}
+	if record {
+		s.recorder = store
+	}
+}
+
+// Providers возвращает текущий порядок опроса провайдеров
+func (s *CurrencyService) Providers() []string {
+	return append([]string(nil), *s.providers.Load()...)
+}
+
+// SetProviders атомарно меняет порядок опроса провайдеров.
+// Неизвестное имя - ошибка, текущий список при этом не меняется.
+func (s *CurrencyService) SetProviders(names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("provider list is empty")
+	}
+	var unknown []string
+	for _, name := range names {
+		if _, ok := s.fetchers[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown providers: %s", strings.Join(unknown, ", "))
+	}
+
+	list := append([]string(nil), names...)
+	s.providers.Store(&list)
+	return nil
+}
+
+// fetchRate опрашивает провайдеров по порядку. К следующему переходим, только если
+// предыдущий недоступен или исчерпал бюджет; ошибки вроде неизвестной валюты
+// возвращаются сразу. Если бюджет исчерпан у всех, отдаем последний известный курс.
+func (s *CurrencyService) fetchRate(ctx context.Context, from, to string) (rate Rate, mode quota.Mode, err error) {
+	logger := logging.FromContext(ctx, s.logger)
+
+	var lastErr error
+	for _, name := range *s.providers.Load() {
+		mode = s.quota.Mode(ctx, name)
+		if mode == quota.ModeStaleOnly {
+			logger.Debug("Provider budget exhausted, skipping", zap.String("provider", name))
+			continue
+		}
+
+		value, err := s.fetchers[name](ctx, from, to)
+		if err == nil {
+			rate = Rate{
+				Value: value,
+				Provenance: Provenance{
+					Source:    RateSourceUpstream,
+					Provider:  name,
+					Timestamp: time.Now().UTC(),
+				},
+			}
+			s.recordHistory(ctx, from, to, rate)
+			return rate, mode, nil
+		}
+		if !apperror.IsKind(err, apperror.KindUpstreamUnavailable) {
+			return Rate{}, mode, err
+		}
+		lastErr = err
+		logger.Warn("Provider unavailable, trying next",
+			zap.String("provider", name),
+			zap.Error(err),
+		)
+	}
+
+	if lastErr != nil {
+		return Rate{}, mode, lastErr
+	}
+	rate, err = s.staleRate(ctx, from, to)
+	return rate, quota.ModeStaleOnly, err
+}
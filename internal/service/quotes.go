@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"currency-converter-v2/internal/apperror"
+	"currency-converter-v2/internal/auth"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/quote"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+// UseQuotes подключает хранилище котировок; ttl - сколько котировку можно исполнить.
+// Вызывается до начала обработки запросов.
+func (s *CurrencyService) UseQuotes(store quote.Store, ttl time.Duration) {
+	s.quotes = store
+	s.quoteTTL = ttl
+}
+
+// CreateQuote считает конвертацию по текущему курсу и фиксирует результат на quoteTTL
+func (s *CurrencyService) CreateQuote(ctx context.Context, from, to string, amount float64) (*quote.Quote, error) {
+	if s.quotes == nil {
+		return nil, apperror.NotFound("quotes are disabled")
+	}
+	// Сама котировка - еще не конвертация, в журнал попадет ее исполнение
+	result, err := s.convert(ctx, from, to, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := quote.NewID()
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("generate quote id: %w", err))
+	}
+	now := time.Now().UTC()
+	q := quote.Quote{
+		ID:        id,
+		ClientID:  auth.ClientFrom(ctx).ID,
+		From:      result.From,
+		To:        result.To,
+		Amount:    result.Amount,
+		Rate:      result.Rate,
+		MidRate:   result.MidRate,
+		MarkupPct: result.MarkupPct,
+		Fee:       result.Fee,
+		Result:    result.Result,
+		RawResult: result.RawResult,
+		Rounding:  result.Rounding,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.quoteTTL),
+
+		RateSource:    result.Source,
+		RateProvider:  result.Provider,
+		RateTimestamp: result.Timestamp,
+		CacheAge:      result.CacheAge,
+		Stale:         result.Stale,
+		Override:      result.Override,
+	}
+	if err := s.quotes.Save(ctx, q); err != nil {
+		return nil, quoteStoreError(err)
+	}
+
+	logging.FromContext(ctx, s.logger).Info("Quote created",
+		zap.String("quote_id", q.ID),
+		zap.String("client_id", q.ClientID),
+		zap.String("from", q.From),
+		zap.String("to", q.To),
+		zap.Float64("rate", q.Rate),
+		zap.Time("expires_at", q.ExpiresAt),
+	)
+	return &q, nil
+}
+
+// QuoteTerms - ожидаемые условия котировки; пустые поля не проверяются
+type QuoteTerms struct {
+	From   string
+	To     string
+	Amount float64
+}
+
+// mismatch возвращает описание расхождения с котировкой или пустую строку
+func (t QuoteTerms) mismatch(q quote.Quote) string {
+	switch {
+	case t.From != "" && !strings.EqualFold(t.From, q.From),
+		t.To != "" && !strings.EqualFold(t.To, q.To),
+		t.Amount != 0 && t.Amount != q.Amount:
+		return fmt.Sprintf("quote was issued for %v %s to %s", q.Amount, q.From, q.To)
+	}
+	return ""
+}
+
+// ExecuteQuote исполняет котировку по зафиксированному курсу.
+// Чужая или истекшая котировка - not_found, повторное исполнение - conflict,
+// расхождение с terms - validation (котировка при этом не расходуется).
+func (s *CurrencyService) ExecuteQuote(ctx context.Context, id string, terms QuoteTerms) (*ConversionResult, error) {
+	if s.quotes == nil {
+		return nil, apperror.NotFound("quotes are disabled")
+	}
+	logger := logging.FromContext(ctx, s.logger)
+
+	q, err := s.quotes.Get(ctx, id)
+	if err != nil {
+		return nil, quoteStoreError(err)
+	}
+	client := auth.ClientFrom(ctx)
+	if q.ClientID != client.ID || q.Expired(time.Now()) {
+		return nil, quoteStoreError(quote.ErrNotFound)
+	}
+	if msg := terms.mismatch(q); msg != "" {
+		return nil, apperror.Validation(msg)
+	}
+	if err := s.quotes.MarkUsed(ctx, q); err != nil {
+		if errors.Is(err, quote.ErrUsed) {
+			logger.Warn("Quote reuse rejected",
+				zap.String("quote_id", q.ID),
+				zap.String("client_id", client.ID),
+			)
+		}
+		return nil, quoteStoreError(err)
+	}
+
+	logger.Info("Quote executed",
+		zap.String("quote_id", q.ID),
+		zap.String("client_id", client.ID),
+		zap.String("from", q.From),
+		zap.String("to", q.To),
+		zap.Float64("amount", q.Amount),
+		zap.Float64("rate", q.Rate),
+		zap.Float64("result", q.Result),
+	)
+	result := &ConversionResult{
+		From:      q.From,
+		To:        q.To,
+		Amount:    q.Amount,
+		Rate:      q.Rate,
+		Result:    q.Result,
+		MidRate:   q.MidRate,
+		MarkupPct: q.MarkupPct,
+		Fee:       q.Fee,
+		RawResult: q.RawResult,
+		Rounding:  q.Rounding,
+		Override:  q.Override,
+		QuoteID:   q.ID,
+
+		Provenance: Provenance{
+			Source:    q.RateSource,
+			Provider:  q.RateProvider,
+			Timestamp: q.RateTimestamp,
+			CacheAge:  q.CacheAge,
+			Stale:     q.Stale,
+		},
+	}
+	s.recordAudit(ctx, result)
+	return result, nil
+}
+
+// quoteStoreError переводит ошибки хранилища котировок в ошибки API
+func quoteStoreError(err error) error {
+	switch {
+	case errors.Is(err, quote.ErrNotFound):
+		return apperror.NotFound("quote not found or expired")
+	case errors.Is(err, quote.ErrUsed):
+		return apperror.Conflict("quote has already been executed")
+	case errors.Is(err, cache.ErrUnavailable):
+		return apperror.New(apperror.KindUpstreamUnavailable, "quote storage is temporarily unavailable", err)
+	default:
+		return apperror.Internal(err)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/quota"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+// RefreshTable запрашивает у провайдеров таблицу курсов базовой валюты в обход кеша
+// и обновляет кеш таблицы и всех пар base->валюта одним запросом к провайдеру.
+// Ручные курсы не учитываются. Если бюджет провайдеров исчерпан,
+// возвращается последняя известная таблица с пометкой Stale.
+func (s *CurrencyService) RefreshTable(ctx context.Context, base string) (RateTable, error) {
+	base = strings.ToUpper(base)
+	table, mode, err := s.fetchTable(ctx, base)
+	if err != nil {
+		return RateTable{}, err
+	}
+	if mode == quota.ModeStaleOnly {
+		return table, nil
+	}
+
+	ttl := s.quota.CacheTTL(mode, s.redis.TTL())
+	cached := cache.CachedTable{
+		Rates:     table.Rates,
+		Provider:  table.Provider,
+		FetchedAt: table.Timestamp,
+	}
+	err = s.redis.SetRateTableWithTTL(ctx, base, cached, ttl)
+	if err == nil {
+		err = s.redis.SetTableRatesWithTTL(ctx, base, cached, ttl)
+	}
+	if err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		logging.FromContext(ctx, s.logger).Warn("Failed to cache refreshed rates (non-critical)",
+			zap.String("base", base),
+			zap.Error(err),
+		)
+	}
+	return table, nil
+}
@@ -0,0 +1,329 @@
+// Package stream раздает обновления курсов подписчикам (SSE, WebSocket).
+// Обновления расходятся между инстансами через Redis pub/sub.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"currency-converter-v2/internal/refresher"
+	"currency-converter-v2/pkg/cache"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// channel - канал Redis, куда публикуются обновления
+	channel = "rates:updates"
+	// pairsKey - пары подписчиков всех инстансов, их обновляет refresher
+	pairsKey = "stream:pairs"
+	// pairsTTL - сколько пара живет в pairsKey без подтверждения
+	pairsTTL = 2 * time.Minute
+	// historySize - сколько последних событий хранится для возобновления по Last-Event-ID
+	historySize = 256
+	// bufferSize - очередь событий одного подписчика
+	bufferSize = 16
+)
+
+// Event - обновление курсов с порядковым ID
+type Event struct {
+	ID     int64            `json:"id"`
+	Update refresher.Update `json:"update"`
+}
+
+// Hub рассылает обновления локальным подписчикам
+type Hub struct {
+	redis  *cache.RedisClient
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	history []Event
+	lastID  int64
+	closed  bool
+}
+
+func NewHub(redis *cache.RedisClient, logger *zap.Logger) *Hub {
+	return &Hub{
+		redis:  redis,
+		logger: logger,
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription - подписчик на набор курсов
+type Subscription struct {
+	hub    *Hub
+	events chan Event
+
+	// Меняются под hub.mu
+	bases map[string]bool         // все обновленные курсы базы
+	pairs map[refresher.Pair]bool // отдельные пары
+}
+
+// Events - очередь событий. Закрывается при Close или если подписчик
+// не успевает их читать: клиент переподключается и догоняет по Last-Event-ID.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close отписывает подписчика
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// SetPairs заменяет набор пар подписчика; "USD/*" - все курсы базы
+func (s *Subscription) SetPairs(pairs []refresher.Pair) {
+	bases := make(map[string]bool)
+	set := make(map[refresher.Pair]bool, len(pairs))
+	for _, pair := range pairs {
+		if pair.To == refresher.AllSymbols {
+			bases[pair.From] = true
+			continue
+		}
+		set[pair] = true
+	}
+	s.hub.mu.Lock()
+	s.bases, s.pairs = bases, set
+	s.hub.mu.Unlock()
+	s.hub.registerPairs(context.Background(), pairs)
+}
+
+// Subscribe подписывает на курсы base (symbols пусто - на все обновляемые курсы базы)
+// и возвращает пропущенные события после lastEventID (0 - без возобновления).
+func (h *Hub) Subscribe(base string, symbols []string, lastEventID int64) (*Subscription, []Event) {
+	sub := h.newSubscription()
+	if len(symbols) == 0 {
+		sub.bases[base] = true
+	}
+	for _, symbol := range symbols {
+		sub.pairs[refresher.Pair{From: base, To: symbol}] = true
+	}
+	pairs := sub.pairList()
+
+	h.mu.Lock()
+	var missed []Event
+	if lastEventID > 0 {
+		for _, e := range h.history {
+			if e.ID <= lastEventID {
+				continue
+			}
+			if filtered, ok := sub.filter(e); ok {
+				missed = append(missed, filtered)
+			}
+		}
+	}
+	h.add(sub)
+	h.mu.Unlock()
+
+	h.registerPairs(context.Background(), pairs)
+	return sub, missed
+}
+
+// SubscribePairs создает подписку с пустым набором, пары задаются через SetPairs
+func (h *Hub) SubscribePairs() *Subscription {
+	sub := h.newSubscription()
+	h.mu.Lock()
+	h.add(sub)
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *Hub) newSubscription() *Subscription {
+	return &Subscription{
+		hub:    h,
+		events: make(chan Event, bufferSize),
+		bases:  make(map[string]bool),
+		pairs:  make(map[refresher.Pair]bool),
+	}
+}
+
+// add вызывается под h.mu
+func (h *Hub) add(sub *Subscription) {
+	if h.closed {
+		close(sub.events)
+		return
+	}
+	h.subs[sub] = struct{}{}
+}
+
+// Publish - обработчик refresher: отправляет обновление всем инстансам.
+// Без Redis обновление раздается только локальным подписчикам.
+func (h *Hub) Publish(ctx context.Context, u refresher.Update) {
+	h.mu.Lock()
+	id := time.Now().UnixNano()
+	if id <= h.lastID {
+		id = h.lastID + 1
+	}
+	h.lastID = id
+	h.mu.Unlock()
+
+	event := Event{ID: id, Update: u}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Failed to encode rate update", zap.Error(err))
+		return
+	}
+	err = h.redis.Publish(ctx, channel, payload)
+	if err == nil {
+		// Свои подписчики получат событие из подписки, как и остальные инстансы
+		return
+	}
+	if !errors.Is(err, cache.ErrUnavailable) {
+		h.logger.Warn("Failed to publish rate update, delivering locally only", zap.Error(err))
+	}
+	h.deliver(event)
+}
+
+// Run слушает обновления из Redis и поддерживает регистрацию пар подписчиков,
+// пока не отменен ctx. После остановки все подписки закрываются, чтобы открытые
+// потоки не держали graceful shutdown.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.close()
+	messages := h.redis.Subscribe(ctx, channel)
+	ticker := time.NewTicker(pairsTTL / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.registerPairs(ctx, h.localPairs())
+		case payload, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				h.logger.Warn("Invalid rate update in pub/sub", zap.Error(err))
+				continue
+			}
+			h.deliver(event)
+		}
+	}
+}
+
+// Pairs - пары подписчиков всех инстансов, регистрируется в refresher.Watch
+func (h *Hub) Pairs(ctx context.Context) []refresher.Pair {
+	pairs := h.localPairs()
+	members, err := h.redis.LiveMembers(ctx, pairsKey)
+	if err != nil {
+		return pairs
+	}
+	for _, member := range members {
+		if pair, ok := refresher.ParsePair(member); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+func (h *Hub) deliver(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if event.ID > h.lastID {
+		h.lastID = event.ID
+	}
+	// Одно и то же событие может прийти и локально, и из pub/sub после восстановления Redis
+	for _, e := range h.history {
+		if e.ID == event.ID {
+			return
+		}
+	}
+	h.history = append(h.history, event)
+	sort.Slice(h.history, func(i, j int) bool { return h.history[i].ID < h.history[j].ID })
+	if len(h.history) > historySize {
+		h.history = h.history[len(h.history)-historySize:]
+	}
+
+	for sub := range h.subs {
+		filtered, ok := sub.filter(event)
+		if !ok {
+			continue
+		}
+		select {
+		case sub.events <- filtered:
+		default:
+			h.logger.Debug("Stream subscriber is too slow, dropping it")
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
+
+// remove вызывается под h.mu
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.events)
+}
+
+func (h *Hub) localPairs() []refresher.Pair {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var pairs []refresher.Pair
+	for sub := range h.subs {
+		pairs = append(pairs, sub.pairList()...)
+	}
+	return pairs
+}
+
+func (h *Hub) registerPairs(ctx context.Context, pairs []refresher.Pair) {
+	for _, pair := range pairs {
+		if err := h.redis.TouchMember(ctx, pairsKey, pair.String(), pairsTTL); err != nil {
+			return
+		}
+	}
+}
+
+// filter оставляет в событии курсы подписчика; false - интересующих курсов нет.
+// Вызывается под hub.mu.
+func (s *Subscription) filter(event Event) (Event, bool) {
+	if s.bases[event.Update.Base] {
+		return event, true
+	}
+	u := event.Update
+	u.Rates = make(map[string]float64)
+	u.Providers = make(map[string]string)
+	for symbol, rate := range event.Update.Rates {
+		if s.pairs[refresher.Pair{From: u.Base, To: symbol}] {
+			u.Rates[symbol] = rate
+			u.Providers[symbol] = event.Update.Providers[symbol]
+		}
+	}
+	if len(u.Rates) == 0 {
+		return Event{}, false
+	}
+	return Event{ID: event.ID, Update: u}, true
+}
+
+// pairList вызывается под hub.mu или до регистрации подписки.
+// Подписка на всю базу регистрируется как "BASE/*": refresher обновляет всю ее таблицу.
+func (s *Subscription) pairList() []refresher.Pair {
+	pairs := make([]refresher.Pair, 0, len(s.pairs)+len(s.bases))
+	for base := range s.bases {
+		pairs = append(pairs, refresher.Pair{From: base, To: refresher.AllSymbols})
+	}
+	for pair := range s.pairs {
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
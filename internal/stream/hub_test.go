@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/refresher"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func update(base string, rates map[string]float64) refresher.Update {
+	return refresher.Update{Base: base, Rates: rates, Timestamp: time.Now().UTC()}
+}
+
+func TestHub_FiltersBySubscription(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	sub, missed := hub.Subscribe("USD", []string{"EUR"}, 0)
+	defer sub.Close()
+	assert.Empty(t, missed)
+
+	ctx := context.Background()
+	hub.Publish(ctx, update("EUR", map[string]float64{"USD": 1.1}))
+	hub.Publish(ctx, update("USD", map[string]float64{"GBP": 0.8}))
+	hub.Publish(ctx, update("USD", map[string]float64{"EUR": 0.9, "GBP": 0.8}))
+
+	event := <-sub.Events()
+	assert.Equal(t, map[string]float64{"EUR": 0.9}, event.Update.Rates)
+	assert.Empty(t, sub.Events())
+
+	assert.Equal(t, []refresher.Pair{{From: "USD", To: "EUR"}}, hub.Pairs(ctx))
+}
+
+func TestHub_ResumesFromLastEventID(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	ctx := context.Background()
+	hub.Publish(ctx, update("USD", map[string]float64{"EUR": 0.91}))
+	hub.Publish(ctx, update("USD", map[string]float64{"EUR": 0.92}))
+	hub.Publish(ctx, update("USD", map[string]float64{"EUR": 0.93}))
+
+	first := hub.history[0].ID
+	sub, missed := hub.Subscribe("USD", nil, first)
+	defer sub.Close()
+	require.Len(t, missed, 2)
+	assert.Equal(t, 0.92, missed[0].Update.Rates["EUR"])
+	assert.Equal(t, 0.93, missed[1].Update.Rates["EUR"])
+	assert.Less(t, missed[0].ID, missed[1].ID)
+}
+
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	sub, _ := hub.Subscribe("USD", nil, 0)
+
+	for i := 0; i <= bufferSize; i++ {
+		hub.Publish(context.Background(), update("USD", map[string]float64{"EUR": float64(i)}))
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, bufferSize, received, "очередь закрыта после переполнения")
+	sub.Close() // повторное закрытие безопасно
+}
+
+func TestHub_RunClosesSubscriptionsOnStop(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	sub, _ := hub.Subscribe("USD", nil, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+
+	late, _ := hub.Subscribe("USD", nil, 0)
+	_, ok = <-late.Events()
+	assert.False(t, ok, "после остановки новые подписки сразу закрыты")
+}
+
+func TestHub_BaseSubscriptionWatchesWholeTable(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	sub, _ := hub.Subscribe("USD", nil, 0)
+	defer sub.Close()
+
+	// Без этой пары refresher не знал бы о подписчике, и приходили бы только keepalive
+	assert.Equal(t, []refresher.Pair{{From: "USD", To: refresher.AllSymbols}}, hub.Pairs(context.Background()))
+
+	hub.Publish(context.Background(), update("USD", map[string]float64{"EUR": 0.9, "GBP": 0.8}))
+	event := <-sub.Events()
+	assert.Equal(t, map[string]float64{"EUR": 0.9, "GBP": 0.8}, event.Update.Rates)
+
+	sub.SetPairs([]refresher.Pair{{From: "EUR", To: refresher.AllSymbols}, {From: "USD", To: "GBP"}})
+	hub.Publish(context.Background(), update("USD", map[string]float64{"EUR": 0.9, "GBP": 0.8}))
+	event = <-sub.Events()
+	assert.Equal(t, map[string]float64{"GBP": 0.8}, event.Update.Rates)
+}
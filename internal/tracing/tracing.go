@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"currency-converter-v2/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.uber.org/zap"
+)
+
+// ShutdownFunc сбрасывает накопленные спаны и останавливает экспортер
+type ShutdownFunc func(ctx context.Context) error
+
+// Init настраивает глобальный TracerProvider и W3C propagator.
+// Если трейсинг выключен, propagator всё равно устанавливается,
+// чтобы входящий traceparent передавался дальше в upstream.
+func Init(cfg config.TracingConfig, logger *zap.Logger) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		logger.Debug("Tracing disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info("Tracing enabled",
+		zap.String("exporter", cfg.Exporter),
+		zap.String("endpoint", cfg.Endpoint),
+		zap.Float64("sample_ratio", cfg.SampleRatio),
+	)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %q", cfg.Exporter)
+	}
+}
@@ -0,0 +1,11 @@
+package version
+
+// Значения подставляются при сборке через -ldflags, например:
+//
+//	go build -ldflags "-X currency-converter-v2/internal/version.Version=v2.1.0 \
+//	  -X currency-converter-v2/internal/version.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
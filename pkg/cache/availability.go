@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+
+	"currency-converter-v2/internal/metrics"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	StateConnected = "connected"
+	StateDegraded  = "degraded"
+
+	// Как часто проверять Redis, пока он доступен
+	healthCheckInterval = 10 * time.Second
+)
+
+var (
+	// ErrUnavailable возвращается, пока Redis недоступен и кеш работает в деградированном режиме
+	ErrUnavailable = errors.New("redis is unavailable")
+	// ErrCacheMiss - значения нет в кеше
+	ErrCacheMiss = errors.New("cache miss")
+)
+
+// Available сообщает, можно ли сейчас ходить в Redis
+func (r *RedisClient) Available() bool {
+	return r != nil && r.available.Load()
+}
+
+// State возвращает текущее состояние кеша: connected или degraded
+func (r *RedisClient) State() string {
+	if r.Available() {
+		return StateConnected
+	}
+	return StateDegraded
+}
+
+// setAvailable переключает состояние и фиксирует переход в логах и метриках
+func (r *RedisClient) setAvailable(available bool, cause error) {
+	if r.available.Swap(available) == available {
+		return
+	}
+
+	if available {
+		metrics.RedisAvailable.Set(1)
+		metrics.RedisStateTransitions.WithLabelValues(StateConnected).Inc()
+		r.logger.Info("✅ Redis is reachable, cache enabled",
+			zap.String("state", StateConnected),
+		)
+		return
+	}
+
+	metrics.RedisAvailable.Set(0)
+	metrics.RedisStateTransitions.WithLabelValues(StateDegraded).Inc()
+	r.logger.Warn("Redis is unreachable, cache running in degraded mode",
+		zap.String("state", StateDegraded),
+		zap.Error(cause),
+	)
+	r.wakeMonitor()
+}
+
+// markFailure переводит кеш в деградированный режим, если ошибка - проблема соединения.
+// Ответы сервера (WRONGTYPE, OOM и т.п.) о доступности Redis ничего не говорят.
+func (r *RedisClient) markFailure(err error) {
+	if !isConnectionError(err) {
+		return
+	}
+	r.setAvailable(false, err)
+}
+
+// isConnectionError отличает сетевые ошибки и таймауты от ошибок самих команд
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	// pool.ErrPoolTimeout go-redis не экспортирует
+	return err.Error() == errPoolTimeout
+}
+
+const errPoolTimeout = "redis: connection pool timeout"
+
+func (r *RedisClient) wakeMonitor() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// monitor проверяет Redis в фоне: пока он доступен - с фиксированным интервалом,
+// после падения - с экспоненциальной задержкой и джиттером до ReconnectMaxBackoff.
+func (r *RedisClient) monitor() {
+	backoff := r.config.ReconnectMinBackoff
+
+	for {
+		wait := healthCheckInterval
+		if !r.Available() {
+			wait = jitter(backoff)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-r.done:
+			timer.Stop()
+			return
+		case <-r.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		err := r.ping()
+		if err == nil {
+			r.setAvailable(true, nil)
+			backoff = r.config.ReconnectMinBackoff
+			continue
+		}
+
+		if r.Available() {
+			r.setAvailable(false, err)
+			continue
+		}
+
+		metrics.RedisReconnectAttempts.Inc()
+		r.logger.Debug("Redis reconnect attempt failed",
+			zap.Duration("next_backoff", backoff),
+			zap.Error(err),
+		)
+		backoff *= 2
+		if backoff > r.config.ReconnectMaxBackoff {
+			backoff = r.config.ReconnectMaxBackoff
+		}
+	}
+}
+
+func (r *RedisClient) ping() error {
+	timeout := r.config.DialTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return r.client.Ping(ctx).Err()
+}
+
+// jitter возвращает случайную задержку в диапазоне [d/2, d]
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// servePong - минимальный RESP сервер, отвечающий +PONG на любую команду
+func servePong(t *testing.T, ln net.Listener) {
+	t.Helper()
+	serveRESP(t, ln, func(string) string { return "+PONG\r\n" })
+}
+
+// serveRESP - минимальный RESP сервер: reply получает имя команды и возвращает готовый ответ
+func serveRESP(t *testing.T, ln net.Listener, reply func(cmd string) string) {
+	t.Helper()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				header, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				args, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "*")))
+				// Каждый аргумент - строка длины и сама строка, первый аргумент - команда
+				var cmd string
+				for i := 0; i < args*2; i++ {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if i == 1 {
+						cmd = strings.ToUpper(strings.TrimSpace(line))
+					}
+				}
+				if _, err := conn.Write([]byte(reply(cmd))); err != nil {
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+func TestRedisClient_StartsDegradedAndReconnects(t *testing.T) {
+	// Занимаем и сразу освобождаем порт, чтобы Redis "не был поднят"
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	client, err := NewRedisClient(config.RedisConfig{
+		Mode:                ModeStandalone,
+		Addr:                addr,
+		DialTimeout:         200 * time.Millisecond,
+		ReconnectMinBackoff: 20 * time.Millisecond,
+		ReconnectMaxBackoff: 50 * time.Millisecond,
+	}, zap.NewNop())
+	require.NoError(t, err, "недоступный Redis не должен быть ошибкой создания клиента")
+	defer client.Close()
+
+	assert.Equal(t, StateDegraded, client.State())
+	_, err = client.GetExchangeRate(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	// Поднимаем "Redis" на том же адресе
+	ln, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer ln.Close()
+	go servePong(t, ln)
+
+	assert.Eventually(t, client.Available, 2*time.Second, 10*time.Millisecond,
+		"клиент должен переподключиться в фоне")
+	assert.Equal(t, StateConnected, client.State())
+}
+
+func TestRedisClient_NilIsUnavailable(t *testing.T) {
+	var client *RedisClient
+
+	assert.False(t, client.Available())
+	_, err := client.GetExchangeRate(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorIs(t, client.SetExchangeRate(context.Background(), "USD", "EUR", CachedRate{Rate: 0.9}), ErrUnavailable)
+	client.Close()
+}
+
+func TestRedisClient_CommandErrorsKeepConnected(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go serveRESP(t, ln, func(cmd string) string {
+		if cmd == "PING" {
+			return "+PONG\r\n"
+		}
+		return "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"
+	})
+
+	client, err := NewRedisClient(config.RedisConfig{
+		Mode:        ModeStandalone,
+		Addr:        ln.Addr().String(),
+		DialTimeout: 200 * time.Millisecond,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	defer client.Close()
+	require.True(t, client.Available())
+
+	// Ошибка команды возвращается вызывающему, но кеш остается включенным
+	_, err = client.GetExchangeRate(context.Background(), "USD", "EUR")
+	assert.ErrorContains(t, err, "WRONGTYPE")
+	assert.NotErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, StateConnected, client.State())
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, isConnectionError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, isConnectionError(fmt.Errorf("read: %w", io.EOF)))
+	assert.True(t, isConnectionError(context.DeadlineExceeded))
+	assert.True(t, isConnectionError(errors.New("redis: connection pool timeout")))
+
+	assert.False(t, isConnectionError(nil))
+	assert.False(t, isConnectionError(context.Canceled))
+	assert.False(t, isConnectionError(errors.New("OOM command not allowed when used memory > 'maxmemory'")))
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// newUniversalClient создает клиент под нужную топологию Redis.
+// Режим задается явно, а не угадывается по числу адресов, как в redis.NewUniversalClient.
+func newUniversalClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	opts, err := universalOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Mode {
+	case ModeStandalone, "":
+		return redis.NewClient(opts.Simple()), nil
+	case ModeSentinel:
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown redis mode: %q", cfg.Mode)
+	}
+}
+
+func universalOptions(cfg config.RedisConfig) (*redis.UniversalOptions, error) {
+	addrs := cfg.Addrs
+	if len(addrs) == 0 && cfg.Addr != "" {
+		addrs = []string{cfg.Addr}
+	}
+
+	switch cfg.Mode {
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires master name")
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires at least one sentinel address")
+		}
+	case ModeCluster:
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires at least one node address")
+		}
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("redis cluster does not support DB %d", cfg.DB)
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redis.UniversalOptions{
+		Addrs:            addrs,
+		MasterName:       cfg.MasterName,
+		DB:               cfg.DB,
+		Username:         cfg.Username,
+		Password:         cfg.Password.Value(),
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword.Value(),
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+// newTLSConfig собирает tls.Config из файлов CA и клиентского сертификата
+func newTLSConfig(cfg config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates in redis CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
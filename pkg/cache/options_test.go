@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"testing"
+
+	"currency-converter-v2/internal/config"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUniversalClient_Modes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      config.RedisConfig
+		wantType interface{}
+	}{
+		{
+			name:     "Standalone",
+			cfg:      config.RedisConfig{Mode: ModeStandalone, Addr: "localhost:6379"},
+			wantType: &redis.Client{},
+		},
+		{
+			name: "Sentinel",
+			cfg: config.RedisConfig{
+				Mode:       ModeSentinel,
+				Addrs:      []string{"sentinel-1:26379", "sentinel-2:26379"},
+				MasterName: "mymaster",
+			},
+			wantType: &redis.Client{},
+		},
+		{
+			name:     "Cluster",
+			cfg:      config.RedisConfig{Mode: ModeCluster, Addrs: []string{"node-1:6379", "node-2:6379"}},
+			wantType: &redis.ClusterClient{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := newUniversalClient(tc.cfg)
+			require.NoError(t, err)
+			defer client.Close()
+
+			assert.IsType(t, tc.wantType, client)
+		})
+	}
+}
+
+func TestUniversalOptions_Validation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     config.RedisConfig
+		wantErr string
+	}{
+		{
+			name:    "SentinelWithoutMaster",
+			cfg:     config.RedisConfig{Mode: ModeSentinel, Addrs: []string{"sentinel:26379"}},
+			wantErr: "master name",
+		},
+		{
+			name:    "ClusterWithDB",
+			cfg:     config.RedisConfig{Mode: ModeCluster, Addrs: []string{"node:6379"}, DB: 2},
+			wantErr: "does not support DB",
+		},
+		{
+			name:    "MissingCAFile",
+			cfg:     config.RedisConfig{Addr: "localhost:6379", TLS: config.RedisTLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"}},
+			wantErr: "CA file",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := universalOptions(tc.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+
+	_, err := newUniversalClient(config.RedisConfig{Mode: "ring"})
+	assert.ErrorContains(t, err, "unknown redis mode")
+}
+
+func TestUniversalOptions_TLSAndACL(t *testing.T) {
+	opts, err := universalOptions(config.RedisConfig{
+		Mode:             ModeSentinel,
+		Addrs:            []string{"sentinel:26379"},
+		MasterName:       "mymaster",
+		Username:         "app",
+		Password:         "secret",
+		SentinelPassword: "sentinel-secret",
+		PoolSize:         50,
+		TLS:              config.RedisTLSConfig{Enabled: true, ServerName: "redis.internal"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", opts.Username)
+	assert.Equal(t, "sentinel-secret", opts.SentinelPassword)
+	assert.Equal(t, 50, opts.PoolSize)
+	require.NotNil(t, opts.TLSConfig)
+	assert.Equal(t, "redis.internal", opts.TLSConfig.ServerName)
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// Publish отправляет сообщение в канал pub/sub
+func (r *RedisClient) Publish(ctx context.Context, channel string, payload []byte) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "PUBLISH", channel)
+	defer span.End()
+
+	if err := r.client.Publish(ctx, channel, payload).Err(); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Subscribe слушает канал pub/sub, пока не отменен ctx. Пока Redis недоступен,
+// сообщений нет; после переподключения подписка восстанавливается сама.
+// Сообщения, опубликованные во время разрыва, теряются.
+func (r *RedisClient) Subscribe(ctx context.Context, channel string) <-chan []byte {
+	out := make(chan []byte, 64)
+	if r == nil {
+		go func() {
+			<-ctx.Done()
+			close(out)
+		}()
+		return out
+	}
+
+	go func() {
+		defer close(out)
+		for ctx.Err() == nil {
+			if !r.Available() {
+				if sleepContext(ctx, time.Second) != nil {
+					return
+				}
+				continue
+			}
+			r.receive(ctx, channel, out)
+		}
+	}()
+	return out
+}
+
+// receive читает подписку до ошибки соединения или отмены ctx
+func (r *RedisClient) receive(ctx context.Context, channel string, out chan<- []byte) {
+	sub := r.client.Subscribe(ctx, channel)
+	defer sub.Close()
+
+	for {
+		msg, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Warn("Redis subscription interrupted", zap.String("channel", channel), zap.Error(err))
+				r.markFailure(err)
+				_ = sleepContext(ctx, time.Second)
+			}
+			return
+		}
+		select {
+		case out <- []byte(msg.Payload):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// TouchMember добавляет элемент в множество key, где он живет ttl с последнего обновления
+func (r *RedisClient) TouchMember(ctx context.Context, key, member string, ttl time.Duration) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "ZADD", key)
+	defer span.End()
+
+	expires := float64(time.Now().Add(ttl).UnixMilli())
+	if err := r.client.ZAdd(ctx, key, &redis.Z{Score: expires, Member: member}).Err(); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to add %s to %s: %w", member, key, err)
+	}
+	return nil
+}
+
+// LiveMembers возвращает элементы множества key, чей ttl еще не истек, и удаляет истекшие
+func (r *RedisClient) LiveMembers(ctx context.Context, key string) ([]string, error) {
+	if !r.Available() {
+		return nil, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "ZRANGEBYSCORE", key)
+	defer span.End()
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+now)
+	members := pipe.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: now, Max: "+inf"})
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return members.Val(), nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
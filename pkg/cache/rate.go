@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachedRate - курс в кеше вместе с тем, откуда и когда он получен
+type CachedRate struct {
+	Rate      float64   `json:"rate"`
+	Provider  string    `json:"provider,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Age возвращает возраст курса; для записей без времени получения - 0
+func (c CachedRate) Age(now time.Time) time.Duration {
+	if c.FetchedAt.IsZero() {
+		return 0
+	}
+	return now.Sub(c.FetchedAt)
+}
+
+func encodeRate(rate CachedRate) ([]byte, error) {
+	return json.Marshal(rate)
+}
+
+// decodeRate читает запись кеша. Старые записи - просто число без метаданных,
+// они остаются читаемыми, пока не истечет их TTL.
+func decodeRate(value string) (CachedRate, error) {
+	if strings.HasPrefix(value, "{") {
+		var rate CachedRate
+		if err := json.Unmarshal([]byte(value), &rate); err != nil {
+			return CachedRate{}, err
+		}
+		return rate, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return CachedRate{}, fmt.Errorf("neither JSON nor a number: %w", err)
+	}
+	return CachedRate{Rate: rate}, nil
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedRate_RoundTrip(t *testing.T) {
+	fetched := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	data, err := encodeRate(CachedRate{Rate: 0.92, Provider: "exchangerate-api", FetchedAt: fetched})
+	require.NoError(t, err)
+
+	rate, err := decodeRate(string(data))
+	require.NoError(t, err)
+	assert.Equal(t, 0.92, rate.Rate)
+	assert.Equal(t, "exchangerate-api", rate.Provider)
+	assert.True(t, fetched.Equal(rate.FetchedAt))
+	assert.Equal(t, 90*time.Second, rate.Age(fetched.Add(90*time.Second)))
+}
+
+func TestCachedRate_LegacyPlainNumber(t *testing.T) {
+	// Так курс хранился раньше - голым числом
+	rate, err := decodeRate("0.8523")
+	require.NoError(t, err)
+	assert.Equal(t, 0.8523, rate.Rate)
+	assert.Empty(t, rate.Provider)
+	assert.Zero(t, rate.Age(time.Now()))
+
+	_, err = decodeRate("not-a-rate")
+	assert.Error(t, err)
+}
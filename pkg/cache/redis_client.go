@@ -3,106 +3,332 @@ package cache
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"currency-converter-v2/internal/config"
+	"currency-converter-v2/internal/logging"
+	"currency-converter-v2/internal/metrics"
 
 	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("currency-converter-v2/pkg/cache")
+
+// startSpan открывает клиентский спан для команды Redis
+func startSpan(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "redis."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.redis.key", key),
+		),
+	)
+}
+
 type RedisClient struct {
-	// TODO: Добавить поля структуры
-	client *redis.Client
+	client redis.UniversalClient
 	config config.RedisConfig
 	logger *zap.Logger
-}
 
-// NewRedisClient создает новый Redis клиент
-func NewRedisClient(cfg config.RedisConfig, logger *zap.Logger) (*RedisClient, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	// TTL меняются при перезагрузке конфигурации без пересоздания клиента
+	ttl      atomic.Int64
+	staleTTL atomic.Int64
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	available atomic.Bool
+	wake      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+// NewRedisClient создает новый Redis клиент (standalone, sentinel или cluster).
+// Недоступность Redis при старте не является ошибкой: клиент стартует
+// в деградированном режиме и переподключается в фоне.
+func NewRedisClient(cfg config.RedisConfig, logger *zap.Logger) (*RedisClient, error) {
+	client, err := newUniversalClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis configuration: %w", err)
 	}
 
-	logger.Info("✅ Connected to Redis",
-		zap.String("addr", cfg.Addr),
-		zap.Int("db", cfg.DB),
-	)
-
-	return &RedisClient{
+	r := &RedisClient{
 		client: client,
 		config: cfg,
 		logger: logger,
-	}, nil
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	r.SetTTL(cfg.TTL, cfg.StaleTTL)
+	metrics.RedisAvailable.Set(0)
+
+	if err := r.ping(); err != nil {
+		logger.Warn("Redis is unreachable at startup, cache running in degraded mode",
+			zap.String("mode", cfg.Mode),
+			zap.String("addr", cfg.Addr),
+			zap.Strings("addrs", cfg.Addrs),
+			zap.Error(err),
+		)
+	} else {
+		r.available.Store(true)
+		metrics.RedisAvailable.Set(1)
+		logger.Info("✅ Connected to Redis",
+			zap.String("mode", cfg.Mode),
+			zap.String("addr", cfg.Addr),
+			zap.Strings("addrs", cfg.Addrs),
+			zap.Int("db", cfg.DB),
+			zap.Bool("tls", cfg.TLS.Enabled),
+		)
+	}
+
+	go r.monitor()
+
+	return r, nil
 }
 
 // GetExchangeRate получает курс валюты из Redis
-func (r *RedisClient) GetExchangeRate(ctx context.Context, from, to string) (float64, error) {
-	key := fmt.Sprintf("rate:%s:%s", from, to)
+func (r *RedisClient) GetExchangeRate(ctx context.Context, from, to string) (CachedRate, error) {
+	return r.getRate(ctx, rateKey(from, to), from, to)
+}
+
+// GetStaleExchangeRate получает последний известный курс, даже если основной TTL истек.
+// Используется, когда запросы к upstream запрещены бюджетом.
+func (r *RedisClient) GetStaleExchangeRate(ctx context.Context, from, to string) (CachedRate, error) {
+	return r.getRate(ctx, staleRateKey(from, to), from, to)
+}
+
+func (r *RedisClient) getRate(ctx context.Context, key, from, to string) (CachedRate, error) {
+	if !r.Available() {
+		return CachedRate{}, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "GET", key)
+	defer span.End()
+
 	valueStr, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return 0, fmt.Errorf("exchange rate not found for %s to %s", from, to)
+			span.SetAttributes(attribute.Bool("cache.hit", false))
+			return CachedRate{}, fmt.Errorf("exchange rate not found for %s to %s: %w", from, to, ErrCacheMiss)
 		}
-		r.logger.Error("Redis GET error",
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logging.FromContext(ctx, r.logger).Error("Redis GET error",
 			zap.String("key", key),
 			zap.Error(err))
-		return 0, err
+		return CachedRate{}, err
 	}
-	value, err := strconv.ParseFloat(valueStr, 64)
+	span.SetAttributes(attribute.Bool("cache.hit", true))
+	value, err := decodeRate(valueStr)
 	if err != nil {
-		r.logger.Error("Redis GET error",
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid value")
+		logging.FromContext(ctx, r.logger).Error("Redis GET error",
 			zap.String("key", key),
 			zap.Error(err))
-		return 0, fmt.Errorf("invalid exchange rate format: %w", err)
+		return CachedRate{}, fmt.Errorf("invalid exchange rate format: %w", err)
 	}
-	// TODO: Реализовать получение курса
 	return value, nil
 }
 
-// SetExchangeRate устанавливает курс валюты в Redis
-func (r *RedisClient) SetExchangeRate(ctx context.Context, from, to string, rate float64) error {
-	key := fmt.Sprintf("rate:%s:%s", from, to)
-	err := r.client.Set(ctx, key, rate, r.config.TTL).Err()
+// SetExchangeRate устанавливает курс валюты в Redis с TTL из конфигурации
+func (r *RedisClient) SetExchangeRate(ctx context.Context, from, to string, rate CachedRate) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	return r.SetExchangeRateWithTTL(ctx, from, to, rate, r.TTL())
+}
+
+// TTL - текущий TTL курсов в кеше
+func (r *RedisClient) TTL() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return time.Duration(r.ttl.Load())
+}
+
+// SetTTL меняет TTL для последующих записей; уже сохраненные ключи не трогает
+func (r *RedisClient) SetTTL(ttl, staleTTL time.Duration) {
+	if r == nil {
+		return
+	}
+	r.ttl.Store(int64(ttl))
+	r.staleTTL.Store(int64(staleTTL))
+}
+
+// SetExchangeRateWithTTL сохраняет курс с метаданными и указанным TTL.
+// Вместе с ним обновляется "stale" копия с длинным StaleTTL.
+func (r *RedisClient) SetExchangeRateWithTTL(ctx context.Context, from, to string, rate CachedRate, ttl time.Duration) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	key := rateKey(from, to)
+	ctx, span := startSpan(ctx, "SET", key)
+	defer span.End()
+
+	value, err := encodeRate(rate)
+	if err != nil {
+		return fmt.Errorf("caching error: %w", err)
+	}
+
+	// Обычный pipeline, а не MULTI: в cluster ключи могут лежать в разных слотах
+	pipe := r.client.Pipeline()
+	pipe.Set(ctx, key, value, ttl)
+	if staleTTL := time.Duration(r.staleTTL.Load()); staleTTL > 0 {
+		pipe.Set(ctx, staleRateKey(from, to), value, staleTTL)
+	}
+	_, err = pipe.Exec(ctx)
 	if err != nil {
-		r.logger.Error("Redis SET error",
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logging.FromContext(ctx, r.logger).Error("Redis SET error",
 			zap.String("key", key),
 			zap.Error(err))
 		return fmt.Errorf("caching error: %w", err)
 	}
-	r.logger.Debug("Exchange rate saved to Redis",
+	logging.FromContext(ctx, r.logger).Debug("Exchange rate saved to Redis",
 		zap.String("key", key),
-		zap.Float64("rate", rate),
-		zap.Duration("ttl", r.config.TTL),
+		zap.Float64("rate", rate.Rate),
+		zap.String("provider", rate.Provider),
+		zap.Duration("ttl", ttl),
 	)
-	// TODO: Реализовать сохранение курса
 	return nil
 }
 
+// IncrementCounter увеличивает счетчик и при первом создании ставит ему TTL
+func (r *RedisClient) IncrementCounter(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if !r.Available() {
+		return 0, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "INCR", key)
+	defer span.End()
+
+	value, err := r.client.Incr(ctx, key).Result()
+	if err == nil && value == 1 {
+		err = r.client.Expire(ctx, key, ttl).Err()
+	}
+	if err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to increment counter: %w", err)
+	}
+	return value, nil
+}
+
+// GetCounter возвращает значение счетчика, отсутствующий ключ - это 0
+func (r *RedisClient) GetCounter(ctx context.Context, key string) (int64, error) {
+	if !r.Available() {
+		return 0, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "GET", key)
+	defer span.End()
+
+	value, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, fmt.Errorf("failed to read counter: %w", err)
+	}
+	return value, nil
+}
+
+// SetValue сохраняет произвольное значение с TTL
+func (r *RedisClient) SetValue(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "SET", key)
+	defer span.End()
+
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetValueIfAbsent сохраняет значение, только если ключа еще нет (SET NX).
+// false - ключ уже существовал.
+func (r *RedisClient) SetValueIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if !r.Available() {
+		return false, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "SETNX", key)
+	defer span.End()
+
+	ok, err := r.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// GetValue возвращает значение ключа или ErrCacheMiss
+func (r *RedisClient) GetValue(ctx context.Context, key string) ([]byte, error) {
+	if !r.Available() {
+		return nil, ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "GET", key)
+	defer span.End()
+
+	value, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("%s: %w", key, ErrCacheMiss)
+	}
+	if err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func rateKey(from, to string) string {
+	return fmt.Sprintf("rate:%s:%s", from, to)
+}
+
+func staleRateKey(from, to string) string {
+	return fmt.Sprintf("rate:stale:%s:%s", from, to)
+}
+
 // DeleteExchangeRate удаляет курс из Redis
 func (r *RedisClient) DeleteExchangeRate(ctx context.Context, from, to string) error {
-	key := fmt.Sprintf("rate:%s:%s", from, to)
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	key := rateKey(from, to)
 
-	err := r.client.Del(ctx, key).Err()
+	pipe := r.client.Pipeline()
+	pipe.Del(ctx, key)
+	pipe.Del(ctx, staleRateKey(from, to))
+	_, err := pipe.Exec(ctx)
 	if err != nil {
-		r.logger.Error("Failed to delete exchange rate",
+		r.markFailure(err)
+		logging.FromContext(ctx, r.logger).Error("Failed to delete exchange rate",
 			zap.String("key", key),
 			zap.Error(err),
 		)
 		return fmt.Errorf("failed to delete rate: %w", err)
 	}
 
-	r.logger.Debug("Exchange rate deleted from cache",
+	logging.FromContext(ctx, r.logger).Debug("Exchange rate deleted from cache",
 		zap.String("key", key),
 	)
 
@@ -113,19 +339,24 @@ func (r *RedisClient) DeleteExchangeRate(ctx context.Context, from, to string) e
 func (r *RedisClient) HealthCheck(ctx context.Context) error {
 	err := r.client.Ping(ctx).Err()
 	if err != nil {
-		r.logger.Warn("Redis health check failed",
+		r.markFailure(err)
+		logging.FromContext(ctx, r.logger).Warn("Redis health check failed",
 			zap.Error(err),
 		)
 		return fmt.Errorf("redis health check failed: %w", err)
 	}
 
+	r.setAvailable(true, nil)
 	return nil
 }
 
-// Close закрывает подключение к Redis
+// Close останавливает фоновое переподключение и закрывает подключение к Redis
 func (r *RedisClient) Close() {
 	if r == nil || r.client == nil {
 		return
 	}
-	r.client.Close() // Просто вызываем, не проверяем ошибку
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.client.Close() // Просто вызываем, не проверяем ошибку
+	})
 }
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"currency-converter-v2/internal/logging"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// CachedTable - курсы базовой валюты ко всем валютам провайдера одним ответом
+type CachedTable struct {
+	Rates     map[string]float64 `json:"rates"`
+	Provider  string             `json:"provider,omitempty"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Age возвращает возраст таблицы
+func (c CachedTable) Age(now time.Time) time.Duration {
+	if c.FetchedAt.IsZero() {
+		return 0
+	}
+	return now.Sub(c.FetchedAt)
+}
+
+// GetRateTable получает таблицу курсов базовой валюты
+func (r *RedisClient) GetRateTable(ctx context.Context, base string) (CachedTable, error) {
+	return r.getTable(ctx, tableKey(base))
+}
+
+// GetStaleRateTable получает последнюю известную таблицу, даже если основной TTL истек
+func (r *RedisClient) GetStaleRateTable(ctx context.Context, base string) (CachedTable, error) {
+	return r.getTable(ctx, staleTableKey(base))
+}
+
+func (r *RedisClient) getTable(ctx context.Context, key string) (CachedTable, error) {
+	value, err := r.GetValue(ctx, key)
+	if err != nil {
+		return CachedTable{}, err
+	}
+	var table CachedTable
+	if err := json.Unmarshal(value, &table); err != nil {
+		return CachedTable{}, fmt.Errorf("invalid rate table format: %w", err)
+	}
+	return table, nil
+}
+
+// SetRateTableWithTTL сохраняет таблицу с указанным TTL и "stale" копию с StaleTTL
+func (r *RedisClient) SetRateTableWithTTL(ctx context.Context, base string, table CachedTable, ttl time.Duration) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	key := tableKey(base)
+	ctx, span := startSpan(ctx, "SET", key)
+	defer span.End()
+
+	value, err := json.Marshal(table)
+	if err != nil {
+		return fmt.Errorf("caching error: %w", err)
+	}
+
+	pipe := r.client.Pipeline()
+	pipe.Set(ctx, key, value, ttl)
+	if staleTTL := time.Duration(r.staleTTL.Load()); staleTTL > 0 {
+		pipe.Set(ctx, staleTableKey(base), value, staleTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logging.FromContext(ctx, r.logger).Error("Redis SET error",
+			zap.String("key", key),
+			zap.Error(err))
+		return fmt.Errorf("caching error: %w", err)
+	}
+	logging.FromContext(ctx, r.logger).Debug("Rate table saved to Redis",
+		zap.String("key", key),
+		zap.Int("rates", len(table.Rates)),
+		zap.String("provider", table.Provider),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+// SetTableRatesWithTTL раскладывает таблицу по ключам пар base->валюта одним pipeline,
+// как SetExchangeRateWithTTL для каждой пары: с TTL и "stale" копией
+func (r *RedisClient) SetTableRatesWithTTL(ctx context.Context, base string, table CachedTable, ttl time.Duration) error {
+	if !r.Available() {
+		return ErrUnavailable
+	}
+	ctx, span := startSpan(ctx, "SET", rateKey(base, "*"))
+	defer span.End()
+
+	staleTTL := time.Duration(r.staleTTL.Load())
+	pipe := r.client.Pipeline()
+	for to, rate := range table.Rates {
+		if to == base {
+			continue
+		}
+		value, err := encodeRate(CachedRate{Rate: rate, Provider: table.Provider, FetchedAt: table.FetchedAt})
+		if err != nil {
+			return fmt.Errorf("caching error: %w", err)
+		}
+		pipe.Set(ctx, rateKey(base, to), value, ttl)
+		if staleTTL > 0 {
+			pipe.Set(ctx, staleRateKey(base, to), value, staleTTL)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.markFailure(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logging.FromContext(ctx, r.logger).Error("Redis SET error",
+			zap.String("base", base),
+			zap.Error(err))
+		return fmt.Errorf("caching error: %w", err)
+	}
+	logging.FromContext(ctx, r.logger).Debug("Exchange rates saved to Redis",
+		zap.String("base", base),
+		zap.Int("rates", len(table.Rates)),
+		zap.String("provider", table.Provider),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+func tableKey(base string) string {
+	return fmt.Sprintf("rates:table:%s", base)
+}
+
+func staleTableKey(base string) string {
+	return fmt.Sprintf("rates:table:stale:%s", base)
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"currency-converter-v2/internal/config"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // регистрирует драйвер "pgx"
+	"go.uber.org/zap"
+)
+
+type Postgres struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewPostgres открывает пул соединений с PostgreSQL и проверяет доступность базы
+func NewPostgres(cfg config.DatabaseConfig, logger *zap.Logger) (*Postgres, error) {
+	db, err := sql.Open("pgx", cfg.DSN.Value())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	logger.Info("✅ Connected to PostgreSQL")
+
+	return &Postgres{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// DB возвращает пул соединений для репозиториев
+func (p *Postgres) DB() *sql.DB {
+	return p.db
+}
+
+// HealthCheck проверяет доступность базы
+func (p *Postgres) HealthCheck(ctx context.Context) error {
+	if err := p.db.PingContext(ctx); err != nil {
+		p.logger.Warn("Database health check failed", zap.Error(err))
+		return fmt.Errorf("database health check failed: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает пул соединений
+func (p *Postgres) Close() {
+	if p == nil || p.db == nil {
+		return
+	}
+	p.db.Close()
+}
@@ -0,0 +1,235 @@
+// Package fixtures хранит таблицы курсов в локальных файлах (JSON или CSV).
+// Используется как офлайн-провайдер курсов (replay) и как приемник
+// ответов настоящего API (record).
+package fixtures
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File - формат JSON фикстуры: таблицы курсов по базовой валюте
+//
+//	{"recorded_at": "...", "rates": {"USD": {"EUR": 0.92, "GBP": 0.79}}}
+//
+// CSV фикстура содержит те же данные построчно: base,quote,rate
+type File struct {
+	RecordedAt time.Time                     `json:"recorded_at,omitempty"`
+	Rates      map[string]map[string]float64 `json:"rates"`
+}
+
+type Store struct {
+	path string
+
+	mu         sync.RWMutex
+	rates      map[string]map[string]float64
+	recordedAt time.Time
+}
+
+// Open загружает фикстуры из файла. Отсутствующий файл - пустое хранилище,
+// его создаст первая запись в режиме record.
+func Open(path string) (*Store, error) {
+	if _, err := format(path); err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, rates: make(map[string]map[string]float64)}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixtures: %w", err)
+	}
+	defer f.Close()
+
+	if err := s.decode(f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Rate возвращает курс from->to; если есть только обратная пара, курс вычисляется из нее
+func (s *Store) Rate(from, to string) (float64, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if rate, ok := s.rates[from][to]; ok {
+		return rate, true
+	}
+	if rate, ok := s.rates[to][from]; ok && rate != 0 {
+		return 1 / rate, true
+	}
+	return 0, false
+}
+
+// Table возвращает курсы base ко всем известным валютам, включая вычисленные из обратных пар
+func (s *Store) Table(base string) (map[string]float64, bool) {
+	base = strings.ToUpper(base)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table := make(map[string]float64, len(s.rates[base]))
+	for quote, rates := range s.rates {
+		if rate, ok := rates[base]; ok && rate != 0 {
+			table[quote] = 1 / rate
+		}
+	}
+	// Прямые курсы важнее вычисленных
+	for quote, rate := range s.rates[base] {
+		table[quote] = rate
+	}
+	return table, len(table) > 0
+}
+
+// Len - количество пар в хранилище
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, table := range s.rates {
+		n += len(table)
+	}
+	return n
+}
+
+// RecordTable добавляет (или заменяет) курсы базовой валюты и сохраняет файл
+func (s *Store) RecordTable(base string, rates map[string]float64) error {
+	base = strings.ToUpper(base)
+	s.mu.Lock()
+	table := s.rates[base]
+	if table == nil {
+		table = make(map[string]float64, len(rates))
+		s.rates[base] = table
+	}
+	for quote, rate := range rates {
+		table[strings.ToUpper(quote)] = rate
+	}
+	s.recordedAt = time.Now().UTC()
+	s.mu.Unlock()
+
+	return s.Save()
+}
+
+// Save атомарно перезаписывает файл фикстур
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".fixtures-*")
+	if err != nil {
+		return fmt.Errorf("failed to save fixtures: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	s.mu.RLock()
+	err = s.encode(tmp)
+	s.mu.RUnlock()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save fixtures: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func format(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return "json", nil
+	case ".csv":
+		return "csv", nil
+	default:
+		return "", fmt.Errorf("unsupported fixtures format %q (use .json or .csv)", ext)
+	}
+}
+
+func (s *Store) decode(r io.Reader) error {
+	kind, _ := format(s.path)
+	if kind == "json" {
+		var file File
+		if err := json.NewDecoder(r).Decode(&file); err != nil {
+			return err
+		}
+		for base, table := range file.Rates {
+			for quote, rate := range table {
+				s.set(base, quote, rate)
+			}
+		}
+		s.recordedAt = file.RecordedAt
+		return nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "base") {
+			continue // заголовок
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid rate %q", i+1, record[2])
+		}
+		s.set(strings.TrimSpace(record[0]), strings.TrimSpace(record[1]), rate)
+	}
+	return nil
+}
+
+func (s *Store) set(base, quote string, rate float64) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if s.rates[base] == nil {
+		s.rates[base] = make(map[string]float64)
+	}
+	s.rates[base][quote] = rate
+}
+
+func (s *Store) encode(w io.Writer) error {
+	kind, _ := format(s.path)
+	if kind == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(File{RecordedAt: s.recordedAt, Rates: s.rates})
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"base", "quote", "rate"}); err != nil {
+		return err
+	}
+	for _, base := range sortedKeys(s.rates) {
+		for _, quote := range sortedKeys(s.rates[base]) {
+			rate := strconv.FormatFloat(s.rates[base][quote], 'g', -1, 64)
+			if err := writer.Write([]string{base, quote, rate}); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
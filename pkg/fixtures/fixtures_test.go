@@ -0,0 +1,62 @@
+package fixtures
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_RecordAndReopen(t *testing.T) {
+	for _, name := range []string{"rates.json", "rates.csv"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures", name)
+
+			store, err := Open(path)
+			require.NoError(t, err)
+			assert.Equal(t, 0, store.Len())
+
+			require.NoError(t, store.RecordTable("usd", map[string]float64{"EUR": 0.5, "GBP": 0.25}))
+
+			reopened, err := Open(path)
+			require.NoError(t, err)
+			assert.Equal(t, 2, reopened.Len())
+
+			rate, ok := reopened.Rate("USD", "EUR")
+			require.True(t, ok)
+			assert.Equal(t, 0.5, rate)
+
+			// Обратная пара вычисляется из прямой
+			rate, ok = reopened.Rate("GBP", "USD")
+			require.True(t, ok)
+			assert.Equal(t, 4.0, rate)
+
+			_, ok = reopened.Rate("EUR", "JPY")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestOpen_HandwrittenCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	require.NoError(t, os.WriteFile(path, []byte("# курсы для CI\nbase,quote,rate\nUSD,EUR,0.92\n"), 0o600))
+
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	rate, ok := store.Rate("usd", "eur")
+	require.True(t, ok)
+	assert.Equal(t, 0.92, rate)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	_, err := Open("rates.yaml")
+	assert.ErrorContains(t, err, "unsupported fixtures format")
+
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	require.NoError(t, os.WriteFile(path, []byte("USD,EUR,abc\n"), 0o600))
+	_, err = Open(path)
+	assert.ErrorContains(t, err, "invalid rate")
+}
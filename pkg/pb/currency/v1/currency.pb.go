@@ -0,0 +1,831 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: currency/v1/currency.proto
+
+// gRPC API конвертера. Поведение совпадает с REST (/api/v1/convert и /api/v1/stream):
+// наценка по тарифу клиента, журнал конвертаций, те же коды ошибок.
+// Ключ клиента передается в metadata x-api-key.
+
+package currencyv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ConvertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_currency_v1_currency_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConvertRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type ConvertResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	From   string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To     string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Amount float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Курс после наценки
+	Rate      float64 `protobuf:"fixed64,4,opt,name=rate,proto3" json:"rate,omitempty"`
+	Result    float64 `protobuf:"fixed64,5,opt,name=result,proto3" json:"result,omitempty"`
+	MidRate   float64 `protobuf:"fixed64,6,opt,name=mid_rate,json=midRate,proto3" json:"mid_rate,omitempty"`
+	MarkupPct float64 `protobuf:"fixed64,7,opt,name=markup_pct,json=markupPct,proto3" json:"markup_pct,omitempty"`
+	// Комиссия в валюте from
+	Fee float64 `protobuf:"fixed64,8,opt,name=fee,proto3" json:"fee,omitempty"`
+	// Когда курс получен у провайдера
+	RateTimestamp *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=rate_timestamp,json=rateTimestamp,proto3" json:"rate_timestamp,omitempty"`
+	// cache, upstream, override или derived
+	Source          string  `protobuf:"bytes,10,opt,name=source,proto3" json:"source,omitempty"`
+	Provider        string  `protobuf:"bytes,11,opt,name=provider,proto3" json:"provider,omitempty"`
+	CacheAgeSeconds float64 `protobuf:"fixed64,12,opt,name=cache_age_seconds,json=cacheAgeSeconds,proto3" json:"cache_age_seconds,omitempty"`
+	Stale           bool    `protobuf:"varint,13,opt,name=stale,proto3" json:"stale,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_currency_v1_currency_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConvertResponse) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetResult() float64 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetMidRate() float64 {
+	if x != nil {
+		return x.MidRate
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetMarkupPct() float64 {
+	if x != nil {
+		return x.MarkupPct
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetRateTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RateTimestamp
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetCacheAgeSeconds() float64 {
+	if x != nil {
+		return x.CacheAgeSeconds
+	}
+	return 0
+}
+
+func (x *ConvertResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+type GetRateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRateRequest) Reset() {
+	*x = GetRateRequest{}
+	mi := &file_currency_v1_currency_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRateRequest) ProtoMessage() {}
+
+func (x *GetRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRateRequest.ProtoReflect.Descriptor instead.
+func (*GetRateRequest) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRateRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetRateRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetRateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Rate          float64                `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRateResponse) Reset() {
+	*x = GetRateResponse{}
+	mi := &file_currency_v1_currency_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRateResponse) ProtoMessage() {}
+
+func (x *GetRateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRateResponse.ProtoReflect.Descriptor instead.
+func (*GetRateResponse) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetRateResponse) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetRateResponse) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetRateResponse) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type BatchConvertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversions   []*ConvertRequest      `protobuf:"bytes,1,rep,name=conversions,proto3" json:"conversions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertRequest) Reset() {
+	*x = BatchConvertRequest{}
+	mi := &file_currency_v1_currency_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertRequest) ProtoMessage() {}
+
+func (x *BatchConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertRequest.ProtoReflect.Descriptor instead.
+func (*BatchConvertRequest) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchConvertRequest) GetConversions() []*ConvertRequest {
+	if x != nil {
+		return x.Conversions
+	}
+	return nil
+}
+
+type BatchConvertResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// В том же порядке, что и conversions
+	Results       []*BatchConvertResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertResponse) Reset() {
+	*x = BatchConvertResponse{}
+	mi := &file_currency_v1_currency_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertResponse) ProtoMessage() {}
+
+func (x *BatchConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertResponse.ProtoReflect.Descriptor instead.
+func (*BatchConvertResponse) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchConvertResponse) GetResults() []*BatchConvertResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchConvertResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*BatchConvertResult_Conversion
+	//	*BatchConvertResult_Error
+	Outcome       isBatchConvertResult_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertResult) Reset() {
+	*x = BatchConvertResult{}
+	mi := &file_currency_v1_currency_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertResult) ProtoMessage() {}
+
+func (x *BatchConvertResult) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertResult.ProtoReflect.Descriptor instead.
+func (*BatchConvertResult) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchConvertResult) GetOutcome() isBatchConvertResult_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *BatchConvertResult) GetConversion() *ConvertResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchConvertResult_Conversion); ok {
+			return x.Conversion
+		}
+	}
+	return nil
+}
+
+func (x *BatchConvertResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchConvertResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isBatchConvertResult_Outcome interface {
+	isBatchConvertResult_Outcome()
+}
+
+type BatchConvertResult_Conversion struct {
+	Conversion *ConvertResponse `protobuf:"bytes,1,opt,name=conversion,proto3,oneof"`
+}
+
+type BatchConvertResult_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchConvertResult_Conversion) isBatchConvertResult_Outcome() {}
+
+func (*BatchConvertResult_Error) isBatchConvertResult_Outcome() {}
+
+// Error - ошибка одной конвертации в пакете; code как в REST (validation, unsupported_currency, ...)
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_currency_v1_currency_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type WatchRatesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Base  string                 `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	// Пусто - все обновляемые курсы базы
+	Symbols       []string `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRatesRequest) Reset() {
+	*x = WatchRatesRequest{}
+	mi := &file_currency_v1_currency_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRatesRequest) ProtoMessage() {}
+
+func (x *WatchRatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRatesRequest.ProtoReflect.Descriptor instead.
+func (*WatchRatesRequest) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchRatesRequest) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *WatchRatesRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type RateUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Base          string                 `protobuf:"bytes,2,opt,name=base,proto3" json:"base,omitempty"`
+	Rates         map[string]float64     `protobuf:"bytes,3,rep,name=rates,proto3" json:"rates,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateUpdate) Reset() {
+	*x = RateUpdate{}
+	mi := &file_currency_v1_currency_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateUpdate) ProtoMessage() {}
+
+func (x *RateUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_currency_v1_currency_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateUpdate.ProtoReflect.Descriptor instead.
+func (*RateUpdate) Descriptor() ([]byte, []int) {
+	return file_currency_v1_currency_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RateUpdate) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *RateUpdate) GetBase() string {
+	if x != nil {
+		return x.Base
+	}
+	return ""
+}
+
+func (x *RateUpdate) GetRates() map[string]float64 {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+func (x *RateUpdate) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+var File_currency_v1_currency_proto protoreflect.FileDescriptor
+
+const file_currency_v1_currency_proto_rawDesc = "" +
+	"\n" +
+	"\x1acurrency/v1/currency.proto\x12\vcurrency.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"L\n" +
+	"\x0eConvertRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\"\xfe\x02\n" +
+	"\x0fConvertResponse\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x12\n" +
+	"\x04rate\x18\x04 \x01(\x01R\x04rate\x12\x16\n" +
+	"\x06result\x18\x05 \x01(\x01R\x06result\x12\x19\n" +
+	"\bmid_rate\x18\x06 \x01(\x01R\amidRate\x12\x1d\n" +
+	"\n" +
+	"markup_pct\x18\a \x01(\x01R\tmarkupPct\x12\x10\n" +
+	"\x03fee\x18\b \x01(\x01R\x03fee\x12A\n" +
+	"\x0erate_timestamp\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\rrateTimestamp\x12\x16\n" +
+	"\x06source\x18\n" +
+	" \x01(\tR\x06source\x12\x1a\n" +
+	"\bprovider\x18\v \x01(\tR\bprovider\x12*\n" +
+	"\x11cache_age_seconds\x18\f \x01(\x01R\x0fcacheAgeSeconds\x12\x14\n" +
+	"\x05stale\x18\r \x01(\bR\x05stale\"4\n" +
+	"\x0eGetRateRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\"I\n" +
+	"\x0fGetRateResponse\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\x01R\x04rate\"T\n" +
+	"\x13BatchConvertRequest\x12=\n" +
+	"\vconversions\x18\x01 \x03(\v2\x1b.currency.v1.ConvertRequestR\vconversions\"Q\n" +
+	"\x14BatchConvertResponse\x129\n" +
+	"\aresults\x18\x01 \x03(\v2\x1f.currency.v1.BatchConvertResultR\aresults\"\x8b\x01\n" +
+	"\x12BatchConvertResult\x12>\n" +
+	"\n" +
+	"conversion\x18\x01 \x01(\v2\x1c.currency.v1.ConvertResponseH\x00R\n" +
+	"conversion\x12*\n" +
+	"\x05error\x18\x02 \x01(\v2\x12.currency.v1.ErrorH\x00R\x05errorB\t\n" +
+	"\aoutcome\"5\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"A\n" +
+	"\x11WatchRatesRequest\x12\x12\n" +
+	"\x04base\x18\x01 \x01(\tR\x04base\x12\x18\n" +
+	"\asymbols\x18\x02 \x03(\tR\asymbols\"\xe9\x01\n" +
+	"\n" +
+	"RateUpdate\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12\x12\n" +
+	"\x04base\x18\x02 \x01(\tR\x04base\x128\n" +
+	"\x05rates\x18\x03 \x03(\v2\".currency.v1.RateUpdate.RatesEntryR\x05rates\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x1a8\n" +
+	"\n" +
+	"RatesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x012\xbb\x02\n" +
+	"\x0fCurrencyService\x12D\n" +
+	"\aConvert\x12\x1b.currency.v1.ConvertRequest\x1a\x1c.currency.v1.ConvertResponse\x12D\n" +
+	"\aGetRate\x12\x1b.currency.v1.GetRateRequest\x1a\x1c.currency.v1.GetRateResponse\x12S\n" +
+	"\fBatchConvert\x12 .currency.v1.BatchConvertRequest\x1a!.currency.v1.BatchConvertResponse\x12G\n" +
+	"\n" +
+	"WatchRates\x12\x1e.currency.v1.WatchRatesRequest\x1a\x17.currency.v1.RateUpdate0\x01B5Z3currency-converter-v2/pkg/pb/currency/v1;currencyv1b\x06proto3"
+
+var (
+	file_currency_v1_currency_proto_rawDescOnce sync.Once
+	file_currency_v1_currency_proto_rawDescData []byte
+)
+
+func file_currency_v1_currency_proto_rawDescGZIP() []byte {
+	file_currency_v1_currency_proto_rawDescOnce.Do(func() {
+		file_currency_v1_currency_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_currency_v1_currency_proto_rawDesc), len(file_currency_v1_currency_proto_rawDesc)))
+	})
+	return file_currency_v1_currency_proto_rawDescData
+}
+
+var file_currency_v1_currency_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_currency_v1_currency_proto_goTypes = []any{
+	(*ConvertRequest)(nil),        // 0: currency.v1.ConvertRequest
+	(*ConvertResponse)(nil),       // 1: currency.v1.ConvertResponse
+	(*GetRateRequest)(nil),        // 2: currency.v1.GetRateRequest
+	(*GetRateResponse)(nil),       // 3: currency.v1.GetRateResponse
+	(*BatchConvertRequest)(nil),   // 4: currency.v1.BatchConvertRequest
+	(*BatchConvertResponse)(nil),  // 5: currency.v1.BatchConvertResponse
+	(*BatchConvertResult)(nil),    // 6: currency.v1.BatchConvertResult
+	(*Error)(nil),                 // 7: currency.v1.Error
+	(*WatchRatesRequest)(nil),     // 8: currency.v1.WatchRatesRequest
+	(*RateUpdate)(nil),            // 9: currency.v1.RateUpdate
+	nil,                           // 10: currency.v1.RateUpdate.RatesEntry
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_currency_v1_currency_proto_depIdxs = []int32{
+	11, // 0: currency.v1.ConvertResponse.rate_timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 1: currency.v1.BatchConvertRequest.conversions:type_name -> currency.v1.ConvertRequest
+	6,  // 2: currency.v1.BatchConvertResponse.results:type_name -> currency.v1.BatchConvertResult
+	1,  // 3: currency.v1.BatchConvertResult.conversion:type_name -> currency.v1.ConvertResponse
+	7,  // 4: currency.v1.BatchConvertResult.error:type_name -> currency.v1.Error
+	10, // 5: currency.v1.RateUpdate.rates:type_name -> currency.v1.RateUpdate.RatesEntry
+	11, // 6: currency.v1.RateUpdate.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 7: currency.v1.CurrencyService.Convert:input_type -> currency.v1.ConvertRequest
+	2,  // 8: currency.v1.CurrencyService.GetRate:input_type -> currency.v1.GetRateRequest
+	4,  // 9: currency.v1.CurrencyService.BatchConvert:input_type -> currency.v1.BatchConvertRequest
+	8,  // 10: currency.v1.CurrencyService.WatchRates:input_type -> currency.v1.WatchRatesRequest
+	1,  // 11: currency.v1.CurrencyService.Convert:output_type -> currency.v1.ConvertResponse
+	3,  // 12: currency.v1.CurrencyService.GetRate:output_type -> currency.v1.GetRateResponse
+	5,  // 13: currency.v1.CurrencyService.BatchConvert:output_type -> currency.v1.BatchConvertResponse
+	9,  // 14: currency.v1.CurrencyService.WatchRates:output_type -> currency.v1.RateUpdate
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_currency_v1_currency_proto_init() }
+func file_currency_v1_currency_proto_init() {
+	if File_currency_v1_currency_proto != nil {
+		return
+	}
+	file_currency_v1_currency_proto_msgTypes[6].OneofWrappers = []any{
+		(*BatchConvertResult_Conversion)(nil),
+		(*BatchConvertResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_currency_v1_currency_proto_rawDesc), len(file_currency_v1_currency_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_currency_v1_currency_proto_goTypes,
+		DependencyIndexes: file_currency_v1_currency_proto_depIdxs,
+		MessageInfos:      file_currency_v1_currency_proto_msgTypes,
+	}.Build()
+	File_currency_v1_currency_proto = out.File
+	file_currency_v1_currency_proto_goTypes = nil
+	file_currency_v1_currency_proto_depIdxs = nil
+}
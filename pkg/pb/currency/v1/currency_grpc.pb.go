@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: currency/v1/currency.proto
+
+// gRPC API конвертера. Поведение совпадает с REST (/api/v1/convert и /api/v1/stream):
+// наценка по тарифу клиента, журнал конвертаций, те же коды ошибок.
+// Ключ клиента передается в metadata x-api-key.
+
+package currencyv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CurrencyService_Convert_FullMethodName      = "/currency.v1.CurrencyService/Convert"
+	CurrencyService_GetRate_FullMethodName      = "/currency.v1.CurrencyService/GetRate"
+	CurrencyService_BatchConvert_FullMethodName = "/currency.v1.CurrencyService/BatchConvert"
+	CurrencyService_WatchRates_FullMethodName   = "/currency.v1.CurrencyService/WatchRates"
+)
+
+// CurrencyServiceClient is the client API for CurrencyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CurrencyServiceClient interface {
+	// Convert конвертирует сумму по текущему курсу
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// GetRate возвращает средний рыночный курс пары без наценки
+	GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*GetRateResponse, error)
+	// BatchConvert выполняет несколько конвертаций; ошибка одной не прерывает остальные
+	BatchConvert(ctx context.Context, in *BatchConvertRequest, opts ...grpc.CallOption) (*BatchConvertResponse, error)
+	// WatchRates присылает обновления курсов базы после каждого фонового обновления
+	WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error)
+}
+
+type currencyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCurrencyServiceClient(cc grpc.ClientConnInterface) CurrencyServiceClient {
+	return &currencyServiceClient{cc}
+}
+
+func (c *currencyServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, CurrencyService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*GetRateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRateResponse)
+	err := c.cc.Invoke(ctx, CurrencyService_GetRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) BatchConvert(ctx context.Context, in *BatchConvertRequest, opts ...grpc.CallOption) (*BatchConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchConvertResponse)
+	err := c.cc.Invoke(ctx, CurrencyService_BatchConvert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *currencyServiceClient) WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CurrencyService_ServiceDesc.Streams[0], CurrencyService_WatchRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRatesRequest, RateUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CurrencyService_WatchRatesClient = grpc.ServerStreamingClient[RateUpdate]
+
+// CurrencyServiceServer is the server API for CurrencyService service.
+// All implementations must embed UnimplementedCurrencyServiceServer
+// for forward compatibility.
+type CurrencyServiceServer interface {
+	// Convert конвертирует сумму по текущему курсу
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// GetRate возвращает средний рыночный курс пары без наценки
+	GetRate(context.Context, *GetRateRequest) (*GetRateResponse, error)
+	// BatchConvert выполняет несколько конвертаций; ошибка одной не прерывает остальные
+	BatchConvert(context.Context, *BatchConvertRequest) (*BatchConvertResponse, error)
+	// WatchRates присылает обновления курсов базы после каждого фонового обновления
+	WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error
+	mustEmbedUnimplementedCurrencyServiceServer()
+}
+
+// UnimplementedCurrencyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCurrencyServiceServer struct{}
+
+func (UnimplementedCurrencyServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedCurrencyServiceServer) GetRate(context.Context, *GetRateRequest) (*GetRateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRate not implemented")
+}
+func (UnimplementedCurrencyServiceServer) BatchConvert(context.Context, *BatchConvertRequest) (*BatchConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchConvert not implemented")
+}
+func (UnimplementedCurrencyServiceServer) WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRates not implemented")
+}
+func (UnimplementedCurrencyServiceServer) mustEmbedUnimplementedCurrencyServiceServer() {}
+func (UnimplementedCurrencyServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeCurrencyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CurrencyServiceServer will
+// result in compilation errors.
+type UnsafeCurrencyServiceServer interface {
+	mustEmbedUnimplementedCurrencyServiceServer()
+}
+
+func RegisterCurrencyServiceServer(s grpc.ServiceRegistrar, srv CurrencyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCurrencyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CurrencyService_ServiceDesc, srv)
+}
+
+func _CurrencyService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CurrencyService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_GetRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).GetRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CurrencyService_GetRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).GetRate(ctx, req.(*GetRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_BatchConvert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CurrencyServiceServer).BatchConvert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CurrencyService_BatchConvert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CurrencyServiceServer).BatchConvert(ctx, req.(*BatchConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CurrencyService_WatchRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CurrencyServiceServer).WatchRates(m, &grpc.GenericServerStream[WatchRatesRequest, RateUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CurrencyService_WatchRatesServer = grpc.ServerStreamingServer[RateUpdate]
+
+// CurrencyService_ServiceDesc is the grpc.ServiceDesc for CurrencyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CurrencyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "currency.v1.CurrencyService",
+	HandlerType: (*CurrencyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _CurrencyService_Convert_Handler,
+		},
+		{
+			MethodName: "GetRate",
+			Handler:    _CurrencyService_GetRate_Handler,
+		},
+		{
+			MethodName: "BatchConvert",
+			Handler:    _CurrencyService_BatchConvert_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRates",
+			Handler:       _CurrencyService_WatchRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "currency/v1/currency.proto",
+}
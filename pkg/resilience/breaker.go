@@ -0,0 +1,154 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State - состояние circuit breaker
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen возвращается без обращения к upstream, пока breaker разомкнут
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerConfig struct {
+	FailureThreshold int           // подряд идущих ошибок до размыкания
+	OpenTimeout      time.Duration // сколько держать breaker открытым до пробных запросов
+	HalfOpenRequests int           // успешных пробных запросов для замыкания
+}
+
+// CircuitBreaker - классический breaker closed -> open -> half-open -> closed.
+// В half-open пропускается не больше HalfOpenRequests одновременных запросов.
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+	now       func() time.Time
+
+	onStateChange func(name string, from, to State)
+}
+
+func NewCircuitBreaker(name string, cfg BreakerConfig, onStateChange func(name string, from, to State)) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &CircuitBreaker{
+		name:          name,
+		config:        cfg,
+		now:           time.Now,
+		onStateChange: onStateChange,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// State возвращает текущее состояние с учетом истекшего OpenTimeout
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshLocked()
+	return b.state
+}
+
+// Allow решает, можно ли выполнить запрос. После Allow обязательно вызвать Done.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshLocked()
+
+	switch b.state {
+	case StateOpen:
+		return ErrCircuitOpen
+	case StateHalfOpen:
+		if b.inFlight >= b.config.HalfOpenRequests {
+			return ErrCircuitOpen
+		}
+	}
+	b.inFlight++
+	return nil
+}
+
+// Done сообщает результат запроса, разрешенного через Allow
+func (b *CircuitBreaker) Done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.inFlight > 0 {
+		b.inFlight--
+	}
+
+	if success {
+		b.failures = 0
+		if b.state == StateHalfOpen {
+			b.successes++
+			if b.successes >= b.config.HalfOpenRequests {
+				b.setStateLocked(StateClosed)
+			}
+		}
+		return
+	}
+
+	switch b.state {
+	case StateHalfOpen:
+		b.setStateLocked(StateOpen)
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.setStateLocked(StateOpen)
+		}
+	}
+}
+
+// refreshLocked переводит open -> half-open по истечении OpenTimeout
+func (b *CircuitBreaker) refreshLocked() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setStateLocked(StateHalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) setStateLocked(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	b.failures = 0
+	b.successes = 0
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+	if b.onStateChange != nil {
+		b.onStateChange(b.name, from, to)
+	}
+}
@@ -0,0 +1,211 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RetryConfig struct {
+	MaxRetries    int           // дополнительных попыток после первой
+	BaseDelay     time.Duration // задержка перед первой повторной попыткой
+	MaxDelay      time.Duration // потолок экспоненциальной задержки
+	MaxRetryAfter time.Duration // больше этого Retry-After не ждем, а сразу отдаем 429
+}
+
+// Hooks позволяют снаружи навесить логи и метрики, не завязывая пакет на zap/prometheus
+type Hooks struct {
+	OnAttempt func(req *http.Request) // перед каждой попыткой, включая повторы
+	OnRetry   func(attempt int, reason string, delay time.Duration)
+}
+
+// Transport - http.RoundTripper с circuit breaker и повторами.
+// Breaker стоит снаружи повторов: одна серия попыток - один успех или одна ошибка.
+type Transport struct {
+	base    http.RoundTripper
+	breaker *CircuitBreaker
+	retry   RetryConfig
+	hooks   Hooks
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func NewTransport(base http.RoundTripper, breaker *CircuitBreaker, retry RetryConfig, hooks Hooks) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:    base,
+		breaker: breaker,
+		retry:   retry,
+		hooks:   hooks,
+		sleep:   sleepContext,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.breaker != nil {
+		if err := t.breaker.Allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.roundTripWithRetry(req)
+
+	if t.breaker != nil {
+		t.breaker.Done(!isFailure(resp, err))
+	}
+	return resp, err
+}
+
+func (t *Transport) roundTripWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := isIdempotent(req)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("request body cannot be replayed for retry")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		if t.hooks.OnAttempt != nil {
+			t.hooks.OnAttempt(attemptReq)
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if !retryable || attempt >= t.retry.MaxRetries {
+			return resp, err
+		}
+
+		delay, reason, retry := t.retryDecision(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Дочитываем тело, чтобы соединение вернулось в пул
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if t.hooks.OnRetry != nil {
+			t.hooks.OnRetry(attempt+1, reason, delay)
+		}
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDecision определяет, стоит ли повторять запрос и сколько ждать
+func (t *Transport) retryDecision(attempt int, resp *http.Response, err error) (time.Duration, string, bool) {
+	if err != nil {
+		if !isTransientError(err) {
+			return 0, "", false
+		}
+		return t.backoff(attempt), "network", true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			delay = t.backoff(attempt)
+		}
+		if t.retry.MaxRetryAfter > 0 && delay > t.retry.MaxRetryAfter {
+			return 0, "", false
+		}
+		return delay, "rate_limited", true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusInternalServerError:
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok &&
+			(t.retry.MaxRetryAfter <= 0 || delay <= t.retry.MaxRetryAfter) {
+			return delay, "status_" + strconv.Itoa(resp.StatusCode), true
+		}
+		return t.backoff(attempt), "status_" + strconv.Itoa(resp.StatusCode), true
+	}
+	return 0, "", false
+}
+
+// backoff - экспоненциальная задержка с full jitter: rand(0, min(MaxDelay, Base*2^attempt))
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.retry.BaseDelay << attempt
+	if delay <= 0 || (t.retry.MaxDelay > 0 && delay > t.retry.MaxDelay) {
+		delay = t.retry.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// retryAfter разбирает Retry-After в секундах или в формате HTTP-даты
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// isTransientError отделяет сетевые сбои от отмены запроса клиентом
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// isFailure - что считается ошибкой для breaker: сетевые сбои и 5xx.
+// 429 и 4xx означают, что upstream жив, и breaker не размыкают.
+func isFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= 500
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,260 @@
+package resilience
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedServer отвечает по заранее заданному сценарию, по одному шагу на запрос.
+// После окончания сценария повторяет последний шаг.
+type scriptedServer struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+type step func(w http.ResponseWriter)
+
+func status(code int, headers ...string) step {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+// dropConnection обрывает соединение без ответа
+func dropConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func newScriptedServer(t *testing.T, steps ...step) *scriptedServer {
+	t.Helper()
+	s := &scriptedServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(s.hits.Add(1)) - 1
+		if i >= len(steps) {
+			i = len(steps) - 1
+		}
+		steps[i](w)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// recordingSleep подменяет ожидание, чтобы тесты не спали по-настоящему
+type recordingSleep struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (r *recordingSleep) sleep(ctx context.Context, d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delays = append(r.delays, d)
+	return ctx.Err()
+}
+
+func newTestTransport(breaker *CircuitBreaker, retry RetryConfig) (*Transport, *recordingSleep, *[]string) {
+	sleeper := &recordingSleep{}
+	var reasons []string
+	transport := NewTransport(nil, breaker, retry, Hooks{
+		OnRetry: func(attempt int, reason string, delay time.Duration) {
+			reasons = append(reasons, reason)
+		},
+	})
+	transport.sleep = sleeper.sleep
+	return transport, sleeper, &reasons
+}
+
+func get(t *testing.T, transport http.RoundTripper, url string) (*http.Response, error) {
+	t.Helper()
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(url)
+	if err == nil {
+		t.Cleanup(func() { resp.Body.Close() })
+	}
+	return resp, err
+}
+
+var defaultRetry = RetryConfig{
+	MaxRetries:    3,
+	BaseDelay:     10 * time.Millisecond,
+	MaxDelay:      100 * time.Millisecond,
+	MaxRetryAfter: 5 * time.Second,
+}
+
+func TestTransport_RetriesTransient5xx(t *testing.T) {
+	server := newScriptedServer(t,
+		status(http.StatusServiceUnavailable),
+		status(http.StatusBadGateway),
+		status(http.StatusOK),
+	)
+	transport, sleeper, reasons := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), server.hits.Load())
+	assert.Equal(t, []string{"status_503", "status_502"}, *reasons)
+	for _, delay := range sleeper.delays {
+		assert.LessOrEqual(t, delay, defaultRetry.MaxDelay, "задержка не должна превышать MaxDelay")
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	server := newScriptedServer(t, status(http.StatusGatewayTimeout))
+	transport, _, _ := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Equal(t, int32(defaultRetry.MaxRetries+1), server.hits.Load())
+}
+
+func TestTransport_RetriesDroppedConnection(t *testing.T) {
+	server := newScriptedServer(t, dropConnection, status(http.StatusOK))
+	transport, _, reasons := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"network"}, *reasons)
+}
+
+func TestTransport_HonorsRetryAfter(t *testing.T) {
+	server := newScriptedServer(t,
+		status(http.StatusTooManyRequests, "Retry-After", "3"),
+		status(http.StatusOK),
+	)
+	transport, sleeper, reasons := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{3 * time.Second}, sleeper.delays)
+	assert.Equal(t, []string{"rate_limited"}, *reasons)
+}
+
+func TestTransport_RetryAfterTooLong(t *testing.T) {
+	server := newScriptedServer(t,
+		status(http.StatusTooManyRequests, "Retry-After", "3600"),
+		status(http.StatusOK),
+	)
+	transport, _, _ := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), server.hits.Load())
+}
+
+func TestTransport_DoesNotRetryClientErrorsOrPost(t *testing.T) {
+	server := newScriptedServer(t, status(http.StatusBadRequest))
+	transport, _, _ := newTestTransport(nil, defaultRetry)
+
+	resp, err := get(t, transport, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, int32(1), server.hits.Load())
+
+	unavailable := newScriptedServer(t, status(http.StatusServiceUnavailable))
+	client := &http.Client{Transport: transport}
+	resp, err = client.Post(unavailable.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), unavailable.hits.Load(), "POST без Idempotency-Key не повторяется")
+}
+
+func TestTransport_CircuitBreaker(t *testing.T) {
+	server := newScriptedServer(t,
+		status(http.StatusInternalServerError),
+		status(http.StatusInternalServerError),
+		status(http.StatusOK),
+	)
+
+	var transitions []string
+	breaker := NewCircuitBreaker("test", BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, func(name string, from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	transport, _, _ := newTestTransport(breaker, RetryConfig{MaxRetries: 0})
+
+	// Две ошибки подряд размыкают breaker
+	for i := 0; i < 2; i++ {
+		resp, err := get(t, transport, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	}
+	assert.Equal(t, StateOpen, breaker.State())
+
+	// В открытом состоянии запрос не доходит до сервера
+	_, err := get(t, transport, server.URL)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), server.hits.Load())
+
+	// После OpenTimeout пропускается пробный запрос, успех замыкает breaker
+	now = now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	resp, err := get(t, transport, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, StateClosed, breaker.State())
+
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, transitions)
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	breaker := NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second}, nil)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	require.NoError(t, breaker.Allow())
+	breaker.Done(false)
+	assert.Equal(t, StateOpen, breaker.State())
+
+	now = now.Add(time.Second)
+	require.NoError(t, breaker.Allow())
+	// Второй одновременный пробный запрос не пропускается
+	assert.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+	breaker.Done(false)
+	assert.Equal(t, StateOpen, breaker.State())
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := retryAfter("7", now)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, delay)
+
+	delay, ok = retryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	_, ok = retryAfter("soon", now)
+	assert.False(t, ok)
+}